@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and clear the workspace's GitHub cache",
+		Long: `PRs, checks and workflow runs fetched from GitHub are cached per workspace
+under $XDG_CACHE_HOME/ws, so "ws status", "ws prompt", mission control and
+debrief don't ask GitHub for the same data on every run.`,
+	}
+	cmd.AddCommand(newCacheStatsCmd())
+	cmd.AddCommand(newCacheClearCmd())
+	return cmd
+}
+
+func newCacheStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show what the cache holds",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			cache := github.NewCache(github.CacheDir(ctx.WS.Root))
+			stats, err := cache.Stats()
+			if err != nil {
+				return fmt.Errorf("reading cache: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", "Cache", cache.Dir())
+			if stats.Entries == 0 {
+				fmt.Fprintf(os.Stderr, "  %-8s %s\n", "Entries", ui.Dim.Render("none"))
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "  %-8s %d %s\n", "Entries", stats.Entries, ui.Dim.Render("("+formatBytes(stats.Bytes)+")"))
+			for _, kind := range slices.Sorted(maps.Keys(stats.Kinds)) {
+				fmt.Fprintf(os.Stderr, "    %-12s %d\n", kind, stats.Kinds[kind])
+			}
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", "Oldest", timeAgo(stats.Oldest))
+			fmt.Fprintf(os.Stderr, "  %-8s %s\n", "Newest", timeAgo(stats.Newest))
+			return nil
+		},
+	}
+}
+
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove every cached entry",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			n, err := github.NewCache(github.CacheDir(ctx.WS.Root)).Clear()
+			if err != nil {
+				return fmt.Errorf("clearing cache: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "  %s Cleared %d cached entries\n", ui.Green.Render("✓"), n)
+			return nil
+		},
+	}
+}
+
+// formatBytes renders a size as "512 B", "4.2 KB" or "1.3 MB".
+func formatBytes(n int64) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
@@ -16,7 +16,7 @@ import (
 type createCapsuleFn func() (string, error)
 
 func runCapsuleCreate(ctx *Context, repo string, branch string, createWorktree createCapsuleFn, successMsg string) error {
-	capsuleName := workspace.CapsuleName(branch)
+	capsuleName := ctx.WS.CapsuleDir(repo, branch)
 	capsulePath := filepath.Join(ctx.WS.RepoDir(repo), capsuleName)
 	if _, err := os.Stat(capsulePath); err == nil {
 		return fmt.Errorf("capsule %q already exists for %s", capsuleName, repo)
@@ -118,6 +118,6 @@ func runCapsuleCreate(ctx *Context, repo string, branch string, createWorktree c
 	}
 
 	fmt.Fprintf(os.Stderr, "\n%s %s %s is ready for work.\n", successMsg, ctx.WS.FormatRepoName(repo), ui.TagDim.Render(capsule))
-	fmt.Printf("cd %s\n", shellQuote(wtPath))
+	printCd(wtPath)
 	return nil
 }
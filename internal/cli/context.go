@@ -20,6 +20,10 @@ type Context struct {
 
 var ctxOverride *Context
 
+// offlineMode is set by --offline (or WS_OFFLINE), and serves every GitHub
+// call from the cache.
+var offlineMode bool
+
 func SetContextOverride(ctx *Context) {
 	ctxOverride = ctx
 }
@@ -31,13 +35,25 @@ func ClearContextOverride() {
 // LoadContext discovers config from the current working directory and builds a workspace.
 func LoadContext() (*Context, error) {
 	if ctxOverride != nil {
-		return ctxOverride, nil
+		if !offlineMode {
+			return ctxOverride, nil
+		}
+		ctx := *ctxOverride
+		ctx.GitHub = github.Offline(ctx.GitHub)
+		return &ctx, nil
 	}
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, err
 	}
-	return LoadContextFromDir(cwd)
+	ctx, err := LoadContextFromDir(cwd)
+	if err != nil {
+		return nil, err
+	}
+	if offlineMode {
+		ctx.GitHub = github.Offline(ctx.GitHub)
+	}
+	return ctx, nil
 }
 
 // LoadContextFromDir discovers config from the given directory and builds a workspace.
@@ -93,9 +109,27 @@ func LoadContextFromDir(dir string) (*Context, error) {
 		AfterCreateHooks: afterCreateHooks,
 		Boarded:          cfg.Boarded,
 		Silo:             cfg.Silo,
+		BranchPolicy: workspace.BranchPolicy{
+			Prefix:          cfg.Branches.Prefix,
+			Charset:         cfg.Branches.Charset,
+			MaxLength:       cfg.Branches.MaxLength,
+			Pattern:         cfg.Branches.Pattern,
+			Fix:             cfg.Branches.Fix,
+			CapsuleTemplate: cfg.Branches.CapsuleTemplate,
+		},
+	}
+	if err := ws.BranchPolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("[branches] in %s: %w", config.FileName, err)
 	}
 
-	return &Context{Config: cfg, WS: ws, GitHub: github.LiveClient{}}, nil
+	// Without a connection to GitHub, everything is served from the cache.
+	gh := &github.CachedClient{
+		Client:        github.LiveClient{},
+		Cache:         github.NewCache(github.CacheDir(root)),
+		ServeStale:    true,
+		DetectOffline: true,
+	}
+	return &Context{Config: cfg, WS: ws, GitHub: gh}, nil
 }
 
 // ResolveRepo figures out which repo the user means.
@@ -167,3 +201,17 @@ func (c *Context) ResolveCapsule(repo, arg string) (string, error) {
 		return ui.PickWorktree(matches)
 	}
 }
+
+// ResolveCapsuleArg is ResolveCapsule, except that "." means the capsule of
+// repo that the current directory is in.
+func (c *Context) ResolveCapsuleArg(repo, arg string) (string, error) {
+	if arg != "." {
+		return c.ResolveCapsule(repo, arg)
+	}
+	cwd, _ := os.Getwd()
+	detectedRepo, wt, ok := workspace.DetectRepo(c.WS.Root, cwd)
+	if !ok || detectedRepo != repo {
+		return "", fmt.Errorf("not inside a capsule of %s", repo)
+	}
+	return wt, nil
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 
@@ -17,16 +19,21 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const ghFetchConcurrency = 8
-
 func newDebriefCmd() *cobra.Command {
 	var days int
-	var burnDirtyLanded bool
+	var burnDirtyLanded, kill bool
 
 	cmd := &cobra.Command{
 		Use:   "debrief [repo]",
 		Short: "Clean up landed capsules and report orbit status",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Remove capsules whose branches have landed or that have been inactive for
+too long, and report on the rest.
+
+Processes still running in a capsule are listed before it's removed, and you're
+asked whether to stop the ones holding its files open; --kill stops them
+without asking. A capsule whose
+files are still held open is skipped. Windows of removed capsules are closed.`,
+		Args: cobra.MaximumNArgs(1),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
 				return completeRepoNames(cmd, args, toComplete)
@@ -48,17 +55,18 @@ func newDebriefCmd() *cobra.Command {
 				repoFilter = resolved
 			}
 
-			return runDebrief(ctx, days, repoFilter, burnDirtyLanded)
+			return runDebrief(ctx, days, repoFilter, burnDirtyLanded, kill)
 		},
 	}
 
 	cmd.Flags().IntVar(&days, "days", 90, "Inactivity threshold in days")
 	cmd.Flags().BoolVar(&burnDirtyLanded, "burn-dirty-landed", false, "Force-remove landed capsules even if they have uncommitted changes")
+	cmd.Flags().BoolVar(&kill, "kill", false, "Stop processes running in removed capsules without asking")
 
 	return cmd
 }
 
-func runDebrief(ctx *Context, days int, repoFilter string, burnDirtyLanded bool) error {
+func runDebrief(ctx *Context, days int, repoFilter string, burnDirtyLanded, kill bool) error {
 	var capsules []workspace.CapsuleInfo
 	var prsByBranch map[string]*github.PR
 	var mergedBranches map[string]bool
@@ -80,10 +88,15 @@ func runDebrief(ctx *Context, days int, repoFilter string, burnDirtyLanded bool)
 		prsByBranch = dm.prsByBranch
 		mergedBranches = dm.mergedBranches
 	} else {
-		// Non-interactive: parallel fetch, then sequential steps.
+		// Non-interactive: parallel fetch, then sequential steps. Offline,
+		// ground is aligned with what was last fetched.
 		fetchResults := make([]workspace.FetchResult, len(repos))
+		offline := github.IsOffline(ctx.GitHub)
 		var wg sync.WaitGroup
 		for i, repo := range repos {
+			if offline {
+				continue
+			}
 			wg.Add(1)
 			go func(idx int, name string) {
 				defer wg.Done()
@@ -128,6 +141,7 @@ func runDebrief(ctx *Context, days int, repoFilter string, burnDirtyLanded bool)
 	}
 
 	var debriefed, skipped, inOrbit int
+	openFiles := sync.OnceValue(listOpenFiles) // one lsof scan for every capsule burned
 	boardChanged := false
 	siloChanged := false
 
@@ -146,6 +160,14 @@ func runDebrief(ctx *Context, days int, repoFilter string, burnDirtyLanded bool)
 				)
 				skipped++
 			} else {
+				if err := stopCapsuleProcesses(ctx.WS, openFiles(), c.Repo, c.Name, kill); err != nil {
+					fmt.Fprintf(os.Stderr, "  %s %s%s %s%s %s, but %v — skipped\n",
+						ui.Red.Render("✗"), repoName, repoPad, tag, tagPad, debriefReason(c), err,
+					)
+					skipped++
+					continue
+				}
+
 				if ctx.WS.IsBoarded(c.Repo, c.Name) {
 					ctx.WS.Unboard(c.Repo, c.Name)
 					boardChanged = true
@@ -179,6 +201,7 @@ func runDebrief(ctx *Context, days int, repoFilter string, burnDirtyLanded bool)
 				fmt.Fprintf(os.Stderr, "  %s %s%s %s%s %s, %s\n",
 					ui.Green.Render("✓"), repoName, repoPad, tag, tagPad, debriefReason(c), suffix,
 				)
+				closeCapsuleWindow(ctx.WS, c.Repo, c.Name)
 				debriefed++
 			}
 		} else {
@@ -256,7 +279,6 @@ func orbitExtra(c workspace.CapsuleInfo, prsByBranch map[string]*github.PR) stri
 	return ui.Dim.Render(" (") + strings.Join(parts, sep) + ui.Dim.Render(")")
 }
 
-
 // --- debrief bubbletea model ---
 
 type debriefAlignMsg struct {
@@ -320,7 +342,10 @@ func (m debriefModel) Init() tea.Cmd {
 		name := m.repos[i].name
 		ctx := m.ctx
 		cmds = append(cmds, func() tea.Msg {
-			err := workspace.GitFetch(ctx.WS.BareDir(name))
+			var err error
+			if !github.IsOffline(ctx.GitHub) {
+				err = workspace.GitFetch(ctx.WS.BareDir(name))
+			}
 			if err == nil {
 				workspace.GitFFMerge(ctx.WS.MainWorktree(name), "origin/"+ctx.WS.DefaultBranch)
 			}
@@ -457,52 +482,22 @@ func fetchPRsByBranch(ctx *Context, capsules []workspace.CapsuleInfo) (map[strin
 		allRepos[c.Repo] = true
 	}
 
-	type repoResult struct {
-		open   []github.PR
-		merged []github.PR
-	}
-	results := make(chan repoResult, len(allRepos))
-
-	// Cap concurrent `gh` invocations. Each repo issues 2 calls, so this
-	// bounds total in-flight requests to ghFetchConcurrency.
-	sem := make(chan struct{}, ghFetchConcurrency)
-
-	var wg sync.WaitGroup
-	for repo := range allRepos {
-		wg.Add(1)
-		go func(repo string) {
-			defer wg.Done()
-			var r repoResult
-			var innerWg sync.WaitGroup
-			innerWg.Add(2)
-			go func() {
-				defer innerWg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-				if prs, err := ctx.GitHub.PRsForRepo(ctx.WS.Org, repo); err == nil {
-					r.open = prs
-				}
-			}()
-			go func() {
-				defer innerWg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-				if prs, err := ctx.GitHub.MergedPRsForRepo(ctx.WS.Org, repo); err == nil {
-					r.merged = prs
+	repos := slices.Sorted(maps.Keys(allRepos))
+	for repo, r := range ctx.GitHub.PRsForRepos(ctx.WS.Org, repos) {
+		if r.Err != nil {
+			// Without PRs (offline, or the forge failed), look for squash
+			// merges locally instead.
+			for _, c := range capsules {
+				if c.Repo == repo && c.Branch != "" && workspace.GitSquashMerged(ctx.WS.BareDir(repo), ctx.WS.DefaultBranch, c.Branch) {
+					mergedBranches[c.Branch] = true
 				}
-			}()
-			innerWg.Wait()
-			results <- r
-		}(repo)
-	}
-	wg.Wait()
-	close(results)
-
-	for r := range results {
-		for i := range r.open {
-			result[r.open[i].HeadRefName] = &r.open[i]
+			}
+			continue
+		}
+		for i := range r.Open {
+			result[r.Open[i].HeadRefName] = &r.Open[i]
 		}
-		for _, pr := range r.merged {
+		for _, pr := range r.Merged {
 			mergedBranches[pr.HeadRefName] = true
 		}
 	}
@@ -20,10 +20,26 @@ func (s *debriefStubClient) MergedPRsForRepo(_, repo string) ([]github.PR, error
 	return s.mergedPRs[repo], nil
 }
 
+func (s *debriefStubClient) PRsForRepos(_ string, repos []string) map[string]github.RepoPRs {
+	result := make(map[string]github.RepoPRs, len(repos))
+	for _, repo := range repos {
+		result[repo] = github.RepoPRs{Open: s.openPRs[repo], Merged: s.mergedPRs[repo]}
+	}
+	return result
+}
+
 func (s *debriefStubClient) PRFromNumber(_, _ string, _ int) (*github.PR, error) {
 	return nil, nil
 }
 
+func (s *debriefStubClient) PRsFromNumbers(_, _ string, _ []int) (map[int]github.PR, error) {
+	return nil, nil
+}
+
+func (s *debriefStubClient) IssueFromNumber(_, _ string, _ int) (*github.Issue, error) {
+	return nil, nil
+}
+
 func (s *debriefStubClient) PRDetail(_, _ string, _ int) (github.PRDetailResult, error) {
 	return github.PRDetailResult{}, nil
 }
@@ -32,6 +48,14 @@ func (s *debriefStubClient) WorkflowRuns(_, _, _ string, _ int) ([]github.Workfl
 	return nil, nil
 }
 
+func (s *debriefStubClient) CreatePR(_, _ string, _ github.CreatePROptions) (*github.PR, error) {
+	return nil, nil
+}
+
+func (s *debriefStubClient) EditPRBody(_, _ string, _ int, _ string) error {
+	return nil
+}
+
 func TestFetchPRsByBranch_ReturnsMergedBranches(t *testing.T) {
 	gh := &debriefStubClient{
 		openPRs: map[string][]github.PR{},
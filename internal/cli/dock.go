@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
 	"strconv"
 
 	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -37,6 +39,9 @@ func isPRNumber(s string) (int, bool) {
 // resolveFromPR fetches a PR by number and returns the head branch name.
 func resolveFromPR(gh github.Client, org, repo string, number int) (string, error) {
 	pr, err := gh.PRFromNumber(org, repo, number)
+	if errors.Is(err, github.ErrOffline) {
+		return "", fmt.Errorf("PR #%d isn't in the cache; dock it by branch name while offline", number)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -107,7 +112,13 @@ Examples:
 			}
 
 			return runCapsuleCreate(ctx, repo, branch, func() (string, error) {
-				return ctx.WS.CreateDockWorktree(repo, branch)
+				capsule, err := ctx.WS.CreateDockWorktree(repo, branch)
+				if err != nil {
+					return capsule, err
+				}
+				return capsule, ctx.WS.UpdateCapsuleMeta(repo, capsule, func(m *workspace.CapsuleMeta) {
+					*m = workspace.CapsuleMeta{CreatedAt: nowFunc(), CreatedBy: "dock"}
+				})
 			}, "Docked!")
 		},
 	}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/brudil/workspace/internal/ide"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newIDECmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ide",
+		Short: "Manage editor workspace files",
+	}
+	cmd.AddCommand(newIDEInitCmd())
+	return cmd
+}
+
+func newIDEInitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [editor...]",
+		Short: "Set up editor workspace files",
+		Long: `Create an editor's workspace files at the workspace root and fill them in
+from the board. Files are copied from the config repo's ide/ directory when it
+has them, and otherwise start out blank. Without editors, the ones listed in
+[ide] generate are set up.
+
+Editors: ` + strings.Join(ide.GeneratorNames(), ", ") + `
+
+Examples:
+  ws ide init vscode
+  ws ide init zed nvim`,
+		ValidArgs: ide.GeneratorNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			names := args
+			if len(names) == 0 {
+				names = ctx.Config.IDE.Generate
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("name an editor to set up, or list them in [ide] generate (options: %s)",
+					strings.Join(ide.GeneratorNames(), ", "))
+			}
+			return initIDEs(ctx.WS, names, os.Stderr)
+		},
+	}
+}
+
+// initIDEs sets up the named editors' workspace files.
+func initIDEs(ws *workspace.Workspace, names []string, w io.Writer) error {
+	gens := make([]ide.Generator, 0, len(names))
+	for _, name := range names {
+		g, ok := ide.LookupGenerator(name)
+		if !ok {
+			return fmt.Errorf("unknown editor %q (options: %s)", name, strings.Join(ide.GeneratorNames(), ", "))
+		}
+		gens = append(gens, g)
+	}
+
+	b := ide.Board{Boarded: ws.Boarded, DisplayNames: ws.DisplayNames, Org: ws.Org}
+	var errs []error
+	for _, g := range gens {
+		verb := "Created"
+		if g.IsSetUp(ws.Root) {
+			verb = "Updated"
+		}
+		if err := g.Init(ws.Root, b); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s %s %s\n", ui.Green.Render("✓"), verb, g.Path)
+	}
+	return errors.Join(errs...)
+}
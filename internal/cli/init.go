@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/ui"
 	"github.com/brudil/workspace/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -79,10 +80,16 @@ func newInitCmd() *cobra.Command {
 			workspace.DisableWorkspaceGit(ctx.WS.Root)
 			runAfterCreateHooks(ctx.WS, clonedRepos, os.Stderr, os.Stderr)
 
+			if names := ctx.Config.IDE.Generate; len(names) > 0 {
+				if err := initIDEs(ctx.WS, names, os.Stderr); err != nil {
+					fmt.Fprintf(os.Stderr, "  %s editor files: %v\n", ui.Orange.Render("⚠"), err)
+				}
+			}
+
 			fmt.Fprintf(os.Stderr, "\nWorkspace ready. %d repos cloned.\n", len(clonedRepos))
 
 			// Print the workspace path to stdout for shell integration: cd $(ws init ...)
-			fmt.Printf("cd %s\n", shellQuote(absDir))
+			printCd(absDir)
 			return nil
 		},
 	}
@@ -2,8 +2,11 @@ package cli_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -38,6 +41,132 @@ func TestLift_CreatesWorktreeAndBoards(t *testing.T) {
 	}
 }
 
+func TestLift_FromIssue(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	stub := &testutil.StubClient{
+		IssueFromNumberFn: func(org, repo string, number int) (*github.Issue, error) {
+			return &github.Issue{Number: number, Title: "Fix login redirect", State: "OPEN"}, nil
+		},
+	}
+
+	result := testutil.RunCommand(t, w.Root, stub, "lift", "repo-a", "#482")
+	if result.Err != nil {
+		t.Fatalf("lift from issue failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+
+	wtDir := filepath.Join(w.Root, "repos", "repo-a", "482-fix-login-redirect")
+	if branch := workspace.GitCurrentBranch(wtDir); branch != "482-fix-login-redirect" {
+		t.Errorf("branch = %q, want %q", branch, "482-fix-login-redirect")
+	}
+	ref := workspace.CapsuleIssues(filepath.Join(w.Root, "repos", "repo-a", ".bare"))["482-fix-login-redirect"]
+	if ref != (workspace.IssueRef{Org: "test-org", Repo: "repo-a", Number: 482}) {
+		t.Errorf("recorded issue = %v, want test-org/repo-a#482", ref)
+	}
+}
+
+func TestLift_FromIssueURLWithTemplate(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	f, _ := os.OpenFile(filepath.Join(w.Root, "ws.toml"), os.O_APPEND|os.O_WRONLY, 0o644)
+	f.WriteString("\n[issues]\nbranch_template = \"{{.Repo}}/issue-{{.Number}}\"\n")
+	f.Close()
+
+	var asked string
+	stub := &testutil.StubClient{
+		IssueFromNumberFn: func(org, repo string, number int) (*github.Issue, error) {
+			asked = fmt.Sprintf("%s/%s#%d", org, repo, number)
+			return &github.Issue{Number: number, Title: "Tracker item", State: "OPEN"}, nil
+		},
+	}
+
+	result := testutil.RunCommand(t, w.Root, stub, "lift", "repo-a", "--issue", "https://github.com/acme/tracker/issues/17")
+	if result.Err != nil {
+		t.Fatalf("lift --issue failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+	if asked != "acme/tracker#17" {
+		t.Errorf("fetched issue %q, want acme/tracker#17", asked)
+	}
+	if branch := workspace.GitCurrentBranch(filepath.Join(w.Root, "repos", "repo-a", "issue-17")); branch != "repo-a/issue-17" {
+		t.Errorf("branch = %q, want %q", branch, "repo-a/issue-17")
+	}
+}
+
+func TestLift_IssueRejectsCapsuleName(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	stub := &testutil.StubClient{
+		IssueFromNumberFn: func(org, repo string, number int) (*github.Issue, error) {
+			return &github.Issue{Number: number, Title: "Fix login redirect", State: "OPEN"}, nil
+		},
+	}
+
+	result := testutil.RunCommand(t, w.Root, stub, "lift", "repo-a", "--issue", "482", "name")
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "name isn't a ref") {
+		t.Fatalf("expected a capsule name to be rejected, got %v", result.Err)
+	}
+	if _, err := os.Stat(filepath.Join(w.Root, "repos", "repo-a", "482-fix-login-redirect")); err == nil {
+		t.Error("capsule was created")
+	}
+
+	result = testutil.RunCommand(t, w.Root, stub, "lift", "repo-a", "#482", "origin/main")
+	if result.Err != nil {
+		t.Fatalf("lift from issue with a base failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+}
+
+func TestLift_BranchPolicy(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	f, _ := os.OpenFile(filepath.Join(w.Root, "ws.toml"), os.O_APPEND|os.O_WRONLY, 0o644)
+	f.WriteString("\n[branches]\nprefix = \"{{.User}}/\"\ncharset = \"a-z0-9/-\"\nfix = true\ncapsule_template = \"{{trunc 8 .Name}}\"\n")
+	f.Close()
+
+	cache := github.NewCache(t.TempDir())
+	cache.SetUser("octocat")
+	gh := &github.CachedClient{Client: &testutil.StubClient{}, Cache: cache}
+
+	result := testutil.RunCommand(t, w.Root, gh, "lift", "repo-a", "Login_Redirect")
+	if result.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+	wtDir := filepath.Join(w.Root, "repos", "repo-a", "login-re")
+	if branch := workspace.GitCurrentBranch(wtDir); branch != "octocat/login-redirect" {
+		t.Errorf("branch = %q, want %q", branch, "octocat/login-redirect")
+	}
+}
+
+func TestLift_BranchPolicyRejects(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	f, _ := os.OpenFile(filepath.Join(w.Root, "ws.toml"), os.O_APPEND|os.O_WRONLY, 0o644)
+	f.WriteString("\n[branches]\npattern = \"^(feat|fix)/\"\n")
+	f.Close()
+
+	result := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "chore/deps")
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "pattern") {
+		t.Fatalf("lift of a branch outside the pattern: err = %v, want a pattern error", result.Err)
+	}
+	if _, err := os.Stat(filepath.Join(w.Root, "repos", "repo-a", "deps")); err == nil {
+		t.Error("capsule was created anyway")
+	}
+}
+
 func TestLift_CustomBase(t *testing.T) {
 	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
 		Org:           "test-org",
@@ -107,6 +236,39 @@ func TestDock_PRNumber(t *testing.T) {
 	}
 }
 
+func TestDock_PRNumberOffline(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a", Branches: []string{"pr-branch"}}},
+	})
+
+	stub := &testutil.StubClient{
+		PRsForReposFn: func(org string, repos []string) map[string]github.RepoPRs {
+			return map[string]github.RepoPRs{"repo-a": {Open: []github.PR{{Number: 42, Title: "My PR", HeadRefName: "pr-branch"}}}}
+		},
+		PRFromNumberFn: func(org, repo string, number int) (*github.PR, error) {
+			t.Error("PRFromNumber went to the forge while offline")
+			return nil, nil
+		},
+	}
+	gh := &github.CachedClient{Client: stub, Cache: github.NewCache(t.TempDir())}
+	gh.PRsForRepos("test-org", []string{"repo-a"})
+
+	result := testutil.RunCommand(t, w.Root, gh, "--offline", "dock", "repo-a", "42")
+	if result.Err != nil {
+		t.Fatalf("dock by PR number offline failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+	if branch := workspace.GitCurrentBranch(filepath.Join(w.Root, "repos", "repo-a", "pr-branch")); branch != "pr-branch" {
+		t.Errorf("branch = %q, want %q", branch, "pr-branch")
+	}
+
+	result = testutil.RunCommand(t, w.Root, gh, "--offline", "dock", "repo-a", "7")
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "isn't in the cache") {
+		t.Errorf("dock of an uncached PR: err = %v, want a cache miss", result.Err)
+	}
+}
+
 func TestDock_PRURL(t *testing.T) {
 	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
 		Org:           "test-org",
@@ -133,6 +295,60 @@ func TestDock_PRURL(t *testing.T) {
 	}
 }
 
+func TestBurn_RefusesWhileFilesHeldOpen(t *testing.T) {
+	if _, err := exec.LookPath("lsof"); err != nil {
+		t.Skip("lsof not installed")
+	}
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "dev-server"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	wtDir := filepath.Join(w.Root, "repos", "repo-a", "dev-server")
+
+	// tail stands in for a dev server: it works in the capsule and holds a
+	// file in it open.
+	server := exec.Command("tail", "-f", "README.md")
+	server.Dir = wtDir
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Process.Kill()
+	// A shell sitting in the capsule holds nothing open, so is left running.
+	shell := exec.Command("sleep", "30")
+	shell.Dir = wtDir
+	if err := shell.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer shell.Process.Kill()
+
+	r := testutil.RunCommand(t, w.Root, nil, "burn", "repo-a", "dev-server")
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "use --kill") {
+		t.Fatalf("burn err = %v, want refusal\nstderr: %s", r.Err, r.Stderr)
+	}
+	if _, err := os.Stat(wtDir); err != nil {
+		t.Fatalf("worktree removed while files were held open: %v", err)
+	}
+
+	r = testutil.RunCommand(t, w.Root, nil, "burn", "repo-a", "dev-server", "--kill")
+	if r.Err != nil {
+		t.Fatalf("burn --kill failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if !strings.Contains(r.Stderr, "Stopped tail") {
+		t.Errorf("stderr doesn't report stopping tail:\n%s", r.Stderr)
+	}
+	if strings.Contains(r.Stderr, "Stopped sleep") {
+		t.Errorf("the shell was stopped too:\n%s", r.Stderr)
+	}
+	if _, err := os.Stat(wtDir); err == nil {
+		t.Error("worktree still exists after burn --kill")
+	}
+}
+
 func TestDebrief_RemovesMergedCapsule(t *testing.T) {
 	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
 		Org:           "test-org",
@@ -438,7 +654,17 @@ type statusJSONOutput struct {
 				Number int    `json:"number"`
 				Title  string `json:"title"`
 				State  string `json:"state"`
+				Linked []struct {
+					Repo   string `json:"repo"`
+					Number int    `json:"number"`
+				} `json:"linked,omitempty"`
 			} `json:"pr,omitempty"`
+			Meta *struct {
+				Note      string   `json:"note"`
+				Tags      []string `json:"tags"`
+				CreatedBy string   `json:"created_by"`
+				Base      string   `json:"base"`
+			} `json:"meta,omitempty"`
 		} `json:"worktrees"`
 	} `json:"repos"`
 }
@@ -557,6 +783,57 @@ func TestStatusJSON_MultipleRepos(t *testing.T) {
 	}
 }
 
+func TestNote_StatusShowsAndFiltersByTag(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "tagged", "--tag", "#oncall"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "untagged"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if r := testutil.RunCommand(t, w.Root, nil, "note", "repo-a", "tagged", "flaky webhooks", "--tag", "q3", "--tag", "stale"); r.Err != nil {
+		t.Fatalf("note failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if r := testutil.RunCommand(t, w.Root, nil, "note", "repo-a", "tagged", "--untag", "#stale"); r.Err != nil {
+		t.Fatalf("note --untag failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+
+	result := testutil.RunCommand(t, w.Root, nil, "status", "--format", "json", "--tag", "oncall")
+	if result.Err != nil {
+		t.Fatalf("status failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+	var out statusJSONOutput
+	if err := json.Unmarshal([]byte(result.Stdout), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\nstdout: %s", err, result.Stdout)
+	}
+	if len(out.Repos) != 1 || len(out.Repos[0].Worktrees) != 1 {
+		t.Fatalf("status --tag oncall = %+v, want just the tagged capsule", out.Repos)
+	}
+	wt := out.Repos[0].Worktrees[0]
+	if wt.Name != "tagged" || wt.Meta == nil {
+		t.Fatalf("worktree = %+v, want tagged with meta", wt)
+	}
+	if wt.Meta.Note != "flaky webhooks" || !slices.Equal(wt.Meta.Tags, []string{"oncall", "q3"}) {
+		t.Errorf("meta = %+v, want the note and both tags", wt.Meta)
+	}
+	if wt.Meta.CreatedBy != "lift" || wt.Meta.Base != "origin/main" {
+		t.Errorf("meta origin = %q from %q, want lift from origin/main", wt.Meta.CreatedBy, wt.Meta.Base)
+	}
+
+	if r := testutil.RunCommand(t, w.Root, nil, "burn", "repo-a", "tagged"); r.Err != nil {
+		t.Fatalf("burn failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	data, _ := os.ReadFile(filepath.Join(w.Root, "repos", "repo-a", ".capsules.toml"))
+	if strings.Contains(string(data), "flaky webhooks") {
+		t.Errorf("burned capsule's note is still recorded:\n%s", data)
+	}
+}
+
 func TestStatusJSON_RepoError(t *testing.T) {
 	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
 		Org:           "test-org",
@@ -752,6 +1029,122 @@ func TestBurn_DirtyWorktree_Denied(t *testing.T) {
 	}
 }
 
+func TestRename_MovesCapsuleAndState(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "feat/long-capsule-name"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if r := testutil.RunCommand(t, w.Root, nil, "silo", "repo-a", "long-capsule-name"); r.Err != nil {
+		t.Fatalf("silo failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+
+	result := testutil.RunCommand(t, w.Root, nil, "rename", "repo-a", "long-capsule-name", "short")
+	if result.Err != nil {
+		t.Fatalf("rename failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+
+	repoDir := filepath.Join(w.Root, "repos", "repo-a")
+	if _, err := os.Stat(filepath.Join(repoDir, "long-capsule-name")); err == nil {
+		t.Error("old capsule dir still exists")
+	}
+	if branch := workspace.GitCurrentBranch(filepath.Join(repoDir, "short")); branch != "feat/long-capsule-name" {
+		t.Errorf("branch = %q, want it unchanged", branch)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(w.Root, "ws.local.toml"))
+	local := string(data)
+	if strings.Contains(local, "long-capsule-name") || !strings.Contains(local, `"short"`) {
+		t.Errorf("ws.local.toml still refers to the old name:\n%s", local)
+	}
+}
+
+func TestRename_Branch(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "feat/parent"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "child", "--on", "parent"); r.Err != nil {
+		t.Fatalf("lift --on failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	repoDir := filepath.Join(w.Root, "repos", "repo-a")
+	testutil.GitCmd(t, filepath.Join(repoDir, "parent"), "push", "origin", "feat/parent")
+
+	result := testutil.RunCommand(t, w.Root, &testutil.StubClient{}, "rename", "repo-a", "parent", "base", "--branch")
+	if result.Err != nil {
+		t.Fatalf("rename failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+
+	if branch := workspace.GitCurrentBranch(filepath.Join(repoDir, "base")); branch != "feat/base" {
+		t.Errorf("branch = %q, want %q", branch, "feat/base")
+	}
+	bareDir := filepath.Join(repoDir, ".bare")
+	if parent, _ := workspace.StackParent(bareDir, "child"); parent != "feat/base" {
+		t.Errorf("child's parent = %q, want %q", parent, "feat/base")
+	}
+	src := w.Sources["repo-a"]
+	if !workspace.GitRefExists(src, "refs/heads/feat/base") || workspace.GitRefExists(src, "refs/heads/feat/parent") {
+		t.Error("branch wasn't renamed on origin")
+	}
+}
+
+func TestRename_BranchWithoutUpstream(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "local-only"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	bareDir := filepath.Join(w.Root, "repos", "repo-a", ".bare")
+	testutil.GitCmd(t, bareDir, "config", "--remove-section", "branch.local-only")
+
+	result := testutil.RunCommand(t, w.Root, &testutil.StubClient{}, "rename", "repo-a", "local-only", "renamed", "--branch")
+	if result.Err != nil {
+		t.Fatalf("rename failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+	if out, err := exec.Command("git", "-C", bareDir, "config", "branch.renamed.remote").Output(); err == nil {
+		t.Errorf("branch.renamed.remote = %q, want no upstream", strings.TrimSpace(string(out)))
+	}
+}
+
+func TestRename_FailureRollsBack(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "feat"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	repoDir := filepath.Join(w.Root, "repos", "repo-a")
+	// Unreadable metadata makes moving the capsule fail after its worktree
+	// has moved.
+	os.RemoveAll(filepath.Join(repoDir, ".capsules.toml"))
+	os.Mkdir(filepath.Join(repoDir, ".capsules.toml"), 0o755)
+
+	result := testutil.RunCommand(t, w.Root, &testutil.StubClient{}, "rename", "repo-a", "feat", "other", "--branch")
+	if result.Err == nil {
+		t.Fatal("expected rename to fail")
+	}
+	if branch := workspace.GitCurrentBranch(filepath.Join(repoDir, "feat")); branch != "feat" {
+		t.Errorf("branch = %q, want the capsule and branch put back", branch)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "other")); err == nil {
+		t.Error("renamed capsule was left behind")
+	}
+}
+
 func TestBurn_UnboardsOnRemove(t *testing.T) {
 	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
 		Org:           "test-org",
@@ -1053,3 +1446,435 @@ func TestDebrief_DaysZero_RemovesInactiveCapsule(t *testing.T) {
 		t.Error("expected worktree to be removed with --days 0, but it still exists")
 	}
 }
+
+// commitFile writes a file in dir and commits it.
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	testutil.GitCmd(t, dir, "add", ".")
+	testutil.GitCmd(t, dir, "commit", "-m", "edit "+name)
+}
+
+// liftStack lifts "parent" with one commit and "child" on top of it.
+func liftStack(t *testing.T, root string) (parentDir, childDir string) {
+	t.Helper()
+	if r := testutil.RunCommand(t, root, nil, "lift", "repo-a", "parent"); r.Err != nil {
+		t.Fatalf("lift parent failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	parentDir = filepath.Join(root, "repos", "repo-a", "parent")
+	commitFile(t, parentDir, "parent.txt", "parent work")
+
+	if r := testutil.RunCommand(t, root, nil, "lift", "repo-a", "child", "--on", "parent"); r.Err != nil {
+		t.Fatalf("lift --on failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	childDir = filepath.Join(root, "repos", "repo-a", "child")
+	commitFile(t, childDir, "child.txt", "child work")
+	return parentDir, childDir
+}
+
+func TestLift_OnRecordsParent(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	_, childDir := liftStack(t, w.Root)
+
+	if _, err := os.Stat(filepath.Join(childDir, "parent.txt")); err != nil {
+		t.Error("child should be branched from the parent capsule")
+	}
+	bareDir := filepath.Join(w.Root, "repos", "repo-a", ".bare")
+	if parent, base := workspace.StackParent(bareDir, "child"); parent != "parent" || base == "" {
+		t.Errorf("StackParent = %q, %q; want parent with a fork point", parent, base)
+	}
+}
+
+func TestRestack_AfterParentMoves(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	parentDir, childDir := liftStack(t, w.Root)
+	commitFile(t, parentDir, "parent-2.txt", "more parent work")
+
+	r := testutil.RunCommand(t, w.Root, nil, "restack", "repo-a")
+	if r.Err != nil {
+		t.Fatalf("restack failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if _, err := os.Stat(filepath.Join(childDir, "parent-2.txt")); err != nil {
+		t.Error("child should include the parent's new commit")
+	}
+	if _, err := os.Stat(filepath.Join(childDir, "child.txt")); err != nil {
+		t.Error("child should keep its own commit")
+	}
+}
+
+func TestRestack_ParentLanded(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	_, childDir := liftStack(t, w.Root)
+
+	// Squash-merge the parent upstream.
+	src := w.Sources["repo-a"]
+	os.WriteFile(filepath.Join(src, "parent.txt"), []byte("parent work"), 0644)
+	testutil.GitCmd(t, src, "add", ".")
+	testutil.GitCmd(t, src, "commit", "-m", "Parent work (#1)")
+	gh := &testutil.StubClient{
+		MergedPRsForRepoFn: func(org, repo string) ([]github.PR, error) {
+			return []github.PR{{Number: 1, HeadRefName: "parent", State: "MERGED"}}, nil
+		},
+	}
+
+	r := testutil.RunCommand(t, w.Root, gh, "restack", "repo-a", "child")
+	if r.Err != nil {
+		t.Fatalf("restack failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if !strings.Contains(r.Stderr, "parent landed") {
+		t.Errorf("stderr = %q, want the landed parent reported", r.Stderr)
+	}
+	bareDir := filepath.Join(w.Root, "repos", "repo-a", ".bare")
+	if !workspace.GitIsAncestor(bareDir, "origin/main", "child") {
+		t.Error("child should now sit on origin/main")
+	}
+	if n := workspace.GitCommitsSince(childDir, "origin/main"); n != 1 {
+		t.Errorf("child has %d commits over origin/main, want only its own", n)
+	}
+	if parent, _ := workspace.StackParent(bareDir, "child"); parent != "" {
+		t.Errorf("parent = %q, want it cleared once the parent landed", parent)
+	}
+}
+
+func TestRestack_FreshParentNotLanded(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "parent"); r.Err != nil {
+		t.Fatalf("lift parent failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "child", "--on", "parent"); r.Err != nil {
+		t.Fatalf("lift --on failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	commitFile(t, filepath.Join(w.Root, "repos", "repo-a", "child"), "child.txt", "child work")
+
+	// The default branch moves on, leaving the empty parent behind it.
+	src := w.Sources["repo-a"]
+	os.WriteFile(filepath.Join(src, "other.txt"), []byte("other work"), 0644)
+	testutil.GitCmd(t, src, "add", ".")
+	testutil.GitCmd(t, src, "commit", "-m", "Other work")
+
+	r := testutil.RunCommand(t, w.Root, nil, "restack", "repo-a")
+	if r.Err != nil {
+		t.Fatalf("restack failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if strings.Contains(r.Stderr, "landed") {
+		t.Errorf("stderr = %q, want the empty parent not treated as landed", r.Stderr)
+	}
+	bareDir := filepath.Join(w.Root, "repos", "repo-a", ".bare")
+	if parent, _ := workspace.StackParent(bareDir, "child"); parent != "parent" {
+		t.Errorf("parent = %q, want child still stacked on parent", parent)
+	}
+}
+
+func TestRestack_ConflictContinue(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	parentDir, childDir := liftStack(t, w.Root)
+	commitFile(t, childDir, "parent.txt", "child's take")
+	commitFile(t, parentDir, "parent.txt", "parent's take")
+
+	r := testutil.RunCommand(t, w.Root, nil, "restack", "repo-a")
+	if r.Err == nil {
+		t.Fatal("expected restack to stop on a conflict")
+	}
+	if !strings.Contains(r.Stderr, "--continue") {
+		t.Errorf("stderr = %q, want instructions to continue", r.Stderr)
+	}
+
+	os.WriteFile(filepath.Join(childDir, "parent.txt"), []byte("resolved"), 0644)
+	testutil.GitCmd(t, childDir, "add", ".")
+
+	r = testutil.RunCommand(t, w.Root, nil, "restack", "repo-a", "--continue")
+	if r.Err != nil {
+		t.Fatalf("restack --continue failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	bareDir := filepath.Join(w.Root, "repos", "repo-a", ".bare")
+	if !workspace.GitIsAncestor(bareDir, "parent", "child") {
+		t.Error("child should sit on parent after continuing")
+	}
+	if steps, _ := (&workspace.Workspace{Root: w.Root}).PendingRestack("repo-a"); len(steps) != 0 {
+		t.Errorf("pending steps = %v, want none", steps)
+	}
+}
+
+func TestPRCreate_PushesAndRecordsPR(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "my-feature"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	capsuleDir := filepath.Join(w.Root, "repos", "repo-a", "my-feature")
+	commitFile(t, capsuleDir, "feature.txt", "feature work")
+	os.MkdirAll(filepath.Join(capsuleDir, ".github"), 0755)
+	os.WriteFile(filepath.Join(capsuleDir, ".github", "pull_request_template.md"), []byte("## Checklist"), 0644)
+
+	var got github.CreatePROptions
+	stub := &testutil.StubClient{
+		CreatePRFn: func(org, repo string, opts github.CreatePROptions) (*github.PR, error) {
+			got = opts
+			return &github.PR{Number: 42, Title: opts.Title, HeadRefName: opts.Head, State: "OPEN"}, nil
+		},
+	}
+	r := testutil.RunCommand(t, w.Root, stub, "pr", "create", "repo-a", "my-feature", "--draft")
+	if r.Err != nil {
+		t.Fatalf("pr create failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+
+	want := github.CreatePROptions{Head: "my-feature", Base: "main", Title: "edit feature.txt", Body: "## Checklist", Draft: true}
+	if got != want {
+		t.Errorf("CreatePR options = %+v, want %+v", got, want)
+	}
+	testutil.GitCmd(t, w.Sources["repo-a"], "rev-parse", "--verify", "my-feature")
+
+	bareDir := filepath.Join(w.Root, "repos", "repo-a", ".bare")
+	if n := workspace.RecordedPRs(bareDir)["my-feature"]; n != 42 {
+		t.Errorf("recorded PR = %d, want 42", n)
+	}
+
+	// GitHub hasn't listed the PR yet; status still links it.
+	stub = &testutil.StubClient{
+		PRFromNumberFn: func(org, repo string, number int) (*github.PR, error) {
+			return &github.PR{Number: number, HeadRefName: "my-feature", State: "OPEN"}, nil
+		},
+	}
+	r = testutil.RunCommand(t, w.Root, stub, "status", "--format", "json")
+	if r.Err != nil {
+		t.Fatalf("status failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	var out statusJSONOutput
+	if err := json.Unmarshal([]byte(r.Stdout), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\nstdout: %s", err, r.Stdout)
+	}
+	for _, wt := range out.Repos[0].Worktrees {
+		if wt.Name == "my-feature" && (wt.PR == nil || wt.PR.Number != 42) {
+			t.Errorf("my-feature PR = %+v, want #42", wt.PR)
+		}
+	}
+}
+
+func TestPRCreate_StackedTargetsParent(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	liftStack(t, w.Root)
+
+	var bases []string
+	stub := &testutil.StubClient{
+		CreatePRFn: func(org, repo string, opts github.CreatePROptions) (*github.PR, error) {
+			bases = append(bases, opts.Base)
+			return &github.PR{Number: len(bases), HeadRefName: opts.Head, State: "OPEN"}, nil
+		},
+	}
+
+	r := testutil.RunCommand(t, w.Root, stub, "pr", "create", "repo-a", "child")
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "isn't pushed yet") {
+		t.Fatalf("expected an unpushed parent error, got %v", r.Err)
+	}
+	for _, capsule := range []string{"parent", "child"} {
+		if r := testutil.RunCommand(t, w.Root, stub, "pr", "create", "repo-a", capsule); r.Err != nil {
+			t.Fatalf("pr create %s failed: %v\nstderr: %s", capsule, r.Err, r.Stderr)
+		}
+	}
+	if want := []string{"main", "parent"}; !slices.Equal(bases, want) {
+		t.Errorf("bases = %v, want %v", bases, want)
+	}
+}
+
+func TestPRCreate_LandedParent(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	liftStack(t, w.Root)
+
+	stub := &testutil.StubClient{
+		MergedPRsForRepoFn: func(org, repo string) ([]github.PR, error) {
+			return []github.PR{{Number: 1, HeadRefName: "parent", State: "MERGED"}}, nil
+		},
+	}
+	r := testutil.RunCommand(t, w.Root, stub, "pr", "create", "repo-a", "child")
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "which has landed") {
+		t.Fatalf("expected a landed parent error, got %v", r.Err)
+	}
+}
+
+func TestPRCreate_ExistingPROnlyPushes(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	if r := testutil.RunCommand(t, w.Root, nil, "lift", "repo-a", "my-feature"); r.Err != nil {
+		t.Fatalf("lift failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	commitFile(t, filepath.Join(w.Root, "repos", "repo-a", "my-feature"), "feature.txt", "more work")
+
+	stub := &testutil.StubClient{
+		PRsForRepoFn: func(org, repo string) ([]github.PR, error) {
+			return []github.PR{{Number: 7, Title: "Feature", HeadRefName: "my-feature", State: "OPEN"}}, nil
+		},
+		CreatePRFn: func(org, repo string, opts github.CreatePROptions) (*github.PR, error) {
+			t.Error("CreatePR should not be called when a PR is open")
+			return nil, nil
+		},
+	}
+	r := testutil.RunCommand(t, w.Root, stub, "pr", "create", "repo-a", "my-feature")
+	if r.Err != nil {
+		t.Fatalf("pr create failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if !strings.Contains(r.Stderr, "Updated") {
+		t.Errorf("stderr = %q, want an update message", r.Stderr)
+	}
+	testutil.GitCmd(t, w.Sources["repo-a"], "rev-parse", "--verify", "my-feature")
+}
+
+func TestPRSync_AddsRelatedSections(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}, {Name: "repo-b"}},
+	})
+	for _, args := range [][]string{
+		{"lift", "repo-a", "auth"},
+		{"lift", "repo-b", "token-endpoint"},
+		{"pr", "link", "login", "repo-a", "auth"},
+		{"pr", "link", "login", "repo-b", "token-endpoint"},
+	} {
+		if r := testutil.RunCommand(t, w.Root, nil, args...); r.Err != nil {
+			t.Fatalf("%v failed: %v\nstderr: %s", args, r.Err, r.Stderr)
+		}
+	}
+
+	edited := make(map[string]string)
+	stub := &testutil.StubClient{
+		PRsForRepoFn: func(org, repo string) ([]github.PR, error) {
+			if repo == "repo-a" {
+				return []github.PR{{Number: 1, HeadRefName: "auth", State: "OPEN"}}, nil
+			}
+			return []github.PR{{Number: 2, HeadRefName: "token-endpoint", State: "OPEN"}}, nil
+		},
+		PRDetailFn: func(org, repo string, number int) (github.PRDetailResult, error) {
+			return github.PRDetailResult{Body: "Part of the login change."}, nil
+		},
+		EditPRBodyFn: func(org, repo string, number int, body string) error {
+			edited[repo] = body
+			return nil
+		},
+	}
+
+	r := testutil.RunCommand(t, w.Root, stub, "status", "--format", "json")
+	if r.Err != nil {
+		t.Fatalf("status failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	var out statusJSONOutput
+	if err := json.Unmarshal([]byte(r.Stdout), &out); err != nil {
+		t.Fatalf("invalid JSON: %v\nstdout: %s", err, r.Stdout)
+	}
+	for _, wt := range out.Repos[0].Worktrees {
+		if wt.Name == "auth" {
+			if wt.PR == nil || len(wt.PR.Linked) != 1 || wt.PR.Linked[0].Repo != "repo-b" {
+				t.Errorf("auth PR = %+v, want it linked with repo-b", wt.PR)
+			}
+		}
+	}
+
+	r = testutil.RunCommand(t, w.Root, stub, "pr", "sync", "repo-a", "auth")
+	if r.Err != nil {
+		t.Fatalf("pr sync failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if !strings.Contains(edited["repo-a"], "- test-org/repo-b#2") {
+		t.Errorf("repo-a body = %q, want it to list repo-b#2", edited["repo-a"])
+	}
+	if !strings.Contains(edited["repo-b"], "- test-org/repo-a#1") || !strings.HasPrefix(edited["repo-b"], "Part of the login change.") {
+		t.Errorf("repo-b body = %q, want its text kept and repo-a#1 listed", edited["repo-b"])
+	}
+}
+
+func TestCache_StatsAndClear(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+	cache := github.NewCache(github.CacheDir(w.Root))
+	cache.SetUser("octocat")
+	cache.SetBranches("test-org", "repo-a", map[string]string{"feat": "feat"})
+
+	r := testutil.RunCommand(t, w.Root, nil, "cache", "stats")
+	if r.Err != nil {
+		t.Fatalf("cache stats failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if !strings.Contains(r.Stderr, cache.Dir()) || !strings.Contains(r.Stderr, "branches") {
+		t.Errorf("stats output missing the cache dir or kinds:\n%s", r.Stderr)
+	}
+
+	r = testutil.RunCommand(t, w.Root, nil, "cache", "clear")
+	if r.Err != nil {
+		t.Fatalf("cache clear failed: %v\nstderr: %s", r.Err, r.Stderr)
+	}
+	if !strings.Contains(r.Stderr, "Cleared 2 cached entries") {
+		t.Errorf("clear output = %q, want 2 entries cleared", r.Stderr)
+	}
+	if cache.User() != "" {
+		t.Error("cache still holds the user after clear")
+	}
+}
+
+func TestIDEInit_UsesConfiguredEditors(t *testing.T) {
+	w := testutil.SetupWorkspace(t, testutil.WorkspaceOpts{
+		Org:           "test-org",
+		DefaultBranch: "main",
+		Repos:         []testutil.RepoOpts{{Name: "repo-a"}},
+	})
+
+	if r := testutil.RunCommand(t, w.Root, nil, "ide", "init"); r.Err == nil {
+		t.Fatal("expected an error with no editors named or configured")
+	}
+
+	f, _ := os.OpenFile(filepath.Join(w.Root, "ws.toml"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("\n[ide]\ngenerate = [\"vscode\", \"helix\"]\n")
+	f.Close()
+
+	result := testutil.RunCommand(t, w.Root, nil, "ide", "init")
+	if result.Err != nil {
+		t.Fatalf("ide init failed: %v\nstderr: %s", result.Err, result.Stderr)
+	}
+	if _, err := os.Stat(filepath.Join(w.Root, "workspace.code-workspace")); err != nil {
+		t.Error("workspace.code-workspace wasn't created")
+	}
+	if _, err := os.Stat(filepath.Join(w.Root, ".helix", "ignore")); err != nil {
+		t.Error(".helix/ignore wasn't created")
+	}
+	if _, err := os.Stat(filepath.Join(w.Root, ".idea")); err == nil {
+		t.Error(".idea was created without being asked for")
+	}
+
+	if r := testutil.RunCommand(t, w.Root, nil, "ide", "init", "emacs"); r.Err == nil || !strings.Contains(r.Err.Error(), "unknown editor") {
+		t.Errorf("err = %v, want unknown editor", r.Err)
+	}
+}
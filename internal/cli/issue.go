@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+var issueURLRe = regexp.MustCompile(`^https?://github\.com/([^/]+)/([^/]+)/issues/(\d+)`)
+
+// maxIssueTitle is how much of an issue's title status lines show.
+const maxIssueTitle = 40
+
+// parseIssueArg reads an issue given as "482", "#482" or an issue URL. Bare
+// numbers refer to an issue in repo.
+func parseIssueArg(org, repo, arg string) (workspace.IssueRef, error) {
+	if m := issueURLRe.FindStringSubmatch(arg); m != nil {
+		n, _ := strconv.Atoi(m[3])
+		if n > 0 {
+			return workspace.IssueRef{Org: m[1], Repo: m[2], Number: n}, nil
+		}
+	}
+	if n, ok := isPRNumber(strings.TrimPrefix(arg, "#")); ok {
+		return workspace.IssueRef{Org: org, Repo: repo, Number: n}, nil
+	}
+	return workspace.IssueRef{}, fmt.Errorf("%q is not an issue number or URL", arg)
+}
+
+// issueBranch fetches the issue and names a branch for it from the
+// workspace's branch template. It returns what the name was built from, for
+// the branch policy.
+func issueBranch(ctx *Context, repo string, ref workspace.IssueRef) (string, workspace.BranchData, error) {
+	data := workspace.BranchData{Repo: repo}
+	issue, err := ctx.GitHub.IssueFromNumber(ref.Org, ref.Repo, ref.Number)
+	if errors.Is(err, github.ErrOffline) {
+		return "", data, fmt.Errorf("issue %s isn't in the cache; lift it by name while offline", ref)
+	}
+	if err != nil {
+		return "", data, err
+	}
+	if issue == nil {
+		return "", data, fmt.Errorf("issue %s not found", ref)
+	}
+	data.Number, data.Title = issue.Number, issue.Title
+
+	tmpl := ctx.Config.Issues.BranchTemplate
+	if tmpl == "" {
+		tmpl = workspace.DefaultBranchTemplate
+	}
+	if strings.Contains(tmpl, ".User") {
+		if data.User, err = currentLogin(ctx); err != nil {
+			return "", data, err
+		}
+	}
+	branch, err := workspace.BranchFromTemplate(tmpl, data)
+	if err != nil {
+		return "", data, fmt.Errorf("[issues] branch_template: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "  Issue #%d: %s\n", issue.Number, issue.Title)
+	return branch, data, nil
+}
+
+// currentLogin returns the user's GitHub login for naming templates.
+func currentLogin(ctx *Context) (string, error) {
+	login := github.Login(ctx.GitHub)
+	if login == "" {
+		return "", fmt.Errorf("naming the branch needs your GitHub login, which couldn't be found")
+	}
+	return login, nil
+}
+
+// capsuleIssues fetches the issues that repo's capsules were lifted from,
+// keyed by branch. Issues that can't be fetched are left out.
+func capsuleIssues(ws *workspace.Workspace, gh github.Client, repo string) map[string]*github.Issue {
+	refs := workspace.CapsuleIssues(ws.BareDir(repo))
+	if len(refs) == 0 {
+		return nil
+	}
+	issues := make(map[string]*github.Issue, len(refs))
+	for branch, ref := range refs {
+		if issue, err := gh.IssueFromNumber(ref.Org, ref.Repo, ref.Number); err == nil && issue != nil {
+			issues[branch] = issue
+		}
+	}
+	return issues
+}
+
+// formatIssue renders an issue as "#482 Fix login redirect open".
+func formatIssue(issue *github.Issue) string {
+	if issue == nil {
+		return ""
+	}
+	num := fmt.Sprintf("#%d", issue.Number)
+	if issue.URL != "" {
+		num = ui.Hyperlink(issue.URL, num)
+	}
+	title := issue.Title
+	if r := []rune(title); len(r) > maxIssueTitle {
+		title = strings.TrimSpace(string(r[:maxIssueTitle-1])) + "…"
+	}
+	state := ui.Green.Render("open")
+	if issue.State != "OPEN" {
+		state = ui.Dim.Render(strings.ToLower(issue.State))
+	}
+	return ui.Dim.Render(num+" "+title) + " " + state
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+func TestParseIssueArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want workspace.IssueRef
+	}{
+		{"482", workspace.IssueRef{Org: "acme", Repo: "api", Number: 482}},
+		{"#482", workspace.IssueRef{Org: "acme", Repo: "api", Number: 482}},
+		{"https://github.com/other/tracker/issues/17", workspace.IssueRef{Org: "other", Repo: "tracker", Number: 17}},
+	}
+	for _, tt := range tests {
+		got, err := parseIssueArg("acme", "api", tt.arg)
+		if err != nil || got != tt.want {
+			t.Errorf("parseIssueArg(%q) = %v, %v; want %v", tt.arg, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"#", "abc", "https://github.com/o/r/pull/3"} {
+		if _, err := parseIssueArg("acme", "api", bad); err == nil {
+			t.Errorf("parseIssueArg(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormatIssue(t *testing.T) {
+	if formatIssue(nil) != "" {
+		t.Error("nil issue should format as empty")
+	}
+	got := formatIssue(&github.Issue{Number: 482, Title: "Fix login redirect", State: "OPEN"})
+	for _, want := range []string{"#482", "Fix login redirect", "open"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatIssue() = %q, missing %q", got, want)
+		}
+	}
+	long := formatIssue(&github.Issue{Number: 1, Title: strings.Repeat("word ", 20), State: "CLOSED"})
+	if !strings.Contains(long, "…") || !strings.Contains(long, "closed") {
+		t.Errorf("formatIssue(long, closed) = %q, want a cut title and closed", long)
+	}
+}
@@ -63,7 +63,7 @@ Usage:
 				return nil
 			}
 
-			fmt.Printf("cd %s\n", shellQuote(path))
+			printCd(path)
 			return nil
 		},
 	}
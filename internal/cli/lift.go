@@ -1,21 +1,45 @@
 package cli
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brudil/workspace/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 func newLiftCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "lift <repo> <capsule-name> [base]",
+	var on, issueArg, note string
+	var tags []string
+
+	cmd := &cobra.Command{
+		Use:   "lift <repo> <capsule-name | #issue> [base]",
 		Short: "Create a new capsule",
 		Long: `Create a new branch and worktree for fresh work. Use "." as the repo to
 infer from the current directory. Base defaults to origin/<default-branch>.
 
+With --on, the new capsule is stacked on an existing capsule: it branches from
+that capsule's branch and remembers it as its parent, so "ws restack" can
+rebase it when the parent moves or lands.
+
+Given an issue ("#482", or --issue with a number or URL) instead of a name,
+the branch is named from the issue's title with [issues] branch_template in
+ws.toml, and the capsule remembers the issue for "ws status" and mission
+control. Quote "#482" so the shell doesn't read it as a comment. A name
+can't be given with an issue; an argument after it is the base, and must be
+an existing ref.
+
 Examples:
   ws lift frontend my-feature
   ws lift . my-feature
-  ws lift frontend my-feature develop`,
-		Args: cobra.RangeArgs(2, 3),
+  ws lift frontend my-feature develop
+  ws lift frontend my-feature-part-2 --on my-feature
+  ws lift api '#482'
+  ws lift api --issue https://github.com/acme/tracker/issues/17
+  ws lift api retry-webhooks --note "flaky deliveries to Slack" --tag oncall`,
+		Args: cobra.RangeArgs(1, 3),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
 				return completeRepoNames(cmd, args, toComplete)
@@ -33,16 +57,113 @@ Examples:
 				return err
 			}
 
-			branch := args[1]
+			rest := args[1:]
+			if issueArg == "" && len(rest) > 0 && (strings.HasPrefix(rest[0], "#") || issueURLRe.MatchString(rest[0])) {
+				issueArg, rest = rest[0], rest[1:]
+			}
+
+			var branch string
+			var issue *workspace.IssueRef
+			data := workspace.BranchData{Repo: repo}
+			if issueArg != "" {
+				// The issue names the branch, so anything after it is the
+				// base. Catch a capsule name given out of habit before it's
+				// taken as one.
+				if len(rest) == 1 && !workspace.GitRefExists(ctx.WS.BareDir(repo), rest[0]) {
+					return fmt.Errorf("%s isn't a ref; with an issue the branch is named from its title, so only a base can follow", rest[0])
+				}
+				ref, err := parseIssueArg(ctx.WS.Org, repo, issueArg)
+				if err != nil {
+					return err
+				}
+				if branch, data, err = issueBranch(ctx, repo, ref); err != nil {
+					return err
+				}
+				issue = &ref
+			} else {
+				if len(rest) == 0 {
+					return fmt.Errorf("missing capsule name")
+				}
+				branch, rest = rest[0], rest[1:]
+			}
+			if len(rest) > 1 {
+				return fmt.Errorf("too many arguments")
+			}
+			if branch, err = applyBranchPolicy(ctx, branch, data); err != nil {
+				return err
+			}
+
+			// record notes where the capsule came from once it exists.
+			record := func(base string, create createCapsuleFn) createCapsuleFn {
+				return func() (string, error) {
+					capsule, err := create()
+					if err != nil {
+						return capsule, err
+					}
+					if issue != nil {
+						if err := workspace.SetCapsuleIssue(ctx.WS.BareDir(repo), branch, *issue); err != nil {
+							return capsule, err
+						}
+					}
+					return capsule, ctx.WS.UpdateCapsuleMeta(repo, capsule, func(m *workspace.CapsuleMeta) {
+						*m = workspace.CapsuleMeta{Note: note, Tags: workspace.NormalizeTags(tags), CreatedAt: nowFunc(), CreatedBy: "lift", Base: base}
+					})
+				}
+			}
+
+			if on != "" {
+				if len(rest) == 1 {
+					return fmt.Errorf("--on can't be used with a base ref")
+				}
+				parent, err := ctx.ResolveCapsule(repo, on)
+				if err != nil {
+					return err
+				}
+				parentBranch := workspace.GitCurrentBranch(filepath.Join(ctx.WS.RepoDir(repo), parent))
+				if parentBranch == "" || parentBranch == "HEAD" {
+					return fmt.Errorf("capsule %s has no branch checked out", parent)
+				}
+				return runCapsuleCreate(ctx, repo, branch, record(parentBranch, func() (string, error) {
+					return ctx.WS.CreateStackedWorktree(repo, branch, parentBranch)
+				}), "Lift off!")
+			}
 
 			base := "origin/" + ctx.WS.DefaultBranch
-			if len(args) == 3 {
-				base = args[2]
+			if len(rest) == 1 {
+				base = rest[0]
 			}
 
-			return runCapsuleCreate(ctx, repo, branch, func() (string, error) {
+			return runCapsuleCreate(ctx, repo, branch, record(base, func() (string, error) {
 				return ctx.WS.CreateLiftWorktree(repo, branch, base)
-			}, "Lift off!")
+			}), "Lift off!")
 		},
 	}
+
+	cmd.Flags().StringVar(&on, "on", "", "Stack the new capsule on an existing capsule")
+	cmd.Flags().StringVar(&issueArg, "issue", "", "Lift from an issue, by number or URL")
+	cmd.Flags().StringVar(&note, "note", "", "Note why the capsule exists (see ws note)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "Tag the capsule (repeatable)")
+	cmd.RegisterFlagCompletionFunc("on", completeWorktreeNames(0))
+	return cmd
+}
+
+// applyBranchPolicy runs a new branch name through the workspace's branch
+// policy, saying so when the name changes.
+func applyBranchPolicy(ctx *Context, branch string, data workspace.BranchData) (string, error) {
+	policy := ctx.WS.BranchPolicy
+	if data.User == "" && strings.Contains(policy.Prefix, ".User") {
+		login, err := currentLogin(ctx)
+		if err != nil {
+			return "", err
+		}
+		data.User = login
+	}
+	named, err := policy.Apply(branch, data)
+	if err != nil {
+		return "", err
+	}
+	if named != branch {
+		fmt.Fprintf(os.Stderr, "  Branch: %s\n", named)
+	}
+	return named, nil
 }
@@ -2,8 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
+	"github.com/brudil/workspace/internal/github"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -11,7 +15,9 @@ import (
 // --- cobra command ---
 
 func newMCCmd() *cobra.Command {
-	return &cobra.Command{
+	var view string
+
+	cmd := &cobra.Command{
 		Use:               "mc",
 		Short:             "Mission control — interactive workspace dashboard",
 		ValidArgsFunction: cobra.NoFileCompletions,
@@ -21,8 +27,20 @@ func newMCCmd() *cobra.Command {
 				return err
 			}
 
+			if _, err := newMCKeymap(ctx.Config.MC); err != nil {
+				return err
+			}
+
+			// Mission control draws from the cache first and then refreshes
+			// itself, so it needs expired entries fetched, not served stale.
 			cwd, _ := os.Getwd()
-			m := newMCModel(ctx.WS, ctx.GitHub, cwd)
+			m := newMCModel(ctx.WS, github.WithoutStale(ctx.GitHub), cwd, ctx.Config.MC)
+			if view != "" {
+				if _, ok := ctx.Config.MC.Views[view]; !ok {
+					return unknownViewError(view, m.viewNames())
+				}
+				m = m.applyView(view)
+			}
 			p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(os.Stderr))
 			finalModel, err := p.Run()
 			if err != nil {
@@ -30,9 +48,28 @@ func newMCCmd() *cobra.Command {
 			}
 
 			if fm, ok := finalModel.(mcModel); ok && fm.jumpPath != "" {
-				fmt.Printf("cd '%s'\n", fm.jumpPath)
+				printCd(fm.jumpPath)
 			}
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&view, "view", "", "Start with a saved view applied")
+	cmd.RegisterFlagCompletionFunc("view", completeMCViews)
+	return cmd
+}
+
+func unknownViewError(name string, available []string) error {
+	if len(available) == 0 {
+		return fmt.Errorf("unknown view %q (no views defined in [mc.views])", name)
+	}
+	return fmt.Errorf("unknown view %q (available: %s)", name, strings.Join(available, ", "))
+}
+
+func completeMCViews(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx, err := LoadContext()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return slices.Sorted(maps.Keys(ctx.Config.MC.Views)), cobra.ShellCompDirectiveNoFileComp
 }
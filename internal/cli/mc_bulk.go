@@ -0,0 +1,433 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/ide"
+	"github.com/brudil/workspace/internal/mux"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- marking ---
+//
+// Rows are marked by identity rather than index, since ghost rows are
+// inserted and removed as PR data arrives.
+
+func rowKey(row mcRow) string {
+	if row.kind == rowGhostPR {
+		return row.repo + "/" + row.branch
+	}
+	return row.repo + "/" + row.wt
+}
+
+func (m mcModel) isMarkable(i int) bool {
+	row := m.rows[i]
+	switch row.kind {
+	case rowWorktree:
+		return row.wt != workspace.GroundDir
+	case rowGhostPR:
+		return true
+	}
+	return false
+}
+
+// isMarked reports whether row i is marked, either explicitly or by an
+// in-progress V range.
+func (m mcModel) isMarked(i int) bool {
+	if m.marked[rowKey(m.rows[i])] {
+		return true
+	}
+	if !m.rangeActive || !m.isMarkable(i) || !m.isRowVisible(i) {
+		return false
+	}
+	lo, hi := min(m.rangeAnchor, m.cursor), max(m.rangeAnchor, m.cursor)
+	return i >= lo && i <= hi
+}
+
+func (m mcModel) hasMarks() bool {
+	return len(m.marked) > 0 || m.rangeActive
+}
+
+// markedRows returns the indices of all marked rows, in display order.
+func (m mcModel) markedRows() []int {
+	var idxs []int
+	for i := range m.rows {
+		if m.isMarked(i) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+func (m mcModel) toggleMark() mcModel {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || !m.isMarkable(m.cursor) {
+		return m
+	}
+	key := rowKey(m.rows[m.cursor])
+	marked := make(map[string]bool, len(m.marked)+1)
+	for k := range m.marked {
+		marked[k] = true
+	}
+	if marked[key] {
+		delete(marked, key)
+	} else {
+		marked[key] = true
+	}
+	m.marked = marked
+	m.moveCursor(1)
+	m.ensureCursorVisible()
+	return m
+}
+
+// toggleRange starts a V range at the cursor, or commits the current range
+// into the marked set.
+func (m mcModel) toggleRange() mcModel {
+	if !m.rangeActive {
+		m.rangeActive = true
+		m.rangeAnchor = m.cursor
+		return m
+	}
+	marked := make(map[string]bool, len(m.marked))
+	for _, i := range m.markedRows() {
+		marked[rowKey(m.rows[i])] = true
+	}
+	m.marked = marked
+	m.rangeActive = false
+	return m
+}
+
+func (m mcModel) clearMarks() mcModel {
+	m.marked = nil
+	m.rangeActive = false
+	return m
+}
+
+// --- bulk operations ---
+
+type mcBulkItem struct {
+	label string
+	run   func() error
+	state repoState
+	err   error
+}
+
+// mcBulk is a bulk action over the marked rows. It runs one item at a time
+// and takes over the screen until dismissed.
+type mcBulk struct {
+	title   string
+	items   []mcBulkItem
+	confirm bool // waiting for y/n before starting
+	done    int
+}
+
+func (b *mcBulk) finished() bool {
+	return b.done >= len(b.items)
+}
+
+func (b *mcBulk) failed() int {
+	n := 0
+	for _, it := range b.items {
+		if it.state == repoFailed {
+			n++
+		}
+	}
+	return n
+}
+
+type mcBulkStepMsg struct {
+	idx int
+	err error
+}
+
+func runBulkItem(idx int, run func() error) tea.Cmd {
+	return func() tea.Msg {
+		return mcBulkStepMsg{idx: idx, err: run()}
+	}
+}
+
+// startBulk shows the progress view for b, running it immediately unless it
+// needs confirming first.
+func (m mcModel) startBulk(b *mcBulk) (mcModel, tea.Cmd) {
+	if len(b.items) == 0 {
+		return m, nil
+	}
+	m.bulk = b
+	if b.confirm {
+		return m, nil
+	}
+	return m.nextBulkStep()
+}
+
+func (m mcModel) nextBulkStep() (mcModel, tea.Cmd) {
+	for i := range m.bulk.items {
+		if m.bulk.items[i].state == repoPending {
+			m.bulk.items[i].state = repoRunning
+			return m, runBulkItem(i, m.bulk.items[i].run)
+		}
+	}
+	return m, nil
+}
+
+func (m mcModel) handleBulkStep(msg mcBulkStepMsg) (mcModel, tea.Cmd) {
+	if m.bulk == nil || msg.idx < 0 || msg.idx >= len(m.bulk.items) {
+		return m, nil
+	}
+	item := &m.bulk.items[msg.idx]
+	if msg.err != nil {
+		item.state = repoFailed
+		item.err = msg.err
+	} else {
+		item.state = repoDone
+	}
+	m.bulk.done++
+	return m.nextBulkStep()
+}
+
+func (m mcModel) handleBulkKey(msg tea.KeyMsg) (mcModel, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if m.bulk.confirm {
+		switch msg.String() {
+		case "y":
+			m.bulk.confirm = false
+			return m.nextBulkStep()
+		case "n", "esc":
+			m.bulk = nil
+		}
+		return m, nil
+	}
+	if !m.bulk.finished() {
+		return m, nil
+	}
+	switch msg.String() {
+	case "enter", "esc", "q":
+		m.bulk = nil
+		m = m.clearMarks()
+		return m.rebuildModel()
+	}
+	return m, nil
+}
+
+// bulkItems builds one item per marked row that fn accepts. fn returns nil
+// to skip a row.
+func (m mcModel) bulkItems(fn func(row mcRow) func() error) []mcBulkItem {
+	var items []mcBulkItem
+	for _, i := range m.markedRows() {
+		row := m.rows[i]
+		run := fn(row)
+		if run == nil {
+			continue
+		}
+		items = append(items, mcBulkItem{label: m.bulkLabel(row), run: run})
+	}
+	return items
+}
+
+func (m mcModel) bulkLabel(row mcRow) string {
+	name := row.wt
+	if row.kind == rowGhostPR {
+		name = row.branch
+	}
+	return m.ws.DisplayNameFor(row.repo) + " " + ui.TagDim.Render(name)
+}
+
+// mcCheckHolders refuses to burn a capsule while processes hold its files
+// open. mc can't ask whether to stop them, so that's left to ws burn --kill.
+// files is nil when lsof couldn't be run, and then nothing is refused.
+func mcCheckHolders(ws *workspace.Workspace, files *workspace.OpenFiles, repo, capsule string) error {
+	if files == nil {
+		return nil
+	}
+	procs := files.CapsuleProcesses(filepath.Join(ws.RepoDir(repo), capsule))
+	holders := slices.DeleteFunc(procs, func(p workspace.Process) bool { return !p.HoldsFiles() })
+	if len(holders) > 0 {
+		return fmt.Errorf("%s; stop them or use ws burn --kill", holdingFilesError(holders))
+	}
+	return nil
+}
+
+// mcOpenFiles takes one lsof snapshot for mc's burns, or nil if it can't.
+func mcOpenFiles() *workspace.OpenFiles {
+	files, _ := workspace.ListOpenFiles()
+	return files
+}
+
+func (m mcModel) doBulkBurn() (mcModel, tea.Cmd) {
+	ws := m.ws
+	openFiles := sync.OnceValue(mcOpenFiles)
+	items := m.bulkItems(func(row mcRow) func() error {
+		if row.kind != rowWorktree || row.wt == ws.DefaultBranch {
+			return nil
+		}
+		repo, capsule := row.repo, row.wt
+		display := ws.DisplayNameFor(repo)
+		return func() error {
+			check, err := ws.CheckRemoveWorktree(repo, capsule)
+			if err != nil {
+				return err
+			}
+			if check.IsDirty {
+				return errors.New("has uncommitted changes")
+			}
+			if target, ok := ws.Silo[repo]; ok && target == capsule {
+				return errors.New("is the active silo target")
+			}
+			if err := mcCheckHolders(ws, openFiles(), repo, capsule); err != nil {
+				return err
+			}
+			mux.KillCapsuleWindow(display, capsule)
+			if ws.IsBoarded(repo, capsule) {
+				_ = ws.Unboard(repo, capsule)
+				if err := saveBoarded(ws); err != nil {
+					return err
+				}
+			}
+			return ws.RemoveWorktree(repo, capsule, false)
+		}
+	})
+	return m.startBulk(&mcBulk{title: fmt.Sprintf("Burn %s", countOf(len(items), "capsule")), items: items, confirm: true})
+}
+
+func (m mcModel) doBulkBoard(board bool) (mcModel, tea.Cmd) {
+	ws := m.ws
+	items := m.bulkItems(func(row mcRow) func() error {
+		if row.kind != rowWorktree || row.wt == ws.DefaultBranch || row.isBoarded == board {
+			return nil
+		}
+		repo, capsule := row.repo, row.wt
+		return func() error {
+			var err error
+			if board {
+				err = ws.Board(repo, capsule)
+			} else {
+				err = ws.Unboard(repo, capsule)
+			}
+			if err != nil {
+				return err
+			}
+			return saveBoarded(ws)
+		}
+	})
+	title := "Board"
+	if !board {
+		title = "Unboard"
+	}
+	return m.startBulk(&mcBulk{title: fmt.Sprintf("%s %s", title, countOf(len(items), "capsule")), items: items})
+}
+
+// doBulkFetch fetches each repo with a marked row once.
+func (m mcModel) doBulkFetch() (mcModel, tea.Cmd) {
+	ws := m.ws
+	seen := make(map[string]bool)
+	var items []mcBulkItem
+	for _, i := range m.markedRows() {
+		repo := m.rows[i].repo
+		if seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		items = append(items, mcBulkItem{
+			label: ws.DisplayNameFor(repo),
+			run:   func() error { return ws.FetchRepo(repo).Err },
+		})
+	}
+	return m.startBulk(&mcBulk{title: fmt.Sprintf("Fetch %s", countOf(len(items), "repo")), items: items})
+}
+
+func (m mcModel) doBulkTmux() (mcModel, tea.Cmd) {
+	ws := m.ws
+	items := m.bulkItems(func(row mcRow) func() error {
+		if row.kind != rowWorktree {
+			return nil
+		}
+		name := mux.WindowName(ws.DisplayNameFor(row.repo), row.wt)
+		return func() error {
+			mx := mux.Detect()
+			if mx == nil {
+				return errors.New("not running inside tmux, Zellij or WezTerm")
+			}
+			if _, ok := mx.Windows()[name]; ok {
+				return nil
+			}
+			return openCapsuleWindow(mx, ws, row.repo, row.wt)
+		}
+	})
+	return m.startBulk(&mcBulk{title: fmt.Sprintf("Open %s in windows", countOf(len(items), "capsule")), items: items})
+}
+
+// doBulkSilo points each repo's silo at its marked capsule. Marking more
+// than one capsule in a repo is ambiguous, so those rows fail.
+func (m mcModel) doBulkSilo() (mcModel, tea.Cmd) {
+	ws := m.ws
+	perRepo := make(map[string]int)
+	for _, i := range m.markedRows() {
+		if m.rows[i].kind == rowWorktree {
+			perRepo[m.rows[i].repo]++
+		}
+	}
+	items := m.bulkItems(func(row mcRow) func() error {
+		if row.kind != rowWorktree {
+			return nil
+		}
+		if perRepo[row.repo] > 1 {
+			return func() error { return errors.New("more than one capsule marked in this repo") }
+		}
+		repo, capsule := row.repo, row.wt
+		return func() error { return pointSilo(ws, repo, capsule, io.Discard) }
+	})
+	return m.startBulk(&mcBulk{title: fmt.Sprintf("Point %s", countOf(len(items), "silo")), items: items})
+}
+
+func saveBoarded(ws *workspace.Workspace) error {
+	if err := config.SaveBoarded(ws.Root, ws.Boarded); err != nil {
+		return err
+	}
+	return ide.Regenerate(ws.Root, ws.Boarded, ws.DisplayNames, ws.Org)
+}
+
+func countOf(n int, noun string) string {
+	return fmt.Sprintf("%d %s", n, pluralize(n, noun, noun+"s"))
+}
+
+// --- rendering ---
+
+func (m mcModel) renderBulk() string {
+	b := m.bulk
+	var s strings.Builder
+	s.WriteString(lipgloss.NewStyle().Bold(true).Render("  "+b.title) + "\n\n")
+	for _, it := range b.items {
+		switch it.state {
+		case repoPending:
+			s.WriteString(fmt.Sprintf("  %s %s\n", ui.Dim.Render("·"), it.label))
+		case repoRunning:
+			s.WriteString(fmt.Sprintf("  %s %s\n", ui.Orange.Render("⟳"), it.label))
+		case repoDone:
+			s.WriteString(fmt.Sprintf("  %s %s\n", ui.Green.Render("✓"), it.label))
+		case repoFailed:
+			s.WriteString(fmt.Sprintf("  %s %s: %v\n", ui.Red.Render("✗"), it.label, it.err))
+		}
+	}
+	s.WriteString("\n")
+	switch {
+	case b.confirm:
+		s.WriteString(ui.Red.Render("  " + b.title + "? y/n"))
+	case !b.finished():
+		s.WriteString(ui.Dim.Render(fmt.Sprintf("  %d/%d", b.done, len(b.items))))
+	case b.failed() > 0:
+		s.WriteString(ui.Orange.Render(fmt.Sprintf("  %d of %d failed", b.failed(), len(b.items))) + ui.Dim.Render("  ⏎ to close"))
+	default:
+		s.WriteString(ui.Green.Render("  Done") + ui.Dim.Render("  ⏎ to close"))
+	}
+	return s.String()
+}
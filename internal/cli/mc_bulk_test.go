@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+func TestHandleKey_SpaceMarksAndAdvances(t *testing.T) {
+	m := keysMCModel()
+	m.cursor = 1
+
+	m, _ = m.handleKey(keyMsg(" "))
+	if !m.isMarked(1) {
+		t.Error("row 1 should be marked after space")
+	}
+	if m.cursor != 2 {
+		t.Errorf("cursor = %d, want 2 (advances after marking)", m.cursor)
+	}
+
+	m.cursor = 1
+	m, _ = m.handleKey(keyMsg(" "))
+	if m.isMarked(1) {
+		t.Error("row 1 should be unmarked after second space")
+	}
+}
+
+func TestHandleKey_RangeMarks(t *testing.T) {
+	m := keysMCModel()
+	m.cursor = 1
+
+	m, _ = m.handleKey(keyMsg("V"))
+	m, _ = m.handleKey(keyMsg("j"))
+	m, _ = m.handleKey(keyMsg("j"))
+	if got := m.markedRows(); len(got) != 3 {
+		t.Fatalf("markedRows = %v, want rows 1-3 while ranging", got)
+	}
+
+	m, _ = m.handleKey(keyMsg("V"))
+	if m.rangeActive {
+		t.Error("second V should end the range")
+	}
+	if len(m.marked) != 3 {
+		t.Errorf("marked = %v, want the range committed", m.marked)
+	}
+
+	// Moving after the range is committed doesn't change the marks.
+	m, _ = m.handleKey(keyMsg("k"))
+	if got := m.markedRows(); len(got) != 3 {
+		t.Errorf("markedRows = %v, want 3 after moving", got)
+	}
+}
+
+func TestHandleKey_EscClearsMarksBeforeFilters(t *testing.T) {
+	m := keysMCModel()
+	m.activeFilters = filterLocal
+	m.marked = map[string]bool{"repo1/feat": true}
+
+	m, _ = m.handleKey(keyMsg("esc"))
+	if m.hasMarks() {
+		t.Error("first esc should clear marks")
+	}
+	if m.activeFilters != filterLocal {
+		t.Error("first esc should leave filters alone")
+	}
+
+	m, _ = m.handleKey(keyMsg("esc"))
+	if m.activeFilters != 0 {
+		t.Error("second esc should clear filters")
+	}
+}
+
+func TestAvailableCommands_Marked(t *testing.T) {
+	m := keysMCModel()
+	if contains(cmdNames(m.availableCommands()), "bulk-burn") {
+		t.Error("bulk commands should be hidden with nothing marked")
+	}
+
+	m.marked = map[string]bool{"repo1/feat": true}
+	cmds := m.availableCommands()
+	if len(cmds) == 0 || cmds[0].scope != scopeMarked {
+		t.Error("bulk commands should sort first when rows are marked")
+	}
+}
+
+func TestBulk_RunsSequentiallyAndReportsFailures(t *testing.T) {
+	m := keysMCModel()
+	var ran []string
+	b := &mcBulk{title: "Test", items: []mcBulkItem{
+		{label: "a", run: func() error { ran = append(ran, "a"); return nil }},
+		{label: "b", run: func() error { ran = append(ran, "b"); return errors.New("boom") }},
+		{label: "c", run: func() error { ran = append(ran, "c"); return nil }},
+	}}
+
+	m, cmd := m.startBulk(b)
+	for cmd != nil {
+		m, cmd = m.handleBulkStep(cmd().(mcBulkStepMsg))
+	}
+
+	if len(ran) != 3 || ran[0] != "a" || ran[2] != "c" {
+		t.Errorf("ran = %v, want [a b c] in order", ran)
+	}
+	if !m.bulk.finished() {
+		t.Error("bulk should be finished")
+	}
+	if m.bulk.items[1].state != repoFailed || m.bulk.items[1].err == nil {
+		t.Error("item b should be reported as failed")
+	}
+	if m.bulk.items[2].state != repoDone {
+		t.Error("a failure should not stop later items")
+	}
+	if m.bulk.failed() != 1 {
+		t.Errorf("failed = %d, want 1", m.bulk.failed())
+	}
+}
+
+func TestBulkBurn_NeedsConfirmation(t *testing.T) {
+	m := keysMCModel()
+	m.marked = map[string]bool{"repo1/feat": true, "repo1/main": true, "repo1/ghost-pr": true}
+
+	m, cmd := m.doBulkBurn()
+	if cmd != nil {
+		t.Error("burn should not start before confirmation")
+	}
+	if m.bulk == nil || !m.bulk.confirm {
+		t.Fatal("expected a bulk burn awaiting confirmation")
+	}
+	// The default branch and ghost rows are skipped.
+	if len(m.bulk.items) != 1 {
+		t.Errorf("items = %d, want 1", len(m.bulk.items))
+	}
+
+	m, _ = m.handleKey(keyMsg("n"))
+	if m.bulk != nil {
+		t.Error("n should cancel the bulk burn")
+	}
+	if !m.hasMarks() {
+		t.Error("cancelling should keep the marks")
+	}
+}
+
+func TestMCCheckHolders(t *testing.T) {
+	if _, err := exec.LookPath("lsof"); err != nil {
+		t.Skip("lsof not installed")
+	}
+	ws := &workspace.Workspace{Root: t.TempDir()}
+	for _, capsule := range []string{"serving", "shell"} {
+		dir := filepath.Join(ws.RepoDir("repo1"), capsule)
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "log"), nil, 0o644)
+	}
+	start := func(capsule string, args ...string) {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = filepath.Join(ws.RepoDir("repo1"), capsule)
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { cmd.Process.Kill(); cmd.Wait() })
+	}
+	start("serving", "tail", "-f", "log")
+	start("shell", "sleep", "30")
+
+	files, err := workspace.ListOpenFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mcCheckHolders(ws, files, "repo1", "serving"); err == nil || !strings.Contains(err.Error(), "tail (pid") {
+		t.Errorf("serving: err = %v, want tail holding files open", err)
+	}
+	if err := mcCheckHolders(ws, files, "repo1", "shell"); err != nil {
+		t.Errorf("shell: err = %v, want a process only working there allowed", err)
+	}
+}
+
+func TestBulkSilo_MultipleCapsulesInRepoFail(t *testing.T) {
+	m := keysMCModel()
+	m.rows = append(m.rows, mcRow{kind: rowWorktree, repo: "repo1", wt: "other", branch: "other", loaded: true})
+	m.marked = map[string]bool{"repo1/feat": true, "repo1/other": true}
+
+	m, cmd := m.doBulkSilo()
+	for cmd != nil {
+		m, cmd = m.handleBulkStep(cmd().(mcBulkStepMsg))
+	}
+	if m.bulk.failed() != 2 {
+		t.Errorf("failed = %d, want 2 (ambiguous silo target)", m.bulk.failed())
+	}
+}
+
+func TestRunSiloHook_ReturnsFailure(t *testing.T) {
+	err := runSiloHook(t.TempDir(), "after_switch", "echo installing; echo 'port 5432 in use' >&2; exit 3", io.Discard)
+	var hookErr *siloHookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("err = %v, want a siloHookError", err)
+	}
+	if want := "after_switch hook failed: exit status 3 (port 5432 in use)"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+	if err := runSiloHook(t.TempDir(), "after_switch", "true", io.Discard); err != nil {
+		t.Errorf("passing hook returned %v", err)
+	}
+}
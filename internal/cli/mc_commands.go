@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/mux"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- user-defined commands ---
+//
+// Commands declared in [[mc.commands]] join the palette alongside the
+// built-in registry. Their run string is a text/template rendered against
+// mcCommandData for the selected row.
+
+// mcCommandData is the template data for a custom command's run string.
+type mcCommandData struct {
+	Org     string
+	Root    string
+	Repo    string
+	Capsule string
+	Branch  string
+	Path    string
+	PR      mcCommandPR
+}
+
+type mcCommandPR struct {
+	Number int
+	URL    string
+	Title  string
+	Author string
+}
+
+var commandScopes = map[string]paletteScope{
+	"":         scopeAlways,
+	"always":   scopeAlways,
+	"worktree": scopeWorktree,
+	"remote":   scopeGhostPR,
+	"pr":       scopeHasPR,
+	"repo":     scopeRepo,
+}
+
+// customCommands returns a palette command per [[mc.commands]] entry.
+func (m mcModel) customCommands() []paletteCommand {
+	var cmds []paletteCommand
+	for _, c := range m.mcCfg.Commands {
+		cmds = append(cmds, paletteCommand{
+			name:  "cmd:" + c.Label,
+			label: c.Label,
+			desc:  c.Run,
+			scope: commandScopes[c.Scope],
+			run:   func(m mcModel) (mcModel, tea.Cmd) { return m.runCustomCommand(c) },
+		})
+	}
+	return cmds
+}
+
+func (m mcModel) commandData() mcCommandData {
+	d := mcCommandData{Org: m.ws.Org, Root: m.ws.Root, Path: m.ws.Root}
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return d
+	}
+	row := m.rows[m.cursor]
+	if row.repo == "" {
+		return d
+	}
+	d.Repo = row.repo
+	d.Branch = rowBranch(row)
+	d.Path = m.ws.MainWorktree(row.repo)
+	if row.kind == rowWorktree {
+		d.Capsule = row.wt
+		d.Path = filepath.Join(m.ws.RepoDir(row.repo), row.wt)
+	}
+	if row.pr != nil {
+		d.PR = mcCommandPR{Number: row.pr.Number, URL: row.pr.URL, Title: row.pr.Title, Author: row.pr.Author}
+	}
+	return d
+}
+
+func renderCommand(c config.MCCommand, data mcCommandData) (string, error) {
+	tmpl, err := template.New(c.Label).Option("missingkey=error").Parse(c.Run)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// runCustomCommand runs c for the selected row: in a new tmux window when
+// asked for and available, otherwise in the foreground with the dashboard
+// suspended until the user dismisses the output.
+func (m mcModel) runCustomCommand(c config.MCCommand) (mcModel, tea.Cmd) {
+	data := m.commandData()
+	script, err := renderCommand(c, data)
+	if err != nil {
+		m.flash = fmt.Sprintf("%s: %v", c.Label, err)
+		return m, nil
+	}
+
+	if mx := mux.Detect(); c.Tmux && mx != nil {
+		// Keep the window open with a shell once the command finishes.
+		shell := script + `; exec "${SHELL:-sh}"`
+		return m, func() tea.Msg {
+			return mcCommandDoneMsg{label: c.Label, err: mx.Run(c.Label, data.Path, "sh", "-c", shell)}
+		}
+	}
+
+	wrapped := `sh -c "$1"; status=$?; printf '\n[exit %d] press enter to return ' "$status"; read _`
+	cmd := exec.Command("sh", "-c", wrapped, "sh", script)
+	cmd.Dir = data.Path
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return mcCommandDoneMsg{label: c.Label, err: err, foreground: true}
+	})
+}
+
+func (m mcModel) handleCommandDone(msg mcCommandDoneMsg) (mcModel, tea.Cmd) {
+	if msg.err != nil {
+		m.flash = fmt.Sprintf("%s: %v", msg.label, msg.err)
+	}
+	if msg.foreground {
+		return m, tea.ClearScreen
+	}
+	return m, queryMuxWindows()
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/github"
+)
+
+func TestCustomCommands_Scope(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Commands: []config.MCCommand{
+		{Label: "Run tests", Run: "make test", Scope: "worktree", Key: "t"},
+		{Label: "Review", Run: "gh pr checkout {{.PR.Number}}", Scope: "remote"},
+		{Label: "Status", Run: "git status"},
+	}}
+
+	m.cursor = 2 // worktree
+	names := cmdNames(m.availableCommands())
+	if !contains(names, "cmd:Run tests") || !contains(names, "cmd:Status") {
+		t.Errorf("expected worktree and global commands, got %v", names)
+	}
+	if contains(names, "cmd:Review") {
+		t.Error("remote-scoped command should not appear on a worktree row")
+	}
+
+	m.cursor = 3 // ghost PR
+	names = cmdNames(m.availableCommands())
+	if !contains(names, "cmd:Review") {
+		t.Error("expected remote-scoped command on ghost row")
+	}
+	if contains(names, "cmd:Run tests") {
+		t.Error("worktree-scoped command should not appear on ghost row")
+	}
+}
+
+func TestHandleKey_CustomCommandKey(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Commands: []config.MCCommand{
+		{Label: "Run tests", Run: "make test", Scope: "worktree", Key: "t"},
+	}}
+
+	m.cursor = 2
+	if _, cmd := m.handleKey(keyMsg("t")); cmd == nil {
+		t.Error("expected t to run the command on a worktree row")
+	}
+	m.cursor = 3
+	if _, cmd := m.handleKey(keyMsg("t")); cmd != nil {
+		t.Error("t should not run a worktree command on a ghost row")
+	}
+}
+
+func TestRunCustomCommand_TemplateErrorFlashes(t *testing.T) {
+	m := keysMCModel()
+	m.cursor = 2
+	m, cmd := m.runCustomCommand(config.MCCommand{Label: "Open", Run: "open {{.Nope}}"})
+	if cmd != nil {
+		t.Error("a command that doesn't render shouldn't run")
+	}
+	if !strings.HasPrefix(m.flash, "Open: ") {
+		t.Errorf("flash = %q, want the template error", m.flash)
+	}
+	if !strings.Contains(m.renderHelpBar(), m.flash) {
+		t.Error("help bar should show the error")
+	}
+
+	m, _ = m.handleKey(keyMsg("j"))
+	if m.flash != "" {
+		t.Errorf("flash = %q, want it cleared by the next key", m.flash)
+	}
+}
+
+func TestHandleCommandDone_ErrorFlashes(t *testing.T) {
+	m := keysMCModel()
+	m, _ = m.handleCommandDone(mcCommandDoneMsg{label: "Deploy", err: errors.New("exit status 1")})
+	if m.flash != "Deploy: exit status 1" {
+		t.Errorf("flash = %q, want the command's error", m.flash)
+	}
+}
+
+func TestRenderCommand_Template(t *testing.T) {
+	m := keysMCModel()
+	m.ws.Root = "/ws"
+	m.cursor = 2
+	m.rows[2].pr = &github.PR{Number: 42, URL: "https://example.com/pr/42"}
+
+	data := m.commandData()
+	if data.Path != filepath.Join("/ws", "repos", "repo1", "feat") {
+		t.Errorf("Path = %q, want capsule dir", data.Path)
+	}
+
+	got, err := renderCommand(config.MCCommand{
+		Label: "x",
+		Run:   "echo {{.Org}} {{.Repo}} {{.Capsule}} {{.Branch}} {{.PR.Number}}",
+	}, data)
+	if err != nil {
+		t.Fatalf("renderCommand error: %v", err)
+	}
+	if want := "echo testorg repo1 feat feat 42"; got != want {
+		t.Errorf("rendered = %q, want %q", got, want)
+	}
+
+	if _, err := renderCommand(config.MCCommand{Label: "x", Run: "{{.Nope}}"}, data); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestRenderCommand_GhostRunsInGround(t *testing.T) {
+	m := keysMCModel()
+	m.ws.Root = "/ws"
+	m.cursor = 3
+
+	data := m.commandData()
+	if data.Path != m.ws.MainWorktree("repo1") {
+		t.Errorf("Path = %q, want ground for a ghost row", data.Path)
+	}
+	if data.Capsule != "" || data.Branch != "ghost-pr" {
+		t.Errorf("data = %+v, want branch only", data)
+	}
+}
@@ -0,0 +1,372 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brudil/workspace/internal/workspace"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- filter expressions ---
+//
+// A filter expression is a whitespace-separated list of terms that must all
+// match for a row to be visible:
+//
+//	repo:api dirty behind>0 pr:failing author:@me age>7d
+//
+// Terms are one of:
+//   - a bare keyword (dirty, clean, local, remote, boarded, live, landed,
+//     mine, review, stacked, restack, linked), also accepted as is:<keyword>
+//   - key:value for repo, branch, author, pr and tag
+//   - a numeric comparison on ahead, behind or age (>, >=, <, <=, =)
+//   - free text, fuzzy-matched against the branch name
+//
+// Any term can be negated with a leading "-" or "!".
+
+type filterTerm struct {
+	negate bool
+	match  func(m mcModel, row mcRow) bool
+}
+
+type filterExpr []filterTerm
+
+// matches reports whether every term in the expression matches the row.
+func (e filterExpr) matches(m mcModel, row mcRow) bool {
+	for _, t := range e {
+		if t.match(m, row) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+var filterKeywords = map[string]func(m mcModel, row mcRow) bool{
+	"dirty":   func(_ mcModel, row mcRow) bool { return row.dirty },
+	"clean":   func(_ mcModel, row mcRow) bool { return row.kind == rowWorktree && row.loaded && !row.dirty },
+	"local":   func(_ mcModel, row mcRow) bool { return row.kind == rowWorktree },
+	"remote":  func(_ mcModel, row mcRow) bool { return row.kind == rowGhostPR },
+	"boarded": func(_ mcModel, row mcRow) bool { return row.isBoarded },
+	"live":    func(_ mcModel, row mcRow) bool { return row.live },
+	"landed":  func(_ mcModel, row mcRow) bool { return row.merged },
+	"mine":    func(m mcModel, row mcRow) bool { return matchAuthor(m, row, "@me") },
+	"review":  func(_ mcModel, row mcRow) bool { return row.pr != nil && row.pr.ReviewDecision == "REVIEW_REQUIRED" },
+	"stacked": func(m mcModel, row mcRow) bool { return row.stack.Stacked(m.ws.DefaultBranch) },
+	"restack": func(m mcModel, row mcRow) bool { return row.stack.NeedsRestack(m.ws.DefaultBranch) },
+	"linked":  func(_ mcModel, row mcRow) bool { return len(row.linked) > 0 },
+}
+
+// parsedFilter is the filter input parsed once per edit, rather than once per
+// row on every render.
+type parsedFilter struct {
+	text string
+	expr filterExpr
+	err  error
+}
+
+// setFilter replaces the filter input's text.
+func (m *mcModel) setFilter(text string) {
+	m.filterInput.SetValue(text)
+	m.syncFilter()
+}
+
+// syncFilter parses the filter input if it has changed since it was last
+// parsed.
+func (m *mcModel) syncFilter() {
+	text := m.filterInput.Value()
+	if text == m.filter.text {
+		return
+	}
+	expr, err := parseFilterExpr(text)
+	m.filter = parsedFilter{text: text, expr: expr, err: err}
+}
+
+// parseFilterExpr parses a filter expression. An empty input yields an
+// empty expression that matches every row.
+func parseFilterExpr(input string) (filterExpr, error) {
+	var expr filterExpr
+	for _, tok := range strings.Fields(input) {
+		term, err := parseFilterTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		expr = append(expr, term)
+	}
+	return expr, nil
+}
+
+func parseFilterTerm(tok string) (filterTerm, error) {
+	var term filterTerm
+	if len(tok) > 1 && (tok[0] == '-' || tok[0] == '!') {
+		term.negate = true
+		tok = tok[1:]
+	}
+
+	lower := strings.ToLower(tok)
+	if fn, ok := filterKeywords[lower]; ok {
+		term.match = fn
+		return term, nil
+	}
+
+	// Numeric comparisons: ahead>0, behind>=2, age>7d
+	if key, op, value, ok := splitComparison(lower); ok {
+		match, err := parseComparison(key, op, value)
+		if err != nil {
+			return term, err
+		}
+		term.match = match
+		return term, nil
+	}
+
+	if key, value, ok := strings.Cut(tok, ":"); ok && value != "" {
+		match, err := parseKeyValue(strings.ToLower(key), value)
+		if err != nil {
+			return term, err
+		}
+		if match != nil {
+			term.match = match
+			return term, nil
+		}
+	}
+
+	text := tok
+	term.match = func(_ mcModel, row mcRow) bool {
+		return workspace.FuzzyMatch(text, rowBranch(row))
+	}
+	return term, nil
+}
+
+func parseKeyValue(key, value string) (func(m mcModel, row mcRow) bool, error) {
+	switch key {
+	case "is":
+		fn, ok := filterKeywords[strings.ToLower(value)]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter is:%s", value)
+		}
+		return fn, nil
+	case "repo":
+		return func(m mcModel, row mcRow) bool { return matchRepo(m, row.repo, value) }, nil
+	case "branch":
+		return func(_ mcModel, row mcRow) bool { return workspace.FuzzyMatch(value, rowBranch(row)) }, nil
+	case "author":
+		return func(m mcModel, row mcRow) bool { return matchAuthor(m, row, value) }, nil
+	case "pr":
+		return parsePRState(strings.ToLower(value))
+	case "tag":
+		tag := strings.TrimPrefix(value, "#")
+		return func(_ mcModel, row mcRow) bool { return row.meta.HasTag(tag) }, nil
+	}
+	// Unknown keys fall through to free-text matching.
+	return nil, nil
+}
+
+func parsePRState(state string) (func(m mcModel, row mcRow) bool, error) {
+	switch state {
+	case "none":
+		return func(_ mcModel, row mcRow) bool { return row.pr == nil }, nil
+	case "any", "open":
+		return func(_ mcModel, row mcRow) bool { return row.pr != nil }, nil
+	case "failing", "failure", "fail":
+		return prRollupIs("failure"), nil
+	case "passing", "success", "pass":
+		return prRollupIs("success"), nil
+	case "pending":
+		return prRollupIs("pending"), nil
+	case "approved", "cleared":
+		return prReviewIs("APPROVED"), nil
+	case "changes":
+		return prReviewIs("CHANGES_REQUESTED"), nil
+	case "review":
+		return prReviewIs("REVIEW_REQUIRED"), nil
+	}
+	return nil, fmt.Errorf("unknown PR state %q (try failing, passing, pending, approved, changes, review, none)", state)
+}
+
+func prRollupIs(status string) func(m mcModel, row mcRow) bool {
+	return func(_ mcModel, row mcRow) bool { return row.pr != nil && row.pr.StatusRollup == status }
+}
+
+func prReviewIs(decision string) func(m mcModel, row mcRow) bool {
+	return func(_ mcModel, row mcRow) bool { return row.pr != nil && row.pr.ReviewDecision == decision }
+}
+
+// splitComparison splits "behind>=2" into ("behind", ">=", "2").
+func splitComparison(tok string) (key, op, value string, ok bool) {
+	i := strings.IndexAny(tok, "<>=")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	key = tok[:i]
+	rest := tok[i:]
+	switch {
+	case strings.HasPrefix(rest, ">="), strings.HasPrefix(rest, "<="):
+		op, value = rest[:2], rest[2:]
+	default:
+		op, value = rest[:1], rest[1:]
+	}
+	if value == "" {
+		return "", "", "", false
+	}
+	switch key {
+	case "ahead", "behind", "age":
+		return key, op, value, true
+	}
+	return "", "", "", false
+}
+
+func parseComparison(key, op, value string) (func(m mcModel, row mcRow) bool, error) {
+	if key == "age" {
+		d, err := parseAge(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ mcModel, row mcRow) bool {
+			if row.lastCommit.IsZero() {
+				return false
+			}
+			return compareInt(op, int64(nowFunc().Sub(row.lastCommit)), int64(d))
+		}, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number in %s%s%s", key, op, value)
+	}
+	return func(_ mcModel, row mcRow) bool {
+		v := row.ahead
+		if key == "behind" {
+			v = row.behind
+		}
+		return row.loaded && compareInt(op, int64(v), int64(n))
+	}, nil
+}
+
+func compareInt(op string, a, b int64) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	default:
+		return a == b
+	}
+}
+
+// parseAge parses durations like "30m", "12h", "7d" and "2w".
+func parseAge(s string) (time.Duration, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if i <= 0 || i != len(s)-1 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30m, 12h, 7d, 2w)", s)
+	}
+	n, _ := strconv.Atoi(s[:i])
+	unit := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}[s[i]]
+	if unit == 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30m, 12h, 7d, 2w)", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// matchRepo matches a repo by canonical name, alias, or fuzzy match on the
+// canonical or display name.
+func matchRepo(m mcModel, repo, query string) bool {
+	if m.ws != nil {
+		if canonical, ok := m.ws.ResolveAlias(query); ok {
+			return canonical == repo
+		}
+		if workspace.FuzzyMatch(query, m.ws.DisplayNameFor(repo)) {
+			return true
+		}
+	}
+	return workspace.FuzzyMatch(query, repo)
+}
+
+// matchAuthor matches a row's PR author. "@me" refers to the signed-in
+// GitHub user and matches nothing until that user is known.
+func matchAuthor(m mcModel, row mcRow, author string) bool {
+	if author == "@me" {
+		if m.ghUser == "" {
+			return false
+		}
+		author = m.ghUser
+	}
+	author = strings.TrimPrefix(author, "@")
+	return row.pr != nil && strings.EqualFold(row.pr.Author, author)
+}
+
+func rowBranch(row mcRow) string {
+	if row.branch != "" {
+		return row.branch
+	}
+	return row.wt
+}
+
+// --- saved views ---
+
+// applyView replaces the filter expression with the named view's filter.
+func (m mcModel) applyView(name string) mcModel {
+	v, ok := m.mcCfg.Views[name]
+	if !ok {
+		return m
+	}
+	m.setFilter(v.Filter)
+	m.activeView = name
+	m.ensureCursorOnVisible()
+	return m
+}
+
+// toggleView applies the named view, or clears it if it is already active.
+func (m mcModel) toggleView(name string) mcModel {
+	if m.currentView() != name {
+		return m.applyView(name)
+	}
+	m.setFilter("")
+	m.activeView = ""
+	m.ensureCursorOnVisible()
+	return m
+}
+
+// currentView returns the active view, or "" once the filter has been
+// edited away from the view's expression.
+func (m mcModel) currentView() string {
+	if m.activeView == "" {
+		return ""
+	}
+	if v, ok := m.mcCfg.Views[m.activeView]; ok && v.Filter == m.filterInput.Value() {
+		return m.activeView
+	}
+	return ""
+}
+
+func (m mcModel) viewNames() []string {
+	return slices.Sorted(maps.Keys(m.mcCfg.Views))
+}
+
+// viewCommands returns a palette command per saved view.
+func (m mcModel) viewCommands() []paletteCommand {
+	var cmds []paletteCommand
+	for _, name := range m.viewNames() {
+		v := m.mcCfg.Views[name]
+		cmds = append(cmds, paletteCommand{
+			name:  "view:" + name,
+			label: "View: " + name,
+			desc:  v.Filter,
+			scope: scopeAlways,
+			run:   func(m mcModel) (mcModel, tea.Cmd) { return m.toggleView(name), nil },
+		})
+	}
+	return cmds
+}
@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/workspace"
 )
 
 func TestIsRowVisible_NoFilters(t *testing.T) {
@@ -142,3 +145,128 @@ func TestIsRowVisible_RepoHeader(t *testing.T) {
 		t.Error("repo header should never be directly visible")
 	}
 }
+
+func TestFilterExpr(t *testing.T) {
+	orig := nowFunc
+	nowFunc = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { nowFunc = orig }()
+
+	m := mcModel{
+		ws: &workspace.Workspace{
+			RepoNames:    []string{"api", "web"},
+			DisplayNames: map[string]string{},
+			AliasMap:     map[string]string{"backend": "api"},
+		},
+		ghUser: "alice",
+		rows: []mcRow{
+			{kind: rowWorktree, repo: "api", wt: "feat-login", branch: "feat-login", dirty: true, behind: 2, loaded: true,
+				lastCommit: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+				pr:         &github.PR{Author: "alice", StatusRollup: "failure"}},
+			{kind: rowWorktree, repo: "web", wt: "fix-nav", branch: "fix-nav", ahead: 1, loaded: true,
+				lastCommit: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+				pr:         &github.PR{Author: "bob", StatusRollup: "success", ReviewDecision: "APPROVED"},
+				meta:       workspace.CapsuleMeta{Tags: []string{"oncall"}}},
+			{kind: rowGhostPR, repo: "api", branch: "bump-deps", pr: &github.PR{Author: "bot", ReviewDecision: "REVIEW_REQUIRED"}},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"", []int{0, 1, 2}},
+		{"dirty", []int{0}},
+		{"-dirty", []int{1, 2}},
+		{"is:remote", []int{2}},
+		{"repo:api", []int{0, 2}},
+		{"repo:backend", []int{0, 2}},
+		{"repo:api local", []int{0}},
+		{"behind>0", []int{0}},
+		{"ahead>=1", []int{1}},
+		{"age>7d", []int{0}},
+		{"age<2d", []int{1}},
+		{"pr:failing", []int{0}},
+		{"pr:approved", []int{1}},
+		{"pr:review", []int{2}},
+		{"pr:none", nil},
+		{"author:@me", []int{0}},
+		{"author:bob", []int{1}},
+		{"mine", []int{0}},
+		{"!mine", []int{1, 2}},
+		{"nav", []int{1}},
+		{"branch:deps", []int{2}},
+		{"tag:oncall", []int{1}},
+		{"-tag:#oncall", []int{0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parseFilterExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("parseFilterExpr(%q) error: %v", tt.expr, err)
+			}
+			var got []int
+			for i, row := range m.rows {
+				if expr.matches(m, row) {
+					got = append(got, i)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterExpr_MeBeforeLoginLoads(t *testing.T) {
+	m := mcModel{rows: []mcRow{
+		{kind: rowWorktree, wt: "feat", branch: "feat", pr: &github.PR{Author: "alice"}},
+	}}
+	m.setFilter("mine")
+	if m.isRowVisible(0) {
+		t.Error("mine should match nothing until the GitHub login is known")
+	}
+	m.ghUser = "alice"
+	if !m.isRowVisible(0) {
+		t.Error("mine should match alice's PR once the login is known")
+	}
+}
+
+func TestSetFilter_ParsesOnChange(t *testing.T) {
+	var m mcModel
+	m.setFilter("dirty")
+	if m.filter.text != "dirty" || m.filter.err != nil || len(m.filter.expr) != 1 {
+		t.Fatalf("filter = %+v, want dirty parsed", m.filter)
+	}
+	m.setFilter("pr:bogus")
+	if m.filter.err == nil {
+		t.Error("expected the parse error to be kept")
+	}
+	m.setFilter("")
+	if m.filter.text != "" || m.filter.expr != nil {
+		t.Errorf("filter = %+v, want cleared", m.filter)
+	}
+}
+
+func TestParseFilterExpr_Errors(t *testing.T) {
+	for _, expr := range []string{"pr:bogus", "is:bogus", "age>7y", "behind>x"} {
+		if _, err := parseFilterExpr(expr); err == nil {
+			t.Errorf("parseFilterExpr(%q) should fail", expr)
+		}
+	}
+}
+
+func TestIsRowVisible_InvalidExprFallsBackToText(t *testing.T) {
+	m := mcModel{
+		rows: []mcRow{
+			{kind: rowWorktree, wt: "pr:bogus", branch: "pr:bogus", loaded: true},
+			{kind: rowWorktree, wt: "main", branch: "main", loaded: true},
+		},
+	}
+	m.setFilter("pr:bogus")
+	if !m.isRowVisible(0) {
+		t.Error("invalid expression should fall back to fuzzy branch match")
+	}
+	if m.isRowVisible(1) {
+		t.Error("non-matching branch should be hidden")
+	}
+}
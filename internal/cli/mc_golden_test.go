@@ -227,3 +227,28 @@ func TestGolden_MC_PaletteOpen(t *testing.T) {
 	m.paletteInput.Focus()
 	golden.RequireEqual(t, m.View())
 }
+
+func TestGolden_MC_StackedBranches(t *testing.T) {
+	pinClock(t)
+	m := goldenMCModel()
+	m.applyStack("frontend", map[string]workspace.StackEdge{
+		"feat-auth":  {Parent: "main", Ahead: 2},
+		"fix-styles": {Parent: "redesign", Ahead: 1},
+		"redesign":   {Parent: "feat-auth", Ahead: 3, Behind: 1},
+	})
+	golden.RequireEqual(t, m.View())
+}
+
+func TestGolden_MC_LinkedPRs(t *testing.T) {
+	pinClock(t)
+	m := goldenMCModel()
+	m.repos[0].prGroups = map[string]string{"feat-auth": "auth"}
+	m.repos[1].prGroups = map[string]string{"add-api": "auth"}
+	m.processPRs("frontend", []github.PR{
+		{Number: 42, Title: "Add authentication flow", HeadRefName: "feat-auth", State: "OPEN"},
+	})
+	m.processPRs("backend", []github.PR{
+		{Number: 7, Title: "Add token endpoint", HeadRefName: "add-api", State: "OPEN", ReviewDecision: "REVIEW_REQUIRED"},
+	})
+	golden.RequireEqual(t, m.View())
+}
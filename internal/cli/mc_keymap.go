@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/brudil/workspace/internal/config"
+)
+
+// --- keymap ---
+//
+// Every key press on the dashboard resolves to a named action. Built-in
+// actions have default keys that [mc.keys] can remap or unbind, and palette
+// commands can be bound by name (including view:<name> and cmd:<label>).
+// Arrow keys and ctrl+c are fixed aliases that can't be remapped.
+
+type mcKeyAction struct {
+	name string
+	key  string // default key
+}
+
+var mcDefaultKeys = []mcKeyAction{
+	{"quit", "q"},
+	{"filter", "/"},
+	{"palette", ":"},
+	{"help", "?"},
+	{"down", "j"},
+	{"up", "k"},
+	{"scroll-down", "J"},
+	{"scroll-up", "K"},
+	{"ground", "l"},
+	{"leave-ground", "h"},
+	{"go", "enter"},
+	{"open", "o"},
+	{"board", "b"},
+	{"dock", "d"},
+	{"refresh", "r"},
+	{"mark", " "},
+	{"range", "V"},
+	{"filter-local", "1"},
+	{"filter-mine", "2"},
+	{"filter-review", "3"},
+	{"filter-dirty", "4"},
+	{"clear", "esc"},
+}
+
+var mcFixedKeys = map[string]string{
+	"ctrl+c":     "quit",
+	"down":       "down",
+	"up":         "up",
+	"shift+down": "scroll-down",
+	"shift+up":   "scroll-up",
+	"right":      "ground",
+	"left":       "leave-ground",
+}
+
+// paletteActions maps palette command names onto the built-in action whose
+// key they share.
+var paletteActions = map[string]string{
+	"unboard": "board",
+	"undock":  "dock",
+}
+
+type mcKeymap struct {
+	keys    map[string]string // action → key
+	actions map[string]string // key → action
+}
+
+// newMCKeymap builds the effective keymap from the defaults, [mc.keys], and
+// the keys of saved views and custom commands. Every key may be bound to
+// only one action.
+func newMCKeymap(cfg config.MCConfig) (mcKeymap, error) {
+	km := mcKeymap{keys: make(map[string]string), actions: make(map[string]string)}
+	var order []string
+	for _, a := range mcDefaultKeys {
+		km.keys[a.name] = a.key
+		order = append(order, a.name)
+	}
+
+	var paletteNames []string
+	for _, c := range paletteCommands() {
+		paletteNames = append(paletteNames, c.name)
+	}
+	for name := range cfg.Views {
+		paletteNames = append(paletteNames, "view:"+name)
+	}
+	for _, c := range cfg.Commands {
+		paletteNames = append(paletteNames, "cmd:"+c.Label)
+	}
+
+	for _, action := range slices.Sorted(maps.Keys(cfg.Keys)) {
+		if _, builtin := km.keys[action]; !builtin {
+			if !slices.Contains(paletteNames, action) {
+				return mcKeymap{}, fmt.Errorf("unknown action %q in [mc.keys]", action)
+			}
+			order = append(order, action)
+		}
+		km.keys[action] = normalizeKey(cfg.Keys[action])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Views)) {
+		if _, set := cfg.Keys["view:"+name]; set {
+			continue
+		}
+		if key := cfg.Views[name].Key; key != "" {
+			km.keys["view:"+name] = key
+			order = append(order, "view:"+name)
+		}
+	}
+	for _, c := range cfg.Commands {
+		if _, set := cfg.Keys["cmd:"+c.Label]; set {
+			continue
+		}
+		if c.Key != "" {
+			km.keys["cmd:"+c.Label] = normalizeKey(c.Key)
+			order = append(order, "cmd:"+c.Label)
+		}
+	}
+
+	for _, action := range order {
+		key := km.keys[action]
+		if key == "" {
+			continue
+		}
+		if fixed, ok := mcFixedKeys[key]; ok {
+			return mcKeymap{}, fmt.Errorf("key %q for %s is reserved for %s", displayKey(key), action, fixed)
+		}
+		if other, ok := km.actions[key]; ok && other != action {
+			return mcKeymap{}, fmt.Errorf("key %q is bound to both %s and %s", displayKey(key), other, action)
+		}
+		km.actions[key] = action
+	}
+	return km, nil
+}
+
+// isBuiltinAction reports whether name is a built-in action or a palette
+// command that shares one's key.
+func isBuiltinAction(name string) bool {
+	if _, ok := paletteActions[name]; ok {
+		return true
+	}
+	return slices.ContainsFunc(mcDefaultKeys, func(a mcKeyAction) bool { return a.name == name })
+}
+
+func defaultMCKeymap() mcKeymap {
+	km, _ := newMCKeymap(config.MCConfig{})
+	return km
+}
+
+// keymap returns the model's keymap, building it from config for models
+// that were constructed without one.
+func (m mcModel) keymap() mcKeymap {
+	if m.keys.actions != nil {
+		return m.keys
+	}
+	if km, err := newMCKeymap(m.mcCfg); err == nil {
+		return km
+	}
+	return defaultMCKeymap()
+}
+
+// action returns the action bound to a key press, or "".
+func (km mcKeymap) action(key string) string {
+	if a, ok := mcFixedKeys[key]; ok {
+		return a
+	}
+	return km.actions[key]
+}
+
+// key returns the key bound to an action, or "" when it's unbound.
+func (km mcKeymap) key(action string) string {
+	if key, ok := km.keys[action]; ok {
+		return key
+	}
+	return km.keys[paletteActions[action]]
+}
+
+// hint renders an action's key compactly for the palette's key column.
+func (km mcKeymap) hint(action string) string {
+	switch key := km.key(action); key {
+	case "enter":
+		return "⏎"
+	case " ":
+		return "␣"
+	case "esc":
+		return "⎋"
+	default:
+		return key
+	}
+}
+
+// label renders an action's key for the help overlay and help bar.
+func (km mcKeymap) label(action string) string {
+	return displayKey(km.key(action))
+}
+
+func normalizeKey(key string) string {
+	if strings.EqualFold(key, "space") {
+		return " "
+	}
+	return key
+}
+
+func displayKey(key string) string {
+	switch key {
+	case " ":
+		return "Space"
+	case "enter":
+		return "Enter"
+	case "esc":
+		return "Esc"
+	default:
+		return key
+	}
+}
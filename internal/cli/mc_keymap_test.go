@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brudil/workspace/internal/config"
+)
+
+func TestNewMCKeymap_Defaults(t *testing.T) {
+	km := defaultMCKeymap()
+	if km.action("o") != "open" {
+		t.Errorf("action(o) = %q, want open", km.action("o"))
+	}
+	if km.action("right") != "ground" {
+		t.Errorf("action(right) = %q, want ground", km.action("right"))
+	}
+	if km.key("undock") != "d" {
+		t.Errorf("key(undock) = %q, want the dock key", km.key("undock"))
+	}
+}
+
+func TestNewMCKeymap_Remap(t *testing.T) {
+	km, err := newMCKeymap(config.MCConfig{Keys: map[string]string{
+		"open":     "e",
+		"refresh":  "",
+		"github":   "g",
+		"mark":     "space",
+		"quit":     "Q",
+		"view:foo": "5",
+	}, Views: map[string]config.MCView{"foo": {Filter: "dirty"}}})
+	if err != nil {
+		t.Fatalf("newMCKeymap error: %v", err)
+	}
+	if km.action("e") != "open" || km.action("o") != "" {
+		t.Error("open should move from o to e")
+	}
+	if km.key("refresh") != "" || km.action("r") != "" {
+		t.Error("refresh should be unbound")
+	}
+	if km.action("g") != "github" {
+		t.Error("palette commands should be bindable by name")
+	}
+	if km.action(" ") != "mark" {
+		t.Error(`"space" should bind the space bar`)
+	}
+	if km.action("5") != "view:foo" {
+		t.Error("views should be bindable as view:<name>")
+	}
+}
+
+func TestNewMCKeymap_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.MCConfig
+		want string
+	}{
+		{"unknown action", config.MCConfig{Keys: map[string]string{"nope": "x"}}, "unknown action"},
+		{"duplicate", config.MCConfig{Keys: map[string]string{"open": "b"}}, "bound to both"},
+		{"fixed key", config.MCConfig{Keys: map[string]string{"open": "left"}}, "reserved"},
+		{"view clashes with preset", config.MCConfig{Views: map[string]config.MCView{
+			"v": {Filter: "dirty", Key: "1"},
+		}}, "bound to both"},
+		{"command clashes with built-in", config.MCConfig{Commands: []config.MCCommand{
+			{Label: "x", Run: "y", Key: "d"},
+		}}, "bound to both"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newMCKeymap(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleKey_RemappedKey(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Keys: map[string]string{"down": "n", "up": ""}}
+	m.cursor = 1
+
+	m, _ = m.handleKey(keyMsg("j"))
+	if m.cursor != 1 {
+		t.Error("j should do nothing once down is remapped")
+	}
+	m, _ = m.handleKey(keyMsg("n"))
+	if m.cursor != 2 {
+		t.Errorf("cursor = %d, want 2 after n", m.cursor)
+	}
+	m, _ = m.handleKey(keyMsg("up"))
+	if m.cursor != 1 {
+		t.Error("arrow keys should keep working when up is unbound")
+	}
+}
+
+func TestAvailableCommands_KeyHints(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Keys: map[string]string{"github": "g", "open": "e"}}
+	m.cursor = 2
+
+	hints := map[string]string{}
+	for _, cmd := range m.availableCommands() {
+		hints[cmd.name] = cmd.key
+	}
+	if hints["open"] != "e" {
+		t.Errorf("open hint = %q, want e", hints["open"])
+	}
+	if hints["go"] != "⏎" {
+		t.Errorf("go hint = %q, want ⏎", hints["go"])
+	}
+}
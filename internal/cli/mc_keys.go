@@ -6,20 +6,23 @@ import (
 
 	"github.com/brudil/workspace/internal/config"
 	"github.com/brudil/workspace/internal/ide"
-	tmuxpkg "github.com/brudil/workspace/internal/tmux"
+	"github.com/brudil/workspace/internal/mux"
 	"github.com/brudil/workspace/internal/workspace"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m mcModel) handleKey(msg tea.KeyMsg) (mcModel, tea.Cmd) {
+	m.flash = ""
+
 	// Filter mode: route keys to textinput
 	if m.filterActive {
 		switch msg.String() {
 		case "esc":
-			m.filterInput.SetValue("")
+			m.setFilter("")
 			m.filterInput.Blur()
 			m.filterActive = false
 			m.activeFilters = 0
+			m.activeView = ""
 			m.ensureCursorOnVisible()
 			return m, nil
 		case "enter":
@@ -31,11 +34,16 @@ func (m mcModel) handleKey(msg tea.KeyMsg) (mcModel, tea.Cmd) {
 		default:
 			var cmd tea.Cmd
 			m.filterInput, cmd = m.filterInput.Update(msg)
+			m.syncFilter()
 			m.ensureCursorOnVisible()
 			return m, cmd
 		}
 	}
 
+	if m.bulk != nil {
+		return m.handleBulkKey(msg)
+	}
+
 	if m.paletteActive {
 		return m.handlePaletteKey(msg)
 	}
@@ -50,14 +58,12 @@ func (m mcModel) handleKey(msg tea.KeyMsg) (mcModel, tea.Cmd) {
 			repo := row.repo
 			branch := row.wt
 			ws := m.ws
-			windowName := tmuxpkg.WindowName(m.ws.DisplayNameFor(repo), branch)
+			display := m.ws.DisplayNameFor(repo)
 			return m, func() tea.Msg {
-				if tmuxpkg.InTmux() {
-					windows := tmuxpkg.ListWindows()
-					if id, ok := windows[windowName]; ok {
-						_ = tmuxpkg.KillWindow(id)
-					}
+				if err := mcCheckHolders(ws, mcOpenFiles(), repo, branch); err != nil {
+					return mcWorktreeDeletedMsg{rowIdx: idx, repo: repo, branch: branch, err: err}
 				}
+				mux.KillCapsuleWindow(display, branch)
 				err := ws.RemoveWorktree(repo, branch, true)
 				return mcWorktreeDeletedMsg{rowIdx: idx, repo: repo, branch: branch, err: err}
 			}
@@ -68,78 +74,93 @@ func (m mcModel) handleKey(msg tea.KeyMsg) (mcModel, tea.Cmd) {
 		return m, nil
 	}
 
-	switch msg.String() {
-	case "q", "ctrl+c":
+	switch action := m.keymap().action(msg.String()); action {
+	case "":
+		return m, nil
+
+	case "quit":
 		return m, tea.Quit
 
-	case "/":
+	case "filter":
 		m.filterActive = true
 		return m, m.filterInput.Focus()
 
-	case ":":
+	case "palette":
 		m.paletteActive = true
 		m.paletteCursor = 0
 		return m, m.paletteInput.Focus()
 
-	case "1":
+	case "filter-local":
 		m.activeFilters ^= filterLocal
 		m.ensureCursorOnVisible()
-	case "2":
+	case "filter-mine":
 		m.activeFilters ^= filterMine
 		m.ensureCursorOnVisible()
-	case "3":
+	case "filter-review":
 		m.activeFilters ^= filterReviewReq
 		m.ensureCursorOnVisible()
-	case "4":
+	case "filter-dirty":
 		m.activeFilters ^= filterDirty
 		m.ensureCursorOnVisible()
 
-	case "esc":
+	case "mark":
+		return m.toggleMark(), nil
+	case "range":
+		return m.toggleRange(), nil
+
+	case "clear":
+		if m.hasMarks() {
+			return m.clearMarks(), nil
+		}
 		m.activeFilters = 0
-		m.filterInput.SetValue("")
+		m.activeView = ""
+		m.setFilter("")
 		m.filterInput.Blur()
 		m.ensureCursorOnVisible()
 
-	case "j", "down":
+	case "down":
 		m.moveCursor(1)
 		m.ensureCursorVisible()
 
-	case "k", "up":
+	case "up":
 		m.moveCursor(-1)
 		m.ensureCursorVisible()
 
-	case "J", "shift+down":
+	case "scroll-down":
 		m.syncDetailContent()
 		m.detailVP.LineDown(3)
-	case "K", "shift+up":
+	case "scroll-up":
 		m.syncDetailContent()
 		m.detailVP.LineUp(3)
 
-	case "left", "h":
+	case "leave-ground":
 		if m.isOnGround() {
 			m.ensureCursorOnVisible()
 			m.ensureCursorVisible()
 		}
 
-	case "right", "l":
+	case "ground":
 		return m.doSelectGround()
 
-	case "enter":
+	case "go":
 		return m.doGo()
-	case "o":
+	case "open":
 		return m.doOpen()
-	case "b":
+	case "board":
 		return m.doBoardToggle()
-	case "d":
+	case "dock":
 		row := m.rows[m.cursor]
 		if row.kind == rowGhostPR {
 			return m.doCreateWorktree()
 		}
 		return m.doDelete()
-	case "r":
+	case "refresh":
 		return m.doRefresh()
-	case "?":
+	case "help":
 		m.showHelp = !m.showHelp
+
+	default:
+		return m.runPaletteCommand(action)
 	}
 
 	return m, nil
@@ -167,23 +188,15 @@ func (m mcModel) doGo() (mcModel, tea.Cmd) {
 	if path == "" {
 		return m, nil
 	}
-	if tmuxpkg.InTmux() {
+	if mx := mux.Detect(); mx != nil {
 		row := m.rows[m.cursor]
-		name := tmuxpkg.WindowName(m.ws.DisplayNameFor(row.repo), row.wt)
-		windows := tmuxpkg.ListWindows()
-		if id, ok := windows[name]; ok {
-			panes := tmuxpkg.ListPanes(id)
-			if paneID, idle := tmuxpkg.FindIdlePane(panes); idle {
-				_ = tmuxpkg.SelectWindow(id)
-				_ = tmuxpkg.SelectPane(paneID)
-			} else {
-				_ = tmuxpkg.SelectWindow(id)
-				_ = tmuxpkg.SplitWindow(id, path)
-			}
+		name := mux.WindowName(m.ws.DisplayNameFor(row.repo), row.wt)
+		if id, ok := mx.Windows()[name]; ok {
+			_ = mx.Focus(id, path)
 		} else {
-			_ = tmuxpkg.NewWindow(name, path)
+			_ = openCapsuleWindow(mx, m.ws, row.repo, row.wt)
 		}
-		return m, queryTmuxWindows()
+		return m, queryMuxWindows()
 	}
 	m.jumpPath = path
 	return m, tea.Quit
@@ -198,17 +211,15 @@ func (m mcModel) doOpen() (mcModel, tea.Cmd) {
 	if editor == "" {
 		editor = "vim"
 	}
-	if tmuxpkg.InTmux() {
+	if mx := mux.Detect(); mx != nil {
 		row := m.rows[m.cursor]
-		name := tmuxpkg.WindowName(m.ws.DisplayNameFor(row.repo), row.wt)
-		windows := tmuxpkg.ListWindows()
-		if id, ok := windows[name]; ok {
-			_ = tmuxpkg.SelectWindow(id)
-			_ = exec.Command("tmux", "split-window", "-t", id, "-c", path, editor, path).Start()
+		name := mux.WindowName(m.ws.DisplayNameFor(row.repo), row.wt)
+		if id, ok := mx.Windows()[name]; ok {
+			_ = mx.Split(id, path, editor, path)
 		} else {
-			_ = exec.Command("tmux", "new-window", "-n", name, "-c", path, editor, path).Start()
+			_ = mx.Run(name, path, editor, path)
 		}
-		return m, queryTmuxWindows()
+		return m, queryMuxWindows()
 	}
 	c := exec.Command(editor, path)
 	return m, tea.ExecProcess(c, func(err error) tea.Msg {
@@ -216,6 +227,19 @@ func (m mcModel) doOpen() (mcModel, tea.Cmd) {
 	})
 }
 
+// doOpenWindow opens the selected capsule in an editor window of its own.
+func (m mcModel) doOpenWindow() (mcModel, tea.Cmd) {
+	row := m.rows[m.cursor]
+	if row.kind != rowWorktree {
+		return m, nil
+	}
+	e := editors[defaultEditor]
+	if target, err := e.capsuleTarget(m.ws, row.repo, row.wt); err == nil {
+		_ = e.launch(target)
+	}
+	return m, nil
+}
+
 func (m mcModel) doOpenPR() (mcModel, tea.Cmd) {
 	row := m.rows[m.cursor]
 	if row.pr != nil && row.pr.URL != "" {
@@ -281,13 +305,13 @@ func (m mcModel) doCreateWorktree() (mcModel, tea.Cmd) {
 func (m mcModel) doRefresh() (mcModel, tea.Cmd) {
 	capsules := m.ws.FindAllCapsules(90, "")
 
-	if tmuxpkg.InTmux() {
-		windows := tmuxpkg.ListWindows()
+	if mx := mux.Detect(); mx != nil {
+		windows := mx.Windows()
 		for _, c := range capsules {
 			if (c.Merged || c.Inactive) && !c.Dirty {
-				name := tmuxpkg.WindowName(m.ws.DisplayNameFor(c.Repo), c.Name)
+				name := mux.WindowName(m.ws.DisplayNameFor(c.Repo), c.Name)
 				if id, ok := windows[name]; ok {
-					_ = tmuxpkg.KillWindow(id)
+					_ = mx.KillWindow(id)
 				}
 			}
 		}
@@ -311,14 +335,16 @@ func (m mcModel) doRefresh() (mcModel, tea.Cmd) {
 }
 
 func (m mcModel) rebuildModel() (mcModel, tea.Cmd) {
-	m2 := newMCModel(m.ws, m.gh, m.cwd)
+	m2 := newMCModel(m.ws, m.gh, m.cwd, m.mcCfg)
 	m2.width = m.width
 	m2.height = m.height
 	m2.listVP = m.listVP
 	m2.detailVP = m.detailVP
 	m2.filterInput = m.filterInput
+	m2.syncFilter()
 	m2.filterActive = m.filterActive
 	m2.activeFilters = m.activeFilters
+	m2.activeView = m.activeView
 	m2.ghUser = m.ghUser
 	return m2, m2.Init()
 }
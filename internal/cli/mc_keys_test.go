@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/brudil/workspace/internal/config"
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -154,7 +155,7 @@ func TestHandleKey_FilterToggles(t *testing.T) {
 func TestHandleKey_EscClearsFilters(t *testing.T) {
 	m := keysMCModel()
 	m.activeFilters = filterLocal | filterDirty
-	m.filterInput.SetValue("something")
+	m.setFilter("something")
 
 	m, _ = m.handleKey(keyMsg("esc"))
 
@@ -169,7 +170,7 @@ func TestHandleKey_EscClearsFilters(t *testing.T) {
 func TestHandleKey_FilterMode_Esc(t *testing.T) {
 	m := keysMCModel()
 	m.filterActive = true
-	m.filterInput.SetValue("search")
+	m.setFilter("search")
 
 	m, _ = m.handleKey(keyMsg("esc"))
 
@@ -184,7 +185,7 @@ func TestHandleKey_FilterMode_Esc(t *testing.T) {
 func TestHandleKey_FilterMode_Enter(t *testing.T) {
 	m := keysMCModel()
 	m.filterActive = true
-	m.filterInput.SetValue("search")
+	m.setFilter("search")
 
 	m, _ = m.handleKey(keyMsg("enter"))
 
@@ -307,3 +308,57 @@ func TestHandleKey_D_OnGhostPR(t *testing.T) {
 		t.Error("expected a cmd for dock action")
 	}
 }
+
+func TestHandleKey_ViewKeyToggles(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Views: map[string]config.MCView{
+		"feat": {Filter: "branch:feat", Key: "5"},
+	}}
+
+	m, _ = m.handleKey(keyMsg("5"))
+	if m.currentView() != "feat" {
+		t.Errorf("currentView = %q, want %q", m.currentView(), "feat")
+	}
+	if m.filterInput.Value() != "branch:feat" {
+		t.Errorf("filter text = %q, want %q", m.filterInput.Value(), "branch:feat")
+	}
+	if m.isRowVisible(1) || !m.isRowVisible(2) {
+		t.Error("view should show only the feat worktree")
+	}
+	if m.cursor != 2 {
+		t.Errorf("cursor = %d, want 2 (moved onto visible row)", m.cursor)
+	}
+
+	m, _ = m.handleKey(keyMsg("5"))
+	if m.currentView() != "" || m.filterInput.Value() != "" {
+		t.Errorf("second press should clear view, got view=%q filter=%q", m.currentView(), m.filterInput.Value())
+	}
+}
+
+func TestHandleKey_ViewKeyReplacesUnboundPreset(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Views: map[string]config.MCView{
+		"dirty": {Filter: "dirty", Key: "1"},
+	}, Keys: map[string]string{"filter-local": ""}}
+
+	m, _ = m.handleKey(keyMsg("1"))
+	if m.activeFilters&filterLocal != 0 {
+		t.Error("1 should toggle the view once filter-local is unbound")
+	}
+	if m.currentView() != "dirty" {
+		t.Errorf("currentView = %q, want %q", m.currentView(), "dirty")
+	}
+}
+
+func TestCurrentView_ClearedByEditing(t *testing.T) {
+	m := keysMCModel()
+	m.mcCfg = config.MCConfig{Views: map[string]config.MCView{
+		"feat": {Filter: "branch:feat"},
+	}}
+	m = m.applyView("feat")
+	m.setFilter("branch:feat dirty")
+
+	if m.currentView() != "" {
+		t.Errorf("currentView = %q, want empty after editing the filter", m.currentView())
+	}
+}
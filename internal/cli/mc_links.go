@@ -0,0 +1,23 @@
+package cli
+
+import "github.com/brudil/workspace/internal/github"
+
+// applyPRLinks links each row's PR with the PRs in other repos that share
+// its branch name or group. It runs whenever a repo's PRs change, since a
+// new PR in one repo can link rows in every other.
+func (m *mcModel) applyPRLinks() {
+	prsByRepo := make(map[string]map[string]*github.PR, len(m.repos))
+	groups := make(map[string]map[string]string, len(m.repos))
+	for _, repo := range m.repos {
+		prsByRepo[repo.name] = repo.prs
+		groups[repo.name] = repo.prGroups
+	}
+	links := linkPRs(prsByRepo, groups)
+	for i := range m.rows {
+		row := &m.rows[i]
+		row.linked = nil
+		if row.pr != nil {
+			row.linked = links.related(groups, row.repo, row.pr.HeadRefName)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"slices"
+	"time"
 
+	"github.com/brudil/workspace/internal/config"
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -32,6 +34,15 @@ type mcRow struct {
 	isBoarded bool
 	merged    bool
 	live      bool
+
+	lastCommit time.Time
+
+	stack workspace.StackEdge // parent branch, for capsules stacked on another capsule
+	depth int                 // nesting under stack parents in the list
+
+	linked []prLink // PRs in other repos linked with this row's PR
+
+	meta workspace.CapsuleMeta // note and tags recorded with "ws note"
 }
 
 // --- detail tier 2 data ---
@@ -43,6 +54,7 @@ type detailData struct {
 	prTitle    string
 	prBody     string
 	checks     []github.CheckRun
+	issue      *github.Issue // issue the capsule was lifted from
 	loaded     bool
 
 	// Ground-specific
@@ -93,8 +105,11 @@ type mcModel struct {
 
 	showHelp bool
 
+	flash string // error shown in place of the help bar until the next key
+
 	filterInput  textinput.Model
 	filterActive bool
+	filter       parsedFilter // filterInput's expression, parsed by setFilter
 
 	paletteActive bool
 	paletteInput  textinput.Model
@@ -103,6 +118,15 @@ type mcModel struct {
 
 	activeFilters filterFlag
 	ghUser        string
+
+	mcCfg      config.MCConfig
+	keys       mcKeymap
+	activeView string
+
+	marked      map[string]bool // rowKey → marked for a bulk action
+	rangeActive bool            // V range in progress
+	rangeAnchor int             // row index where the V range started
+	bulk        *mcBulk         // bulk action in progress, shown instead of the dashboard
 }
 
 type mcRepoData struct {
@@ -112,21 +136,29 @@ type mcRepoData struct {
 	err       error
 	prs       map[string]*github.PR
 	prsLoaded bool
+	prsAsOf   time.Time                      // set when the PRs came from the cache offline
+	stack     map[string]workspace.StackEdge // branch → stack edge
+	prGroups  map[string]string              // branch → group recorded with "ws pr link"
 }
 
 // --- message types ---
 
 type mcWtStatusMsg struct {
-	repo string
-	wt   workspace.WorktreeStatus
+	repo       string
+	wt         workspace.WorktreeStatus
+	lastCommit time.Time
 }
 
 type mcPRsMsg struct {
 	repo string
 	prs  []github.PR
 	err  error
+	asOf time.Time
 }
 
+// mcPRsBatchMsg carries the PRs of every repo from one batched query.
+type mcPRsBatchMsg []mcPRsMsg
+
 type mcDetailTickMsg struct {
 	seq int
 }
@@ -151,11 +183,19 @@ type mcWorktreeDeletedMsg struct {
 	err    error
 }
 
+// mcCommandDoneMsg reports a custom command having finished, or having been
+// started in its own window.
+type mcCommandDoneMsg struct {
+	label      string
+	err        error
+	foreground bool
+}
+
 type mcGhUserMsg struct {
 	login string
 }
 
-type mcTmuxWindowsMsg struct {
+type mcMuxWindowsMsg struct {
 	windows map[string]string
 }
 
@@ -164,9 +204,14 @@ type mcMergedMsg struct {
 	branches map[string]bool
 }
 
+type mcStackMsg struct {
+	repo  string
+	edges map[string]workspace.StackEdge
+}
+
 // --- constructor ---
 
-func newMCModel(ws *workspace.Workspace, gh github.Client, cwd string) mcModel {
+func newMCModel(ws *workspace.Workspace, gh github.Client, cwd string, mcCfg config.MCConfig) mcModel {
 	outlines := ws.StatusOutline(true)
 	repos := make([]mcRepoData, len(outlines))
 	var rows []mcRow
@@ -186,6 +231,8 @@ func newMCModel(ws *workspace.Workspace, gh github.Client, cwd string) mcModel {
 		if o.Err != nil {
 			continue
 		}
+		repos[i].prGroups = workspace.PRGroups(ws.BareDir(o.Name))
+		metas := ws.CapsuleMetas(o.Name)
 
 		for _, wt := range o.Worktrees {
 			rows = append(rows, mcRow{
@@ -193,6 +240,7 @@ func newMCModel(ws *workspace.Workspace, gh github.Client, cwd string) mcModel {
 				repo:      o.Name,
 				wt:        wt,
 				isBoarded: slices.Contains(o.Boarded, wt),
+				meta:      metas[wt],
 			})
 			wtTotal++
 		}
@@ -241,16 +289,19 @@ func newMCModel(ws *workspace.Workspace, gh github.Client, cwd string) mcModel {
 		actionSpinner: -1,
 		filterInput:   ti,
 		paletteInput:  pi,
+		mcCfg:         mcCfg,
 	}
+	// Invalid keymaps are rejected before mission control starts.
+	m.keys, _ = newMCKeymap(mcCfg)
 
 	// Load cached data for instant first render.
 	// Branches first so that processPRs can match worktrees to PRs.
-	cacheDir := github.CacheDir()
+	cache := github.CacheFor(gh)
 	for _, repo := range repos {
-		if repo.err != nil {
+		if repo.err != nil || cache == nil {
 			continue
 		}
-		if branches := github.ReadBranchCache(cacheDir, ws.Org, repo.name); len(branches) > 0 {
+		if branches := cache.Branches(ws.Org, repo.name); len(branches) > 0 {
 			for i := range m.rows {
 				if m.rows[i].kind == rowWorktree && m.rows[i].repo == repo.name {
 					if b, ok := branches[m.rows[i].wt]; ok {
@@ -259,8 +310,7 @@ func newMCModel(ws *workspace.Workspace, gh github.Client, cwd string) mcModel {
 				}
 			}
 		}
-		prs, _ := github.ReadPRCache(cacheDir, ws.Org, repo.name)
-		if len(prs) > 0 {
+		if prs, _, _ := cache.OpenPRs(ws.Org, repo.name); len(prs) > 0 {
 			m.processPRs(repo.name, prs)
 		}
 	}
@@ -24,14 +24,14 @@ func (m mcModel) isRowVisible(i int) bool {
 		return false
 	}
 
-	// Text filter
-	filter := m.filterInput.Value()
-	if filter != "" {
-		branch := row.branch
-		if branch == "" {
-			branch = row.wt
-		}
-		if !workspace.FuzzyMatch(filter, branch) {
+	// Text filter: a filter expression (see mc_filter.go). Input that doesn't
+	// parse yet falls back to a plain fuzzy match on the branch name.
+	if f := m.filter; f.text != "" {
+		if f.err != nil {
+			if !workspace.FuzzyMatch(f.text, rowBranch(row)) {
+				return false
+			}
+		} else if !f.expr.matches(m, row) {
 			return false
 		}
 	}
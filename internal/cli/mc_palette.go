@@ -57,6 +57,7 @@ const (
 	scopeGhostPR               // row is a ghost PR (no local worktree)
 	scopeHasPR                 // row has a PR (worktree or ghost)
 	scopeRepo                  // row belongs to a specific repo (worktree or ghost)
+	scopeMarked                // one or more rows are marked for a bulk action
 )
 
 // --- command ---
@@ -65,7 +66,7 @@ type paletteCommand struct {
 	name  string // internal identifier (kept for test compat)
 	label string // human-readable display name
 	desc  string // short description
-	key   string // direct keybinding hint (e.g. "⏎", "o")
+	key   string // direct keybinding hint (e.g. "⏎", "o"), filled from the keymap
 	scope paletteScope
 	run   func(m mcModel) (mcModel, tea.Cmd)
 }
@@ -74,47 +75,56 @@ type paletteCommand struct {
 
 func paletteCommands() []paletteCommand {
 	return []paletteCommand{
-		{name: "go", label: "Go", desc: "cd into worktree", key: "⏎", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doGo() }},
-		{name: "open", label: "Open in Editor", desc: "open in $EDITOR", key: "o", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doOpen() }},
-		{name: "github", label: "View on GitHub", desc: "open PR in browser", key: "", scope: scopeHasPR, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doOpenPR() }},
-		{name: "board", label: "Board", desc: "add to IDE workspace", key: "b", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBoardToggle() }},
-		{name: "unboard", label: "Unboard", desc: "remove from IDE workspace", key: "b", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBoardToggle() }},
-		{name: "undock", label: "Undock", desc: "remove worktree", key: "d", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doDelete() }},
-		{name: "dock", label: "Dock", desc: "create worktree from PR", key: "d", scope: scopeGhostPR, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doCreateWorktree() }},
-		{name: "copy-path", label: "Copy Path", desc: "copy worktree path", key: "", scope: scopeWorktree, run: paletteCmdCopyPath},
-		{name: "open-repo", label: "View Repo on GitHub", desc: "open repo in browser", key: "", scope: scopeRepo, run: paletteCmdOpenRepo},
-		{name: "fetch", label: "Fetch", desc: "fetch PR data for repo", key: "", scope: scopeRepo, run: paletteCmdFetch},
-		{name: "filter-local", label: "Filter: Local", desc: "toggle local filter", key: "1", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
+		{name: "go", label: "Go", desc: "cd into worktree", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doGo() }},
+		{name: "open", label: "Open in Editor", desc: "open in $EDITOR", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doOpen() }},
+		{name: "window", label: "Open in Own Window", desc: "open capsule alone in " + defaultEditor, scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doOpenWindow() }},
+		{name: "github", label: "View on GitHub", desc: "open PR in browser", scope: scopeHasPR, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doOpenPR() }},
+		{name: "board", label: "Board", desc: "add to IDE workspace", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBoardToggle() }},
+		{name: "unboard", label: "Unboard", desc: "remove from IDE workspace", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBoardToggle() }},
+		{name: "undock", label: "Undock", desc: "remove worktree", scope: scopeWorktree, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doDelete() }},
+		{name: "dock", label: "Dock", desc: "create worktree from PR", scope: scopeGhostPR, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doCreateWorktree() }},
+		{name: "copy-path", label: "Copy Path", desc: "copy worktree path", scope: scopeWorktree, run: paletteCmdCopyPath},
+		{name: "open-repo", label: "View Repo on GitHub", desc: "open repo in browser", scope: scopeRepo, run: paletteCmdOpenRepo},
+		{name: "fetch", label: "Fetch", desc: "fetch PR data for repo", scope: scopeRepo, run: paletteCmdFetch},
+		{name: "bulk-burn", label: "Burn Marked", desc: "remove marked capsules", scope: scopeMarked, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBulkBurn() }},
+		{name: "bulk-board", label: "Board Marked", desc: "add marked capsules to IDE workspace", scope: scopeMarked, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBulkBoard(true) }},
+		{name: "bulk-unboard", label: "Unboard Marked", desc: "remove marked capsules from IDE workspace", scope: scopeMarked, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBulkBoard(false) }},
+		{name: "bulk-fetch", label: "Fetch Marked", desc: "fetch repos of marked rows", scope: scopeMarked, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBulkFetch() }},
+		{name: "bulk-tmux", label: "Open Marked in Windows", desc: "open a tmux, Zellij or WezTerm window per marked capsule", scope: scopeMarked, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBulkTmux() }},
+		{name: "bulk-silo", label: "Point Silo at Marked", desc: "point each repo's silo at its marked capsule", scope: scopeMarked, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doBulkSilo() }},
+		{name: "filter-local", label: "Filter: Local", desc: "toggle local filter", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
 			m.activeFilters ^= filterLocal
 			m.ensureCursorOnVisible()
 			return m, nil
 		}},
-		{name: "filter-mine", label: "Filter: Mine", desc: "toggle my PRs filter", key: "2", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
+		{name: "filter-mine", label: "Filter: Mine", desc: "toggle my PRs filter", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
 			m.activeFilters ^= filterMine
 			m.ensureCursorOnVisible()
 			return m, nil
 		}},
-		{name: "filter-review", label: "Filter: Review Requested", desc: "toggle review filter", key: "3", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
+		{name: "filter-review", label: "Filter: Review Requested", desc: "toggle review filter", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
 			m.activeFilters ^= filterReviewReq
 			m.ensureCursorOnVisible()
 			return m, nil
 		}},
-		{name: "filter-dirty", label: "Filter: Dirty", desc: "toggle dirty filter", key: "4", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
+		{name: "filter-dirty", label: "Filter: Dirty", desc: "toggle dirty filter", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) {
 			m.activeFilters ^= filterDirty
 			m.ensureCursorOnVisible()
 			return m, nil
 		}},
-		{name: "refresh", label: "Refresh", desc: "refresh all data", key: "r", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doRefresh() }},
-		{name: "debrief", label: "Debrief", desc: "clean up merged branches", key: "", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doRefresh() }},
-		{name: "fetch-all", label: "Fetch All", desc: "fetch all repos", key: "", scope: scopeAlways, run: paletteCmdFetchAll},
+		{name: "refresh", label: "Refresh", desc: "refresh all data", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doRefresh() }},
+		{name: "debrief", label: "Debrief", desc: "clean up merged branches", scope: scopeAlways, run: func(m mcModel) (mcModel, tea.Cmd) { return m.doRefresh() }},
+		{name: "fetch-all", label: "Fetch All", desc: "fetch all repos", scope: scopeAlways, run: paletteCmdFetchAll},
 	}
 }
 
 // --- filtering ---
 
 func (m mcModel) availableCommands() []paletteCommand {
-	all := paletteCommands()
+	all := append(paletteCommands(), m.viewCommands()...)
+	all = append(all, m.customCommands()...)
 	filter := m.paletteInput.Value()
+	km := m.keymap()
 
 	var row mcRow
 	if m.cursor >= 0 && m.cursor < len(m.rows) {
@@ -127,25 +137,10 @@ func (m mcModel) availableCommands() []paletteCommand {
 		if filter != "" && !workspace.FuzzyMatch(filter, cmd.label) {
 			continue
 		}
-		// scope filter
-		switch cmd.scope {
-		case scopeWorktree:
-			if row.kind != rowWorktree {
-				continue
-			}
-		case scopeGhostPR:
-			if row.kind != rowGhostPR {
-				continue
-			}
-		case scopeHasPR:
-			if row.pr == nil {
-				continue
-			}
-		case scopeRepo:
-			if row.kind == rowRepoHeader {
-				continue
-			}
+		if !m.scopeApplies(cmd.scope) {
+			continue
 		}
+		cmd.key = km.hint(cmd.name)
 		// board/unboard visibility
 		if cmd.name == "board" && row.isBoarded {
 			continue
@@ -155,18 +150,57 @@ func (m mcModel) availableCommands() []paletteCommand {
 		}
 		out = append(out, cmd)
 	}
-	// Smart sort: context-relevant commands first, then global.
+	// Smart sort: bulk actions first, then context-relevant commands, then global.
 	sort.SliceStable(out, func(i, j int) bool {
-		iContext := out[i].scope != scopeAlways
-		jContext := out[j].scope != scopeAlways
-		if iContext != jContext {
-			return iContext
-		}
-		return false
+		return scopeRank(out[i].scope) < scopeRank(out[j].scope)
 	})
 	return out
 }
 
+// scopeApplies reports whether a command with the given scope can run
+// against the selected row.
+func (m mcModel) scopeApplies(scope paletteScope) bool {
+	var row mcRow
+	if m.cursor >= 0 && m.cursor < len(m.rows) {
+		row = m.rows[m.cursor]
+	}
+	switch scope {
+	case scopeWorktree:
+		return row.kind == rowWorktree
+	case scopeGhostPR:
+		return row.kind == rowGhostPR
+	case scopeHasPR:
+		return row.pr != nil
+	case scopeRepo:
+		return row.kind != rowRepoHeader
+	case scopeMarked:
+		return m.hasMarks()
+	}
+	return true
+}
+
+func scopeRank(s paletteScope) int {
+	switch s {
+	case scopeMarked:
+		return 0
+	case scopeAlways:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// runPaletteCommand runs the named palette command if it applies to the
+// selected row. Keys bound to palette commands in [mc.keys] land here.
+func (m mcModel) runPaletteCommand(name string) (mcModel, tea.Cmd) {
+	for _, cmd := range m.availableCommands() {
+		if cmd.name == name {
+			return cmd.run(m)
+		}
+	}
+	return m, nil
+}
+
 // --- key handling ---
 
 func (m mcModel) handlePaletteKey(msg tea.KeyMsg) (mcModel, tea.Cmd) {
@@ -235,6 +269,8 @@ func scopeLabel(s paletteScope) string {
 		return "pr"
 	case scopeRepo:
 		return "repo"
+	case scopeMarked:
+		return "marked"
 	default:
 		return ""
 	}
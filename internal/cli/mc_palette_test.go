@@ -4,6 +4,7 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/brudil/workspace/internal/config"
 	"github.com/brudil/workspace/internal/github"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -199,3 +200,20 @@ func cmdNames(cmds []paletteCommand) []string {
 func contains(ss []string, s string) bool {
 	return slices.Contains(ss, s)
 }
+
+func TestAvailableCommands_Views(t *testing.T) {
+	m := mcModel{
+		cursor: 0,
+		rows: []mcRow{
+			{kind: rowWorktree, repo: "r", wt: "feat"},
+		},
+		mcCfg: config.MCConfig{Views: map[string]config.MCView{
+			"failing": {Filter: "pr:failing", Key: "5"},
+		}},
+	}
+
+	names := cmdNames(m.availableCommands())
+	if !contains(names, "view:failing") {
+		t.Error("expected 'view:failing' for a saved view")
+	}
+}
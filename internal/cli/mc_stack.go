@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+// --- stacked branches ---
+//
+// Capsules cut from another capsule's branch are listed under it as a tree.
+// Each row keeps the edge to its parent so the list can show how far it has
+// drifted and flag children that need a restack.
+
+// applyStack records each capsule's stack edge and reorders the repo's
+// capsules so children follow their parent.
+func (m *mcModel) applyStack(repoName string, edges map[string]workspace.StackEdge) {
+	for i := range m.repos {
+		if m.repos[i].name == repoName {
+			m.repos[i].stack = edges
+		}
+	}
+	if edges == nil {
+		return
+	}
+
+	start, end := -1, len(m.rows)
+	for i, row := range m.rows {
+		if row.kind == rowRepoHeader {
+			if start >= 0 {
+				end = i
+				break
+			}
+			if row.repo == repoName {
+				start = i + 1
+			}
+		}
+	}
+	if start < 0 {
+		return
+	}
+
+	var ground, capsules, ghosts []int
+	for i := start; i < end; i++ {
+		row := &m.rows[i]
+		switch {
+		case row.kind == rowGhostPR:
+			ghosts = append(ghosts, i)
+		case row.wt == workspace.GroundDir:
+			ground = append(ground, i)
+		default:
+			row.stack = edges[row.branch]
+			capsules = append(capsules, i)
+		}
+	}
+	slices.SortStableFunc(capsules, func(a, b int) int {
+		return cmp.Compare(m.rows[a].wt, m.rows[b].wt)
+	})
+
+	// Only parents that are capsules in this repo nest their children.
+	byBranch := make(map[string]int)
+	for _, i := range capsules {
+		if b := m.rows[i].branch; b != "" {
+			byBranch[b] = i
+		}
+	}
+	children := make(map[int][]int)
+	var roots []int
+	for _, i := range capsules {
+		parent, ok := byBranch[m.rows[i].stack.Parent]
+		if ok && m.rows[i].stack.Stacked(m.ws.DefaultBranch) && parent != i {
+			children[parent] = append(children[parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	order := append([]int(nil), ground...)
+	depths := make(map[int]int)
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		order = append(order, i)
+		depths[i] = depth
+		for _, c := range children[i] {
+			walk(c, depth+1)
+		}
+	}
+	for _, i := range roots {
+		walk(i, 0)
+	}
+	order = append(order, ghosts...)
+
+	block := make([]mcRow, len(order))
+	moved := make(map[int]int, len(order))
+	for j, i := range order {
+		block[j] = m.rows[i]
+		block[j].depth = depths[i]
+		moved[i] = start + j
+	}
+	copy(m.rows[start:end], block)
+
+	for _, idx := range []*int{&m.cursor, &m.confirmIdx, &m.actionSpinner, &m.detailFor} {
+		if to, ok := moved[*idx]; ok {
+			*idx = to
+		}
+	}
+	if m.rangeActive {
+		if to, ok := moved[m.rangeAnchor]; ok {
+			m.rangeAnchor = to
+		}
+	}
+}
+
+// restacks counts the capsules whose stack parent has moved on.
+func (m mcModel) restacks() int {
+	n := 0
+	for _, row := range m.rows {
+		if row.kind == rowWorktree && row.stack.NeedsRestack(m.ws.DefaultBranch) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+func TestApplyStack_NestsChildrenUnderParents(t *testing.T) {
+	m := goldenMCModel()
+	m.cursor = 3 // fix-styles
+
+	m.applyStack("frontend", map[string]workspace.StackEdge{
+		"feat-auth":  {Parent: "main", Ahead: 2},
+		"fix-styles": {Parent: "redesign", Ahead: 1},
+		"redesign":   {Parent: "feat-auth", Ahead: 3, Behind: 1},
+	})
+
+	var got []string
+	var depths []int
+	for _, row := range m.rows[1:5] {
+		got = append(got, row.wt)
+		depths = append(depths, row.depth)
+	}
+	want := []string{".ground", "feat-auth", "redesign", "fix-styles"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+	if depths[1] != 0 || depths[2] != 1 || depths[3] != 2 {
+		t.Errorf("depths = %v, want [0 0 1 2]", depths)
+	}
+	if m.rows[m.cursor].wt != "fix-styles" {
+		t.Errorf("cursor on %q, want it to follow fix-styles", m.rows[m.cursor].wt)
+	}
+	if m.restacks() != 1 {
+		t.Errorf("restacks = %d, want 1", m.restacks())
+	}
+	// Other repos are untouched.
+	if m.rows[7].wt != "add-api" || m.rows[7].depth != 0 {
+		t.Errorf("backend rows changed: %+v", m.rows[7])
+	}
+}
+
+func TestApplyStack_UnstackedRowsKeepOrder(t *testing.T) {
+	m := goldenMCModel()
+	m.applyStack("frontend", map[string]workspace.StackEdge{
+		"redesign": {Parent: "main", Ahead: 1},
+	})
+	want := []string{".ground", "feat-auth", "fix-styles", "redesign"}
+	for i, wt := range want {
+		if m.rows[i+1].wt != wt || m.rows[i+1].depth != 0 {
+			t.Errorf("rows[%d] = %q depth %d, want %q depth 0", i+1, m.rows[i+1].wt, m.rows[i+1].depth, wt)
+		}
+	}
+}
+
+func TestFilterExpr_Stacked(t *testing.T) {
+	m := goldenMCModel()
+	m.applyStack("frontend", map[string]workspace.StackEdge{
+		"fix-styles": {Parent: "redesign", Ahead: 1},
+		"redesign":   {Parent: "feat-auth", Ahead: 3, Behind: 1},
+	})
+	m.setFilter("restack")
+
+	var visible []string
+	for i, row := range m.rows {
+		if row.kind == rowWorktree && m.isRowVisible(i) {
+			visible = append(visible, row.wt)
+		}
+	}
+	if len(visible) != 1 || visible[0] != "redesign" {
+		t.Errorf("visible = %v, want [redesign]", visible)
+	}
+}
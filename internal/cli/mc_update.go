@@ -1,22 +1,22 @@
 package cli
 
 import (
+	"fmt"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/brudil/workspace/internal/github"
-	tmuxpkg "github.com/brudil/workspace/internal/tmux"
+	"github.com/brudil/workspace/internal/mux"
 	"github.com/brudil/workspace/internal/workspace"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	gh "github.com/cli/go-gh/v2"
 )
 
 // --- Init ---
 
 func (m mcModel) Init() tea.Cmd {
 	var cmds []tea.Cmd
+	var repos []string
 	for _, repo := range m.repos {
 		if repo.err != nil {
 			continue
@@ -24,14 +24,18 @@ func (m mcModel) Init() tea.Cmd {
 		for _, wt := range repo.worktrees {
 			cmds = append(cmds, m.queryWorktree(repo.name, wt))
 		}
-		cmds = append(cmds, m.queryRepoPRs(repo.name))
 		cmds = append(cmds, m.queryMergedBranches(repo.name))
+		cmds = append(cmds, m.queryStack(repo.name))
+		repos = append(repos, repo.name)
+	}
+	if len(repos) > 0 {
+		cmds = append(cmds, m.queryPRs(repos))
 	}
 	cmds = append(cmds, m.scheduleDetailFetch())
-	cmds = append(cmds, fetchGhUser())
+	cmds = append(cmds, fetchGhUser(m.gh))
 	cmds = append(cmds, tea.SetWindowTitle("Mission Control"))
-	if tmuxpkg.InTmux() {
-		cmds = append(cmds, queryTmuxWindows())
+	if mux.Detect() != nil {
+		cmds = append(cmds, queryMuxWindows())
 	}
 	return tea.Batch(cmds...)
 }
@@ -41,22 +45,54 @@ func (m mcModel) queryWorktree(repo, wt string) tea.Cmd {
 	wtPath := filepath.Join(repoDir, wt)
 	return func() tea.Msg {
 		status := workspace.QueryWorktreeStatus(wtPath)
-		return mcWtStatusMsg{repo: repo, wt: status}
+		return mcWtStatusMsg{repo: repo, wt: status, lastCommit: workspace.GitLastCommitDate(wtPath)}
 	}
 }
 
 func (m mcModel) queryRepoPRs(repoName string) tea.Cmd {
-	org := m.ws.Org
+	ws := m.ws
 	gh := m.gh
 	return func() tea.Msg {
-		prs, err := gh.PRsForRepo(org, repoName)
+		if github.IsOffline(gh) {
+			// Nothing to refresh from; show what the cache has.
+			r := gh.PRsForRepos(ws.Org, []string{repoName})[repoName]
+			prs := r.Open
+			if r.Err == nil {
+				prs = withRecordedPRs(ws, gh, repoName, prs)
+			}
+			return mcPRsMsg{repo: repoName, prs: prs, err: r.Err, asOf: r.AsOf}
+		}
+		// A refresh is asked for; skip the cached lists.
+		if cache := github.CacheFor(gh); cache != nil {
+			cache.Expire(ws.Org, repoName)
+		}
+		prs, err := gh.PRsForRepo(ws.Org, repoName)
 		if err == nil {
-			github.WritePRCache(github.CacheDir(), org, repoName, prs)
+			prs = withRecordedPRs(ws, gh, repoName, prs)
 		}
 		return mcPRsMsg{repo: repoName, prs: prs, err: err}
 	}
 }
 
+// queryPRs fetches the PRs of several repos with one batched query.
+func (m mcModel) queryPRs(repos []string) tea.Cmd {
+	ws := m.ws
+	gh := m.gh
+	return func() tea.Msg {
+		results := gh.PRsForRepos(ws.Org, repos)
+		batch := make(mcPRsBatchMsg, len(repos))
+		for i, repoName := range repos {
+			r := results[repoName]
+			prs := r.Open
+			if r.Err == nil {
+				prs = withRecordedPRs(ws, gh, repoName, prs)
+			}
+			batch[i] = mcPRsMsg{repo: repoName, prs: prs, err: r.Err, asOf: r.AsOf}
+		}
+		return batch
+	}
+}
+
 func (m mcModel) queryMergedBranches(repoName string) tea.Cmd {
 	bareDir := m.ws.BareDir(repoName)
 	defaultBranch := m.ws.DefaultBranch
@@ -70,21 +106,32 @@ func (m mcModel) queryMergedBranches(repoName string) tea.Cmd {
 	}
 }
 
-func fetchGhUser() tea.Cmd {
-	return func() tea.Msg {
-		cacheDir := github.CacheDir()
-		if login := github.ReadUserCache(cacheDir); login != "" {
-			return mcGhUserMsg{login: login}
-		}
-		stdOut, _, err := gh.Exec("api", "user", "-q", ".login")
-		if err != nil {
-			return mcGhUserMsg{}
+// queryStack works out which capsules in a repo are stacked on others.
+func (m mcModel) queryStack(repoName string) tea.Cmd {
+	repoDir := m.ws.RepoDir(repoName)
+	bareDir := m.ws.BareDir(repoName)
+	defaultBranch := m.ws.DefaultBranch
+	var worktrees []string
+	for _, repo := range m.repos {
+		if repo.name == repoName {
+			worktrees = repo.worktrees
 		}
-		login := strings.TrimSpace(stdOut.String())
-		if login != "" {
-			github.WriteUserCache(cacheDir, login)
+	}
+	return func() tea.Msg {
+		var branches []string
+		for _, wt := range worktrees {
+			if wt == workspace.GroundDir {
+				continue
+			}
+			branches = append(branches, workspace.GitCurrentBranch(filepath.Join(repoDir, wt)))
 		}
-		return mcGhUserMsg{login: login}
+		return mcStackMsg{repo: repoName, edges: workspace.GitStackParents(bareDir, defaultBranch, branches)}
+	}
+}
+
+func fetchGhUser(client github.Client) tea.Cmd {
+	return func() tea.Msg {
+		return mcGhUserMsg{login: github.Login(client)}
 	}
 }
 
@@ -131,12 +178,19 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 				m.rows[i].dirty = msg.wt.Dirty
 				m.rows[i].ahead = msg.wt.Ahead
 				m.rows[i].behind = msg.wt.Behind
+				m.rows[i].lastCommit = msg.lastCommit
 				m.rows[i].loaded = true
 				m.wtDone++
 				m.matchWorktreePR(i)
 				break
 			}
 		}
+		// Place the capsule in its stack now that its branch is known.
+		for _, repo := range m.repos {
+			if repo.name == msg.repo && repo.stack != nil {
+				m.applyStack(repo.name, repo.stack)
+			}
+		}
 		// Update branch cache so next launch can match PRs immediately.
 		branches := make(map[string]string)
 		for _, row := range m.rows {
@@ -144,8 +198,8 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 				branches[row.wt] = row.branch
 			}
 		}
-		if len(branches) > 0 {
-			github.WriteBranchCache(github.CacheDir(), m.ws.Org, msg.repo, branches)
+		if cache := github.CacheFor(m.gh); cache != nil && len(branches) > 0 {
+			cache.SetBranches(m.ws.Org, msg.repo, branches)
 		}
 		if m.activeFilters != 0 {
 			m.ensureCursorOnVisible()
@@ -153,21 +207,12 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 		return m, nil
 
 	case mcPRsMsg:
-		m.prDone++
-		for i := range m.repos {
-			if m.repos[i].name != msg.repo {
-				continue
-			}
-			if msg.err != nil {
-				m.prErrors++
-				m.repos[i].prsLoaded = true
-				break
-			}
-			cursorRepo, cursorBranch := m.clearRepoPRs(msg.repo)
-			m.processPRs(msg.repo, msg.prs)
-			m.repos[i].prsLoaded = true
-			m.restoreCursor(cursorRepo, cursorBranch)
-			break
+		m.applyRepoPRs(msg)
+		return m, nil
+
+	case mcPRsBatchMsg:
+		for _, r := range msg {
+			m.applyRepoPRs(r)
 		}
 		return m, nil
 
@@ -193,6 +238,10 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case mcStackMsg:
+		m.applyStack(msg.repo, msg.edges)
+		return m, nil
+
 	case mcGhUserMsg:
 		m.ghUser = msg.login
 		if m.activeFilters&filterMine != 0 {
@@ -200,12 +249,12 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 		}
 		return m, nil
 
-	case mcTmuxWindowsMsg:
+	case mcMuxWindowsMsg:
 		for i := range m.rows {
 			if m.rows[i].kind == rowRepoHeader {
 				continue
 			}
-			name := tmuxpkg.WindowName(m.ws.DisplayNameFor(m.rows[i].repo), m.rows[i].wt)
+			name := mux.WindowName(m.ws.DisplayNameFor(m.rows[i].repo), m.rows[i].wt)
 			m.rows[i].live = msg.windows[name] != ""
 		}
 		return m, nil
@@ -227,6 +276,7 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 	case mcWorktreeDeletedMsg:
 		m.actionSpinner = -1
 		if msg.err != nil {
+			m.flash = fmt.Sprintf("burn %s %s: %v", m.ws.DisplayNameFor(msg.repo), msg.branch, msg.err)
 			return m, nil
 		}
 		for i := range m.rows {
@@ -242,6 +292,12 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case mcCommandDoneMsg:
+		return m.handleCommandDone(msg)
+
+	case mcBulkStepMsg:
+		return m.handleBulkStep(msg)
+
 	case mcFetchMsg:
 		if msg.err != nil {
 			return m, nil
@@ -256,6 +312,7 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 			}
 			cmds = append(cmds, m.queryRepoPRs(repo.name))
 			cmds = append(cmds, m.queryMergedBranches(repo.name))
+			cmds = append(cmds, m.queryStack(repo.name))
 			break
 		}
 		return m, tea.Batch(cmds...)
@@ -296,6 +353,7 @@ func (m mcModel) handleMsg(msg tea.Msg) (mcModel, tea.Cmd) {
 	if m.filterActive {
 		var cmd tea.Cmd
 		m.filterInput, cmd = m.filterInput.Update(msg)
+		m.syncFilter()
 		return m, cmd
 	}
 	if m.paletteActive {
@@ -399,6 +457,7 @@ func (m *mcModel) processPRs(repoName string, prs []github.PR) {
 	if len(ghosts) > 0 {
 		m.insertGhostRows(repoName, ghosts)
 	}
+	m.applyPRLinks()
 }
 
 // matchWorktreePR links a worktree row to a PR once its branch is known,
@@ -433,6 +492,7 @@ func (m *mcModel) matchWorktreePR(rowIdx int) {
 			break
 		}
 	}
+	m.applyPRLinks()
 }
 
 func (m *mcModel) insertGhostRows(repo string, ghosts []mcRow) {
@@ -458,9 +518,13 @@ func (m *mcModel) insertGhostRows(repo string, ghosts []mcRow) {
 	}
 }
 
-func queryTmuxWindows() tea.Cmd {
+func queryMuxWindows() tea.Cmd {
 	return func() tea.Msg {
-		return mcTmuxWindowsMsg{windows: tmuxpkg.ListWindows()}
+		mx := mux.Detect()
+		if mx == nil {
+			return mcMuxWindowsMsg{}
+		}
+		return mcMuxWindowsMsg{windows: mx.Windows()}
 	}
 }
 
@@ -496,6 +560,16 @@ func (m mcModel) fetchDetail(rowIdx int) tea.Cmd {
 			d.commits = workspace.GitRecentCommits(wtPath, 4, ws.DefaultBranch)
 			d.diffStat = workspace.GitDiffStat(wtPath)
 			d.stashCount = workspace.GitStashCount(wtPath)
+
+			branch := row.branch
+			if branch == "" {
+				branch = workspace.GitCurrentBranch(wtPath)
+			}
+			if ref, ok := workspace.CapsuleIssues(ws.BareDir(row.repo))[branch]; ok {
+				if issue, err := gh.IssueFromNumber(ref.Org, ref.Repo, ref.Number); err == nil {
+					d.issue = issue
+				}
+			}
 		}
 		if row.pr != nil {
 			pr, err := gh.PRDetail(ws.Org, row.repo, row.pr.Number)
@@ -515,3 +589,24 @@ func (m mcModel) fetchDetail(rowIdx int) tea.Cmd {
 		return mcDetailDataMsg{rowIdx: rowIdx, data: d}
 	}
 }
+
+// applyRepoPRs replaces one repo's PRs, keeping the cursor on its row.
+func (m *mcModel) applyRepoPRs(msg mcPRsMsg) {
+	m.prDone++
+	for i := range m.repos {
+		if m.repos[i].name != msg.repo {
+			continue
+		}
+		if msg.err != nil {
+			m.prErrors++
+			m.repos[i].prsLoaded = true
+			return
+		}
+		cursorRepo, cursorBranch := m.clearRepoPRs(msg.repo)
+		m.processPRs(msg.repo, msg.prs)
+		m.repos[i].prsLoaded = true
+		m.repos[i].prsAsOf = msg.asOf
+		m.restoreCursor(cursorRepo, cursorBranch)
+		return
+	}
+}
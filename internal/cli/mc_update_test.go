@@ -1,7 +1,11 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/brudil/workspace/internal/github"
@@ -301,6 +305,43 @@ func TestMCUpdate_WorktreeDeletedMsg(t *testing.T) {
 	}
 }
 
+func TestMCUpdate_WorktreeDeletedMsg_ErrorFlashes(t *testing.T) {
+	m := baseMCModel()
+	origRowCount := len(m.rows)
+
+	result, _ := m.Update(mcWorktreeDeletedMsg{rowIdx: 2, repo: "repo1", branch: "feat", err: errors.New("holding files open: node (pid 7)")})
+	rm := result.(mcModel)
+
+	if len(rm.rows) != origRowCount {
+		t.Errorf("row count = %d, want the row kept", len(rm.rows))
+	}
+	if !strings.Contains(rm.flash, "holding files open") {
+		t.Errorf("flash = %q, want the error", rm.flash)
+	}
+}
+
+func TestMCUpdate_RefreshKeepsFilter(t *testing.T) {
+	m := baseMCModel()
+	m.ws.Root = t.TempDir()
+	m.ws.RepoNames = []string{"repo1"}
+	for _, capsule := range []string{"feat", "other"} {
+		os.MkdirAll(filepath.Join(m.ws.RepoDir("repo1"), capsule), 0o755)
+	}
+	m.setFilter("feat")
+
+	m, _ = m.handleKey(keyMsg("r"))
+
+	var visible []string
+	for i, row := range m.rows {
+		if m.isRowVisible(i) {
+			visible = append(visible, row.wt)
+		}
+	}
+	if len(visible) != 1 || visible[0] != "feat" {
+		t.Errorf("visible after refresh = %v, want only feat", visible)
+	}
+}
+
 func TestMCUpdate_WindowSizeMsg(t *testing.T) {
 	m := baseMCModel()
 
@@ -433,7 +474,7 @@ func TestMCUpdate_TmuxWindowsMsg(t *testing.T) {
 	m.ws.DisplayNames = map[string]string{"repo1": "Repo One"}
 	m.rows[2].wt = "feat"
 
-	msg := mcTmuxWindowsMsg{
+	msg := mcMuxWindowsMsg{
 		windows: map[string]string{
 			"Repo One:feat": "@1",
 		},
@@ -454,7 +495,7 @@ func TestMCUpdate_TmuxWindowsMsg_NoDisplayName(t *testing.T) {
 	m := baseMCModel()
 	m.rows[2].wt = "feat"
 
-	msg := mcTmuxWindowsMsg{
+	msg := mcMuxWindowsMsg{
 		windows: map[string]string{
 			"repo1:feat": "@1",
 		},
@@ -38,6 +38,17 @@ func (m mcModel) renderHeader() string {
 	if m.activeFilters&filterDirty != 0 {
 		tags = append(tags, tagStyle.Render(" dirty "))
 	}
+	if view := m.currentView(); view != "" {
+		tags = append(tags, tagStyle.Render(" "+view+" "))
+	}
+	if m.hasMarks() {
+		markStyle := tagStyle.Foreground(lipgloss.Color("208"))
+		tags = append(tags, markStyle.Render(fmt.Sprintf(" %d marked ", len(m.markedRows()))))
+	}
+	if n := m.restacks(); n > 0 {
+		restackStyle := tagStyle.Foreground(lipgloss.Color("208"))
+		tags = append(tags, restackStyle.Render(fmt.Sprintf(" %d to restack ", n)))
+	}
 	tagStr := ""
 	if len(tags) > 0 {
 		tagStr = " " + strings.Join(tags, " ")
@@ -54,8 +65,12 @@ func (m mcModel) renderHeader() string {
 	var right string
 	if m.filterActive {
 		right = ui.Dim.Render("/") + " " + m.filterInput.View()
-	} else if m.filterInput.Value() != "" {
-		right = ui.Dim.Render("/ " + m.filterInput.Value())
+	} else if filter := m.filterInput.Value(); filter != "" {
+		if m.filter.err != nil {
+			right = ui.Dim.Render("/ ") + ui.Red.Render(filter)
+		} else {
+			right = ui.Dim.Render("/ " + filter)
+		}
 	} else {
 		right = ui.Dim.Render("/ to filter")
 	}
@@ -84,6 +99,10 @@ func (m mcModel) View() string {
 		return m.renderHelpOverlay()
 	}
 
+	if m.bulk != nil {
+		return m.renderBulk()
+	}
+
 	header := m.renderHeader()
 
 	listWidth := m.width * 2 / 5
@@ -189,6 +208,9 @@ func (m mcModel) renderList(width int) string {
 		if _, ok := m.ws.DisplayNames[g.name]; ok {
 			header += " " + ui.Dim.Render("("+g.name+")")
 		}
+		if i := slices.IndexFunc(m.repos, func(r mcRepoData) bool { return r.name == g.name }); i >= 0 && !m.repos[i].prsAsOf.IsZero() {
+			header += " " + ui.Dim.Render("PRs as of "+timeAgo(m.repos[i].prsAsOf))
+		}
 
 		var groundLabel string
 		if g.ground != nil {
@@ -233,6 +255,16 @@ func (m mcModel) renderList(width int) string {
 func (m mcModel) renderRow(row mcRow, selected bool, globalIdx int, availWidth int) string {
 	highlightStyle := lipgloss.NewStyle().Background(lipgloss.Color("236"))
 
+	// While anything is marked, rows get a mark column.
+	var mark string
+	if m.hasMarks() {
+		mark = "  "
+		if m.isMarked(globalIdx) {
+			mark = ui.Orange.Render("✓") + " "
+		}
+		availWidth -= 2
+	}
+
 	var line string
 	switch row.kind {
 	case rowWorktree:
@@ -240,6 +272,7 @@ func (m mcModel) renderRow(row mcRow, selected bool, globalIdx int, availWidth i
 	case rowGhostPR:
 		line = m.renderGhostRow(row, availWidth)
 	}
+	line = mark + line
 
 	if m.actionSpinner == globalIdx {
 		line += "  " + ui.Orange.Render("⟳")
@@ -263,6 +296,9 @@ func (m mcModel) renderWorktreeRow(row mcRow, availWidth int) string {
 	} else {
 		prefix = "  "
 	}
+	if row.depth > 0 {
+		prefix += ui.Dim.Render(strings.Repeat("  ", row.depth-1) + "└ ")
+	}
 
 	// Name part (truncatable)
 	var name string
@@ -291,8 +327,19 @@ func (m mcModel) renderWorktreeRow(row mcRow, availWidth int) string {
 		suffix.WriteString(ui.TagGreen.Render("landed") + " ")
 	}
 
+	if row.stack.Stacked(m.ws.DefaultBranch) {
+		suffix.WriteString(ui.Dim.Render(formatStackEdge(row.stack)) + " ")
+		if row.stack.NeedsRestack(m.ws.DefaultBranch) {
+			suffix.WriteString(ui.TagOrange.Render("restack") + " ")
+		}
+	}
+
 	if row.pr != nil {
-		suffix.WriteString(formatPRInfo(row.pr))
+		suffix.WriteString(formatPRInfo(row.pr) + " ")
+	}
+
+	if len(row.linked) > 0 {
+		suffix.WriteString(ui.Dim.Render(fmt.Sprintf("⛓%d", len(row.linked))))
 	}
 
 	suffixStr := strings.TrimRight(suffix.String(), " ")
@@ -325,6 +372,9 @@ func (m mcModel) renderGhostRow(row mcRow, availWidth int) string {
 	if row.pr != nil {
 		suffixStr = formatPRInfo(row.pr)
 	}
+	if len(row.linked) > 0 {
+		suffixStr += " " + ui.Dim.Render(fmt.Sprintf("⛓%d", len(row.linked)))
+	}
 	suffixWidth := lipgloss.Width(suffixStr)
 
 	gap := 2
@@ -337,6 +387,38 @@ func (m mcModel) renderGhostRow(row mcRow, availWidth int) string {
 	return prefix + ui.Dim.Render(name)
 }
 
+// renderLinkedPRs lists the PRs in other repos linked with the row's PR.
+func (m mcModel) renderLinkedPRs(row mcRow, indent string, width int) string {
+	var b strings.Builder
+	for _, l := range row.linked {
+		num := fmt.Sprintf("#%d", l.pr.Number)
+		if l.pr.URL != "" {
+			num = ui.Hyperlink(l.pr.URL, num)
+		}
+		repoLabel := lipgloss.NewStyle().Foreground(m.repoColorFor(l.repo)).Render(m.ws.DisplayNameFor(l.repo))
+		line := ui.Dim.Render("⛓ ") + repoLabel + " " + ui.Dim.Render(num)
+		if parts := renderPRStatusParts(l.pr); len(parts) > 0 {
+			line += " " + strings.Join(parts, " ")
+		}
+		if l.pr.Title != "" {
+			budget := max(width-4-lipgloss.Width(line)-2, 4)
+			line += "  " + truncate.StringWithTail(l.pr.Title, uint(budget), "…")
+		}
+		b.WriteString(indent + line + "\n")
+	}
+	return b.String()
+}
+
+// formatStackEdge renders a capsule's commits ahead of and behind its
+// stack parent.
+func formatStackEdge(e workspace.StackEdge) string {
+	s := fmt.Sprintf("↑%d", e.Ahead)
+	if e.Behind > 0 {
+		s += fmt.Sprintf(" ↓%d", e.Behind)
+	}
+	return s
+}
+
 func uintPtr(v uint) *uint { return &v }
 
 func renderPRStatusParts(pr *github.PR) []string {
@@ -385,6 +467,16 @@ func (m mcModel) renderCapsuleStatusBlock(row mcRow, indent string, width int) s
 
 	b.WriteString("\n")
 
+	if row.stack.Stacked(m.ws.DefaultBranch) {
+		line := ui.Dim.Render("on ") + row.stack.Parent + "  " + ui.Dim.Render(formatStackEdge(row.stack))
+		if row.stack.NeedsRestack(m.ws.DefaultBranch) {
+			line += "  " + ui.Orange.Render("parent has moved on, run ws restack")
+		}
+		b.WriteString(indent + line + "\n")
+	}
+
+	b.WriteString(m.renderLinkedPRs(row, indent, width))
+
 	if row.loaded {
 		var gitParts []string
 		if row.ahead > 0 {
@@ -438,6 +530,7 @@ func (m mcModel) renderGhostStatusBlock(row mcRow, indent string, width int) str
 	}
 
 	b.WriteString("\n")
+	b.WriteString(m.renderLinkedPRs(row, indent, width))
 
 	if row.pr != nil {
 		if prParts := renderPRStatusParts(row.pr); len(prParts) > 0 {
@@ -542,6 +635,28 @@ func (m mcModel) renderDetailTier2(indent string, width int) string {
 	contentWidth := max(width-len(indent), 20)
 	wrapStyle := lipgloss.NewStyle().Width(contentWidth)
 
+	if meta := row.meta; !meta.IsZero() {
+		b.WriteString(indent + lipgloss.NewStyle().Bold(true).Render("Note") + "\n")
+		if meta.Note != "" {
+			for line := range strings.SplitSeq(wrapStyle.Render(meta.Note), "\n") {
+				b.WriteString(indent + line + "\n")
+			}
+		}
+		if len(meta.Tags) > 0 {
+			b.WriteString(indent + formatTags(meta.Tags) + "\n")
+		}
+		if origin := formatOrigin(meta); origin != "" {
+			b.WriteString(indent + ui.Dim.Render(origin) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if d.issue != nil {
+		b.WriteString(indent + lipgloss.NewStyle().Bold(true).Render("Issue") + "\n")
+		b.WriteString(indent + formatIssue(d.issue) + "\n")
+		b.WriteString("\n")
+	}
+
 	if len(d.commits) > 0 {
 		b.WriteString(indent + lipgloss.NewStyle().Bold(true).Render("Recent Commits") + "\n")
 		for _, c := range d.commits {
@@ -700,6 +815,11 @@ func relativeTime(iso string) string {
 	if err != nil {
 		return iso
 	}
+	return timeAgo(t)
+}
+
+// timeAgo renders t as "just now", "5m ago", "3h ago" or "2d ago".
+func timeAgo(t time.Time) string {
 	d := nowFunc().Sub(t)
 	switch {
 	case d < time.Minute:
@@ -716,26 +836,61 @@ func relativeTime(iso string) string {
 // --- help ---
 
 func (m mcModel) renderHelpOverlay() string {
-	help := []struct{ key, desc string }{
-		{"j/k ↑/↓", "Navigate worktrees"},
-		{"J/K", "Scroll detail panel"},
-		{"/", "Filter by branch name"},
-		{"1", "Toggle filter: local worktrees only"},
-		{"2", "Toggle filter: my PRs"},
-		{"3", "Toggle filter: review requested"},
-		{"4", "Toggle filter: dirty / needs push"},
-		{"Esc", "Clear all filters"},
-		{"→/l", "Go to ground"},
-		{"←/h", "Leave ground"},
-		{"Enter", "Go into worktree"},
-		{"o", "Open worktree in $EDITOR"},
-		{"d", "Dock ghost PR / undock worktree"},
-		{"b", "Toggle board/unboard"},
-		{"r", "Refresh all data"},
-		{":", "Command palette"},
-		{"?", "Toggle this help"},
-		{"q", "Quit"},
-	}
+	km := m.keymap()
+	type entry struct{ key, desc string }
+	var help []entry
+	add := func(key, desc string) {
+		if key != "" {
+			help = append(help, entry{key, desc})
+		}
+	}
+	pair := func(a, b string) string {
+		if km.key(a) == "" || km.key(b) == "" {
+			return km.label(a) + km.label(b)
+		}
+		return km.label(a) + "/" + km.label(b)
+	}
+	withArrow := func(arrow, action string) string {
+		if km.key(action) == "" {
+			return arrow
+		}
+		return arrow + "/" + km.label(action)
+	}
+
+	nav := "↑/↓"
+	if keys := pair("down", "up"); keys != "" {
+		nav = keys + " " + nav
+	}
+	add(nav, "Navigate worktrees")
+	add(pair("scroll-down", "scroll-up"), "Scroll detail panel")
+	add(km.label("filter"), "Filter by branch or expression (e.g. repo:api dirty pr:failing)")
+	add(km.label("filter-local"), "Toggle filter: local worktrees only")
+	add(km.label("filter-mine"), "Toggle filter: my PRs")
+	add(km.label("filter-review"), "Toggle filter: review requested")
+	add(km.label("filter-dirty"), "Toggle filter: dirty / needs push")
+	// Saved views bound to a key sit alongside the filter toggles.
+	for _, name := range m.viewNames() {
+		add(km.label("view:"+name), "Toggle view: "+name)
+	}
+	add(km.label("clear"), "Clear all filters")
+	add(withArrow("→", "ground"), "Go to ground")
+	add(withArrow("←", "leave-ground"), "Leave ground")
+	add(km.label("go"), "Go into worktree")
+	add(km.label("open"), "Open worktree in $EDITOR")
+	add(km.label("dock"), "Dock ghost PR / undock worktree")
+	add(km.label("board"), "Toggle board/unboard")
+	add(km.label("mark"), "Mark row for a bulk action")
+	add(km.label("range"), "Start/end a range of marks")
+	add(km.label("refresh"), "Refresh all data")
+	// Palette commands bound to a key sit before the palette entry.
+	for _, cmd := range append(paletteCommands(), m.customCommands()...) {
+		if !isBuiltinAction(cmd.name) {
+			add(displayKey(km.keys[cmd.name]), cmd.label)
+		}
+	}
+	add(km.label("palette"), "Command palette")
+	add(km.label("help"), "Toggle this help")
+	add(km.label("quit"), "Quit")
 
 	var b strings.Builder
 	b.WriteString(lipgloss.NewStyle().Bold(true).Render("  Mission Control") + "\n\n")
@@ -743,31 +898,70 @@ func (m mcModel) renderHelpOverlay() string {
 		key := lipgloss.NewStyle().Bold(true).Width(10).Render(h.key)
 		b.WriteString("  " + key + " " + h.desc + "\n")
 	}
-	b.WriteString("\n" + ui.Dim.Render("  Press ? to close"))
+	b.WriteString("\n" + ui.Dim.Render("  Press "+km.label("help")+" to close"))
 	return b.String()
 }
 
 func (m mcModel) renderHelpBar() string {
-	keys := []string{
-		"j/k navigate",
-		"→/l ground",
-		"←/h leave ground",
-		"J/K scroll detail",
-		"/ filter",
+	if m.flash != "" {
+		return ui.Red.Render("✗ " + m.flash)
 	}
 
+	km := m.keymap()
+	var keys []string
+	add := func(action, desc string) {
+		switch key := km.key(action); key {
+		case "":
+		case " ":
+			keys = append(keys, "space "+desc)
+		case "esc":
+			keys = append(keys, "esc "+desc)
+		default:
+			keys = append(keys, km.hint(action)+" "+desc)
+		}
+	}
+	pair := func(a, b, desc string) {
+		if km.key(a) != "" && km.key(b) != "" {
+			keys = append(keys, km.hint(a)+"/"+km.hint(b)+" "+desc)
+		}
+	}
+	arrow := func(arrow, action, desc string) {
+		if km.key(action) == "" {
+			keys = append(keys, arrow+" "+desc)
+			return
+		}
+		keys = append(keys, arrow+"/"+km.hint(action)+" "+desc)
+	}
+
+	pair("down", "up", "navigate")
+	arrow("→", "ground", "ground")
+	arrow("←", "leave-ground", "leave ground")
+	pair("scroll-down", "scroll-up", "scroll detail")
+	add("filter", "filter")
+
 	var row mcRow
 	if m.cursor >= 0 && m.cursor < len(m.rows) {
 		row = m.rows[m.cursor]
 	}
 
 	switch {
+	case m.hasMarks():
+		add("mark", "mark")
+		add("range", "range")
+		add("palette", "bulk actions")
+		add("clear", "unmark")
 	case row.kind == rowWorktree:
-		keys = append(keys, "⏎ go", "o open", "b board", "d undock")
+		add("go", "go")
+		add("open", "open")
+		add("board", "board")
+		add("dock", "undock")
 	case row.kind == rowGhostPR:
-		keys = append(keys, "d dock")
+		add("dock", "dock")
 	}
 
-	keys = append(keys, "r refresh", ": commands", "? help", "q quit")
+	add("refresh", "refresh")
+	add("palette", "commands")
+	add("help", "help")
+	add("quit", "quit")
 	return ui.Dim.Render(strings.Join(keys, "  "))
 }
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newNoteCmd() *cobra.Command {
+	var (
+		addTags, removeTags []string
+		issueArg            string
+		prNumber            int
+		edit, clear         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "note <repo> <capsule> [text]",
+		Short: "Show or edit a capsule's note and tags",
+		Long: `Record why a capsule exists. With text, it replaces the capsule's note; with
+--edit, the note opens in $EDITOR. Without either, the capsule's note, tags and
+origin are shown.
+
+Use "." for the repo or capsule to infer them from the current directory.
+
+Tags can be filtered on with "ws status --tag" and "tag:" in mission control.
+
+Examples:
+  ws note api retry-webhooks "flaky deliveries to Slack, see #ops thread"
+  ws note . . --tag oncall --tag q3
+  ws note api retry-webhooks --issue '#482' --pr 51
+  ws note . . --edit`,
+		Args: cobra.RangeArgs(2, 3),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return completeRepoNames(cmd, args, toComplete)
+			case 1:
+				return completeWorktreeNames(0)(cmd, args, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+
+			repo, err := ctx.ResolveRepo(args[0])
+			if err != nil {
+				return err
+			}
+			capsule, err := ctx.ResolveCapsuleArg(repo, args[1])
+			if err != nil {
+				return err
+			}
+			if capsule == workspace.GroundDir {
+				return fmt.Errorf("%s can't have a note", workspace.GroundDir)
+			}
+
+			bareDir := ctx.WS.BareDir(repo)
+			branch := workspace.GitCurrentBranch(filepath.Join(ctx.WS.RepoDir(repo), capsule))
+			if (issueArg != "" || prNumber != 0) && (branch == "" || branch == "HEAD") {
+				return fmt.Errorf("capsule %s has no branch checked out to link", capsule)
+			}
+			if issueArg != "" {
+				ref, err := parseIssueArg(ctx.WS.Org, repo, issueArg)
+				if err != nil {
+					return err
+				}
+				if err := workspace.SetCapsuleIssue(bareDir, branch, ref); err != nil {
+					return err
+				}
+			}
+			if prNumber != 0 {
+				if err := workspace.SetCapsulePR(bareDir, branch, prNumber); err != nil {
+					return err
+				}
+			}
+
+			text, hasText := "", len(args) == 3
+			if hasText {
+				text = strings.TrimSpace(args[2])
+			}
+			if edit {
+				current := ctx.WS.CapsuleMetas(repo)[capsule].Note
+				if text, err = editNote(current); err != nil {
+					return err
+				}
+				hasText = true
+			}
+
+			changed := hasText || clear || len(addTags) > 0 || len(removeTags) > 0
+			if changed {
+				err := ctx.WS.UpdateCapsuleMeta(repo, capsule, func(m *workspace.CapsuleMeta) {
+					if clear {
+						m.Note, m.Tags = "", nil
+					}
+					if hasText {
+						m.Note = text
+					}
+					remove := workspace.NormalizeTags(removeTags)
+					m.Tags = workspace.NormalizeTags(append(m.Tags, addTags...))
+					m.Tags = slices.DeleteFunc(m.Tags, func(t string) bool { return slices.Contains(remove, t) })
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if changed || issueArg != "" || prNumber != 0 {
+				fmt.Fprintf(os.Stderr, "  %s Updated %s %s\n", ui.Green.Render("✓"), ctx.WS.FormatRepoName(repo), ui.TagDim.Render(capsule))
+			}
+			printCapsuleMeta(os.Stderr, ctx, repo, capsule, branch)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&addTags, "tag", nil, "Add a tag (repeatable)")
+	cmd.Flags().StringSliceVar(&removeTags, "untag", nil, "Remove a tag (repeatable)")
+	cmd.Flags().StringVar(&issueArg, "issue", "", "Link an issue, by number or URL")
+	cmd.Flags().IntVar(&prNumber, "pr", 0, "Link a PR by number")
+	cmd.Flags().BoolVarP(&edit, "edit", "e", false, "Edit the note in $EDITOR")
+	cmd.Flags().BoolVar(&clear, "clear", false, "Remove the note and tags")
+	return cmd
+}
+
+// editNote opens note in $EDITOR and returns what was saved.
+func editNote(note string) (string, error) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+	f, err := os.CreateTemp("", "ws-note-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(note)
+	f.Close()
+
+	c := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("running %s: %w", editor, err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// printCapsuleMeta shows what's recorded about a capsule.
+func printCapsuleMeta(w io.Writer, ctx *Context, repo, capsule, branch string) {
+	meta := ctx.WS.CapsuleMetas(repo)[capsule]
+	if meta.Note != "" {
+		for line := range strings.SplitSeq(meta.Note, "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	} else {
+		fmt.Fprintf(w, "  %s\n", ui.Dim.Render("no note"))
+	}
+	if len(meta.Tags) > 0 {
+		fmt.Fprintf(w, "  %s\n", formatTags(meta.Tags))
+	}
+	if origin := formatOrigin(meta); origin != "" {
+		fmt.Fprintf(w, "  %s\n", ui.Dim.Render(origin))
+	}
+	if branch == "" {
+		return
+	}
+	if ref, ok := workspace.CapsuleIssues(ctx.WS.BareDir(repo))[branch]; ok {
+		fmt.Fprintf(w, "  %s\n", ui.Dim.Render("issue "+ref.String()))
+	}
+	if n, ok := workspace.RecordedPRs(ctx.WS.BareDir(repo))[branch]; ok {
+		fmt.Fprintf(w, "  %s\n", ui.Dim.Render(fmt.Sprintf("PR #%d", n)))
+	}
+}
+
+// formatTags renders tags as "#oncall #q3".
+func formatTags(tags []string) string {
+	parts := make([]string, len(tags))
+	for i, t := range tags {
+		parts[i] = "#" + t
+	}
+	return ui.Blue.Render(strings.Join(parts, " "))
+}
+
+// formatOrigin renders how a capsule was made, e.g.
+// "lifted from origin/main 3d ago".
+func formatOrigin(meta workspace.CapsuleMeta) string {
+	var parts []string
+	switch meta.CreatedBy {
+	case "":
+	case "lift":
+		parts = append(parts, "lifted")
+	case "dock":
+		parts = append(parts, "docked")
+	default:
+		parts = append(parts, "made by "+meta.CreatedBy)
+	}
+	if meta.Base != "" {
+		parts = append(parts, "from "+meta.Base)
+	}
+	if !meta.CreatedAt.IsZero() {
+		parts = append(parts, timeAgo(meta.CreatedAt))
+	}
+	return strings.Join(parts, " ")
+}
+
+// maxNoteSummary is how much of a note status lines show.
+const maxNoteSummary = 60
+
+// noteSummary returns the first line of a note, cut to fit a status line.
+func noteSummary(note string) string {
+	line, _, _ := strings.Cut(note, "\n")
+	if r := []rune(line); len(r) > maxNoteSummary {
+		line = strings.TrimSpace(string(r[:maxNoteSummary-1])) + "…"
+	}
+	return line
+}
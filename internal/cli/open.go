@@ -2,51 +2,174 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
+	"github.com/brudil/workspace/internal/ide"
+	"github.com/brudil/workspace/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
-var editors = map[string]func(root string) *exec.Cmd{
-	"cursor": func(root string) *exec.Cmd {
-		return exec.Command("cursor", filepath.Join(root, "workspace.code-workspace"))
-	},
-	"code": func(root string) *exec.Cmd {
-		return exec.Command("code", filepath.Join(root, "workspace.code-workspace"))
-	},
-	"cursor-agent": func(root string) *exec.Cmd {
-		return exec.Command("cursor-agent", filepath.Join(root, "workspace.code-workspace"))
-	},
-	"idea": func(root string) *exec.Cmd {
-		return exec.Command("idea", root)
-	},
+// editor launches an editor on a workspace file, a project directory or a
+// plain directory, depending on which files it reads.
+type editor struct {
+	files   string // generator whose files the editor opens; "" opens a directory
+	command func(target string) *exec.Cmd
+}
+
+var editors = map[string]editor{
+	"cursor":       {files: "vscode", command: appEditor("cursor")},
+	"code":         {files: "vscode", command: appEditor("code")},
+	"cursor-agent": {files: "vscode", command: appEditor("cursor-agent")},
+	"idea":         {files: "idea", command: appEditor("idea")},
+	"zed":          {command: appEditor("zed")},
+	"nvim":         {command: terminalEditor("nvim")},
+	"hx":           {command: terminalEditor("hx")},
+}
+
+// editorNames lists the editors ws open knows, in the order help shows them.
+var editorNames = []string{"cursor", "code", "cursor-agent", "idea", "zed", "nvim", "hx"}
+
+// defaultEditor is opened when none is named.
+const defaultEditor = "cursor"
+
+func appEditor(name string) func(string) *exec.Cmd {
+	return func(target string) *exec.Cmd {
+		return exec.Command(name, target)
+	}
+}
+
+// terminalEditor runs an editor that takes over the terminal from the target
+// directory, so project config such as .nvim.lua and .helix/ is found.
+func terminalEditor(name string) func(string) *exec.Cmd {
+	return func(dir string) *exec.Cmd {
+		c := exec.Command(name, ".")
+		c.Dir = dir
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return c
+	}
+}
+
+func lookupEditor(name string) (editor, error) {
+	e, ok := editors[name]
+	if !ok {
+		return editor{}, fmt.Errorf("unknown editor %q (options: %s)", name, strings.Join(editorNames, ", "))
+	}
+	return e, nil
+}
+
+// workspaceTarget returns what e opens to show every boarded capsule.
+func (e editor) workspaceTarget(root string) string {
+	if e.files == "vscode" {
+		return filepath.Join(root, "workspace.code-workspace")
+	}
+	return root
+}
+
+// capsuleTarget generates the capsule's own workspace or project files, if e
+// reads any, and returns what e opens to show the capsule alone.
+func (e editor) capsuleTarget(ws *workspace.Workspace, repo, capsule string) (string, error) {
+	dir := ws.CapsuleWindowDir(repo, capsule)
+	switch e.files {
+	case "vscode":
+		return ide.GenerateCapsuleVSCode(ws.Root, dir, repo, capsule, ws.DisplayNames)
+	case "idea":
+		return dir, ide.GenerateCapsuleIDEA(ws.Root, dir, repo, capsule, ws.Org, ws.DisplayNames)
+	}
+	return filepath.Join(ws.RepoDir(repo), capsule), nil
+}
+
+// launch starts the editor on target. Terminal editors run in the foreground
+// until they exit.
+func (e editor) launch(target string) error {
+	c := e.command(target)
+	if c.Stdin != nil {
+		return c.Run()
+	}
+	return c.Start()
 }
 
 func newOpenCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:       "open [editor]",
-		Short:     "Open workspace in editor (default: cursor)",
-		Args:      cobra.MaximumNArgs(1),
-		ValidArgs: []string{"cursor", "code", "cursor-agent", "idea"},
+	var capsuleWindow bool
+
+	cmd := &cobra.Command{
+		Use:   "open [editor]",
+		Short: "Open workspace in editor (default: cursor)",
+		Long: `Open the workspace in an editor, with every boarded capsule in one window.
+
+With --capsule, open one capsule in a window of its own instead, whether or not
+it's boarded. Editors that read a workspace or project file get one generated
+for the capsule alone, under ` + workspace.WindowsDir + `/ at the workspace root.
+
+Editors: ` + strings.Join(editorNames, ", ") + `
+
+Examples:
+  ws open code
+  ws open --capsule api feature-x
+  ws open --capsule . . idea`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if capsuleWindow {
+				return cobra.RangeArgs(2, 3)(cmd, args)
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if !capsuleWindow {
+				if len(args) == 0 {
+					return editorNames, cobra.ShellCompDirectiveNoFileComp
+				}
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			switch len(args) {
+			case 0:
+				return completeRepoNames(cmd, args, toComplete)
+			case 1:
+				return completeWorktreeNames(0)(cmd, args, toComplete)
+			case 2:
+				return editorNames, cobra.ShellCompDirectiveNoFileComp
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, err := LoadContext()
 			if err != nil {
 				return err
 			}
 
-			editor := "cursor"
-			if len(args) > 0 {
-				editor = args[0]
+			name := defaultEditor
+			if !capsuleWindow && len(args) == 1 {
+				name = args[0]
+			} else if capsuleWindow && len(args) == 3 {
+				name = args[2]
+			}
+			e, err := lookupEditor(name)
+			if err != nil {
+				return err
 			}
 
-			factory, ok := editors[editor]
-			if !ok {
-				return fmt.Errorf("unknown editor %q (options: cursor, code, cursor-agent, idea)", editor)
+			if !capsuleWindow {
+				return e.launch(e.workspaceTarget(ctx.WS.Root))
 			}
 
-			c := factory(ctx.WS.Root)
-			return c.Start()
+			repo, err := ctx.ResolveRepo(args[0])
+			if err != nil {
+				return err
+			}
+			capsule, err := ctx.ResolveCapsuleArg(repo, args[1])
+			if err != nil {
+				return err
+			}
+			target, err := e.capsuleTarget(ctx.WS, repo, capsule)
+			if err != nil {
+				return fmt.Errorf("generating editor files: %w", err)
+			}
+			return e.launch(target)
 		},
 	}
+
+	cmd.Flags().BoolVar(&capsuleWindow, "capsule", false, "Open one capsule, given as <repo> <capsule>, in a window of its own")
+	return cmd
 }
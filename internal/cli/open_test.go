@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+func TestEditorCapsuleTarget(t *testing.T) {
+	ws := &workspace.Workspace{Root: t.TempDir(), Org: "test-org"}
+	windowDir := ws.CapsuleWindowDir("api", "feature-x")
+
+	tests := []struct {
+		editor, want string
+	}{
+		{"code", filepath.Join(windowDir, "api-feature-x.code-workspace")},
+		{"idea", windowDir},
+		{"zed", filepath.Join(ws.RepoDir("api"), "feature-x")},
+		{"nvim", filepath.Join(ws.RepoDir("api"), "feature-x")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.editor, func(t *testing.T) {
+			e, err := lookupEditor(tt.editor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := e.capsuleTarget(ws, "api", "feature-x")
+			if err != nil {
+				t.Fatalf("capsuleTarget() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("target = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(windowDir, ".idea", "modules", "api-feature-x.iml")); err != nil {
+		t.Error("idea project wasn't generated")
+	}
+	if _, err := lookupEditor("emacs"); err == nil {
+		t.Error("expected an error for an unknown editor")
+	}
+}
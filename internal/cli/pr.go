@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newPRCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pr",
+		Short: "Open and update pull requests for capsules",
+	}
+	cmd.AddCommand(newPRCreateCmd())
+	cmd.AddCommand(newPRLinkCmd())
+	cmd.AddCommand(newPRUnlinkCmd())
+	cmd.AddCommand(newPRSyncCmd())
+	return cmd
+}
+
+// completePRCapsuleArgs completes the [repo] [capsule] arguments shared by
+// the pr subcommands, after offset leading arguments.
+func completePRCapsuleArgs(offset int) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) - offset {
+		case 0:
+			return completeRepoNames(cmd, args[offset:], toComplete)
+		case 1:
+			return completeWorktreeNames(offset)(cmd, args, toComplete)
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+// resolvePRCapsule resolves the optional [repo] [capsule] arguments of the
+// pr subcommands, defaulting to the capsule in the current directory.
+func resolvePRCapsule(ctx *Context, args []string) (repo, capsule string, err error) {
+	repoArg := "."
+	if len(args) > 0 {
+		repoArg = args[0]
+	}
+	repo, err = ctx.ResolveRepo(repoArg)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(args) == 2 {
+		capsule, err = ctx.ResolveCapsule(repo, args[1])
+		return repo, capsule, err
+	}
+	cwd, _ := os.Getwd()
+	if r, wt, ok := workspace.DetectRepo(ctx.WS.Root, cwd); ok && r == repo {
+		capsule = wt
+	}
+	if capsule == "" {
+		return "", "", fmt.Errorf("not inside a capsule; name one after the repo")
+	}
+	return repo, capsule, nil
+}
+
+// capsuleBranch returns the branch checked out in a capsule, refusing the
+// ground and detached capsules.
+func capsuleBranch(ctx *Context, repo, capsule string) (string, error) {
+	if capsule == workspace.GroundDir {
+		return "", fmt.Errorf("the ground tracks %s; lift a capsule for a PR", ctx.WS.DefaultBranch)
+	}
+	branch := workspace.GitCurrentBranch(filepath.Join(ctx.WS.RepoDir(repo), capsule))
+	if branch == "" || branch == "HEAD" {
+		return "", fmt.Errorf("capsule %s has no branch checked out", capsule)
+	}
+	if branch == ctx.WS.DefaultBranch {
+		return "", fmt.Errorf("capsule %s is on %s; PRs need their own branch", capsule, branch)
+	}
+	return branch, nil
+}
+
+type prCreateOptions struct {
+	base  string
+	title string
+	body  string
+	draft bool
+	sync  bool
+}
+
+func newPRCreateCmd() *cobra.Command {
+	var opts prCreateOptions
+
+	cmd := &cobra.Command{
+		Use:   "create [repo] [capsule]",
+		Short: "Push a capsule and open a pull request for it",
+		Long: `Push a capsule's branch and open a pull request for it. The title and body
+are prefilled from the capsule's commits, followed by the repo's pull request
+template. The base is origin/<default-branch>, or the parent capsule for a
+capsule stacked with "ws lift --on". Use "." (or nothing) as the repo and
+leave out the capsule to use the capsule you're in.
+
+If the branch already has an open pull request, it is pushed to update it.
+With --sync, the PR and the PRs linked with it get a Related PRs section
+(see "ws pr sync").
+
+Examples:
+  ws pr create
+  ws pr create frontend my-feature
+  ws pr create . my-feature --draft
+  ws pr create frontend my-feature --base release-2.4 --title "Fix login"`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completePRCapsuleArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+
+			repo, capsule, err := resolvePRCapsule(ctx, args)
+			if err != nil {
+				return err
+			}
+
+			return runPRCreate(ctx, repo, capsule, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.base, "base", "", "Branch to merge into (default: the default branch, or the stack parent)")
+	cmd.Flags().StringVar(&opts.title, "title", "", "PR title (default: from commits)")
+	cmd.Flags().StringVar(&opts.body, "body", "", "PR body (default: from commits and the PR template)")
+	cmd.Flags().BoolVar(&opts.draft, "draft", false, "Open the PR as a draft")
+	cmd.Flags().BoolVar(&opts.sync, "sync", false, "Update the Related PRs section of linked PRs")
+	return cmd
+}
+
+// branchLanded reports whether a merged PR came from branch.
+func branchLanded(ctx *Context, repo, branch string) bool {
+	merged, _ := ctx.GitHub.MergedPRsForRepo(ctx.WS.Org, repo)
+	return slices.ContainsFunc(merged, func(pr github.PR) bool { return pr.HeadRefName == branch })
+}
+
+func runPRCreate(ctx *Context, repo, capsule string, opts prCreateOptions) error {
+	if github.IsOffline(ctx.GitHub) {
+		return fmt.Errorf("can't open a PR offline")
+	}
+	branch, err := capsuleBranch(ctx, repo, capsule)
+	if err != nil {
+		return err
+	}
+	bareDir := ctx.WS.BareDir(repo)
+	wtPath := filepath.Join(ctx.WS.RepoDir(repo), capsule)
+
+	base := opts.base
+	if base == "" {
+		base = ctx.WS.DefaultBranch
+		if parent, _ := workspace.StackParent(bareDir, branch); parent != "" {
+			if branchLanded(ctx, repo, parent) {
+				return fmt.Errorf("%s is stacked on %s, which has landed; run ws restack to move it onto %s, or pass --base", capsule, parent, ctx.WS.DefaultBranch)
+			}
+			if !workspace.GitRefExists(bareDir, "origin/"+parent) {
+				return fmt.Errorf("%s is stacked on %s, which isn't pushed yet; open its PR first or pass --base", capsule, parent)
+			}
+			base = parent
+		}
+	}
+
+	existing := openPRForBranch(ctx, repo, branch)
+
+	fmt.Fprintf(os.Stderr, "  Pushing %s...\n", branch)
+	if err := workspace.GitPush(wtPath, branch); err != nil {
+		return fmt.Errorf("pushing %s: %w", branch, err)
+	}
+
+	if existing != nil {
+		fmt.Fprintf(os.Stderr, "  %s Updated %s %s\n", ui.Green.Render("✓"), ui.TagDim.Render(fmt.Sprintf("#%d", existing.Number)), existing.Title)
+		if existing.URL != "" {
+			fmt.Fprintf(os.Stderr, "    %s\n", existing.URL)
+		}
+		if opts.sync {
+			return runPRSync(ctx, repo, branch)
+		}
+		return nil
+	}
+
+	title, body := workspace.PRDraft(wtPath, "origin/"+base)
+	if opts.title != "" {
+		title = opts.title
+	}
+	if opts.body != "" {
+		body = opts.body
+	}
+
+	pr, err := ctx.GitHub.CreatePR(ctx.WS.Org, repo, github.CreatePROptions{
+		Head:  branch,
+		Base:  base,
+		Title: title,
+		Body:  body,
+		Draft: opts.draft,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Link the PR right away rather than waiting for GitHub to list it.
+	if err := workspace.SetCapsulePR(bareDir, branch, pr.Number); err != nil {
+		fmt.Fprintf(os.Stderr, "  %s couldn't record the PR on %s: %v\n", ui.Orange.Render("⚠"), capsule, err)
+	}
+
+	kind := "Opened"
+	if opts.draft {
+		kind = "Opened draft"
+	}
+	fmt.Fprintf(os.Stderr, "  %s %s %s %s → %s\n", ui.Green.Render("✓"), kind,
+		ui.TagDim.Render(fmt.Sprintf("#%d", pr.Number)), pr.Title, base)
+	if pr.URL != "" {
+		fmt.Fprintf(os.Stderr, "    %s\n", pr.URL)
+	}
+	if opts.sync {
+		return runPRSync(ctx, repo, branch)
+	}
+	return nil
+}
+
+// openPRForBranch returns the open PR for branch, if GitHub lists one or one
+// was recorded by an earlier "ws pr create".
+func openPRForBranch(ctx *Context, repo, branch string) *github.PR {
+	prs, err := ctx.GitHub.PRsForRepo(ctx.WS.Org, repo)
+	if err != nil {
+		return nil
+	}
+	for _, pr := range withRecordedPRs(ctx.WS, ctx.GitHub, repo, prs) {
+		if pr.HeadRefName == branch {
+			return &pr
+		}
+	}
+	return nil
+}
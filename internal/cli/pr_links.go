@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// --- linked PRs ---
+//
+// A change that spans repos opens one PR per repo. PRs are linked when their
+// head branches share a name, or when their capsules were put in the same
+// group with "ws pr link".
+
+func newPRLinkCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "link <group> [repo] [capsule]",
+		Short: "Link a capsule's PR with PRs in other repos",
+		Long: `Put a capsule in a named group of linked PRs. PRs whose branches share a
+name across repos are linked automatically; use a group when they don't.
+Linked PRs are shown together in "ws status" and mission control, and
+"ws pr sync" lists them in each PR's description.
+
+Examples:
+  ws pr link auth-flow
+  ws pr link auth-flow backend token-endpoint`,
+		Args:              cobra.RangeArgs(1, 3),
+		ValidArgsFunction: completePRCapsuleArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			repo, capsule, err := resolvePRCapsule(ctx, args[1:])
+			if err != nil {
+				return err
+			}
+			branch, err := capsuleBranch(ctx, repo, capsule)
+			if err != nil {
+				return err
+			}
+			if err := workspace.SetPRGroup(ctx.WS.BareDir(repo), branch, args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "  %s %s linked as %s\n", ui.Green.Render("✓"), ui.TagDim.Render(capsule), args[0])
+			return nil
+		},
+	}
+}
+
+func newPRUnlinkCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "unlink [repo] [capsule]",
+		Short:             "Remove a capsule from its PR group",
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completePRCapsuleArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			repo, capsule, err := resolvePRCapsule(ctx, args)
+			if err != nil {
+				return err
+			}
+			branch, err := capsuleBranch(ctx, repo, capsule)
+			if err != nil {
+				return err
+			}
+			workspace.ClearPRGroup(ctx.WS.BareDir(repo), branch)
+			fmt.Fprintf(os.Stderr, "  %s %s unlinked\n", ui.Green.Render("✓"), ui.TagDim.Render(capsule))
+			return nil
+		},
+	}
+}
+
+func newPRSyncCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync [repo] [capsule]",
+		Short: "Add a Related PRs section to linked PRs",
+		Long: `Add or update a "Related PRs" section in the description of a capsule's PR
+and every PR linked with it, so reviewers can find the rest of the change.
+The section is rewritten on each sync and removed once a PR has no links.
+
+Examples:
+  ws pr sync
+  ws pr sync frontend my-feature`,
+		Args:              cobra.MaximumNArgs(2),
+		ValidArgsFunction: completePRCapsuleArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			repo, capsule, err := resolvePRCapsule(ctx, args)
+			if err != nil {
+				return err
+			}
+			branch, err := capsuleBranch(ctx, repo, capsule)
+			if err != nil {
+				return err
+			}
+			return runPRSync(ctx, repo, branch)
+		},
+	}
+}
+
+// runPRSync rewrites the Related PRs section of every PR linked with
+// branch's PR in repo.
+func runPRSync(ctx *Context, repo, branch string) error {
+	if github.IsOffline(ctx.GitHub) {
+		return fmt.Errorf("can't update PRs offline")
+	}
+	groups := readPRGroups(ctx.WS, ctx.WS.RepoNames)
+	key := prLinkKey(groups[repo], branch)
+
+	var members []prLink
+	results := ctx.GitHub.PRsForRepos(ctx.WS.Org, ctx.WS.RepoNames)
+	for _, r := range ctx.WS.RepoNames {
+		if err := results[r].Err; err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", ui.Orange.Render("⚠"), ctx.WS.FormatRepoName(r), err)
+			continue
+		}
+		for _, pr := range withRecordedPRs(ctx.WS, ctx.GitHub, r, results[r].Open) {
+			if prLinkKey(groups[r], pr.HeadRefName) == key {
+				members = append(members, prLink{repo: r, pr: &pr})
+			}
+		}
+	}
+	if !slices.ContainsFunc(members, func(l prLink) bool { return l.repo == repo }) {
+		return fmt.Errorf("%s has no open PR; run ws pr create first", branch)
+	}
+
+	for _, l := range members {
+		var refs []string
+		for _, other := range members {
+			if other.repo != l.repo {
+				refs = append(refs, fmt.Sprintf("%s/%s#%d", ctx.WS.Org, other.repo, other.pr.Number))
+			}
+		}
+		label := ctx.WS.FormatRepoName(l.repo) + " " + ui.TagDim.Render(fmt.Sprintf("#%d", l.pr.Number))
+
+		detail, err := ctx.GitHub.PRDetail(ctx.WS.Org, l.repo, l.pr.Number)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", ui.Red.Render("✗"), label, err)
+			continue
+		}
+		body := withRelatedSection(detail.Body, refs)
+		if body == strings.TrimRight(detail.Body, "\n") {
+			fmt.Fprintf(os.Stderr, "  %s %s already up to date\n", ui.Dim.Render("·"), label)
+			continue
+		}
+		if err := ctx.GitHub.EditPRBody(ctx.WS.Org, l.repo, l.pr.Number, body); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", ui.Red.Render("✗"), label, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  %s %s now lists %d related PRs\n", ui.Green.Render("✓"), label, len(refs))
+	}
+	return nil
+}
+
+// prLink is one PR in a group of linked PRs.
+type prLink struct {
+	repo string
+	pr   *github.PR
+}
+
+// prLinks maps a link key to the PRs sharing it, across repos.
+type prLinks map[string][]prLink
+
+// prLinkKey returns the key branch's PR is linked by: its recorded group, or
+// the branch name.
+func prLinkKey(groups map[string]string, branch string) string {
+	if g := groups[branch]; g != "" {
+		return g
+	}
+	return branch
+}
+
+// readPRGroups reads the recorded PR groups for each repo.
+func readPRGroups(ws *workspace.Workspace, repos []string) map[string]map[string]string {
+	groups := make(map[string]map[string]string, len(repos))
+	for _, repo := range repos {
+		groups[repo] = workspace.PRGroups(ws.BareDir(repo))
+	}
+	return groups
+}
+
+// linkPRs groups the open PRs of each repo (keyed by head branch) by link
+// key. Only keys with PRs in more than one repo are kept.
+func linkPRs(prsByRepo map[string]map[string]*github.PR, groups map[string]map[string]string) prLinks {
+	links := make(prLinks)
+	for repo, prs := range prsByRepo {
+		for branch, pr := range prs {
+			key := prLinkKey(groups[repo], branch)
+			links[key] = append(links[key], prLink{repo: repo, pr: pr})
+		}
+	}
+	for key, group := range links {
+		if !spansRepos(group) {
+			delete(links, key)
+			continue
+		}
+		slices.SortFunc(group, func(a, b prLink) int {
+			return cmp.Or(cmp.Compare(a.repo, b.repo), cmp.Compare(a.pr.Number, b.pr.Number))
+		})
+	}
+	return links
+}
+
+func spansRepos(group []prLink) bool {
+	for _, l := range group[1:] {
+		if l.repo != group[0].repo {
+			return true
+		}
+	}
+	return false
+}
+
+// related returns the PRs linked with branch's PR in repo, excluding those
+// in repo itself.
+func (l prLinks) related(groups map[string]map[string]string, repo, branch string) []prLink {
+	if branch == "" {
+		return nil
+	}
+	var out []prLink
+	for _, link := range l[prLinkKey(groups[repo], branch)] {
+		if link.repo != repo {
+			out = append(out, link)
+		}
+	}
+	return out
+}
+
+// formatLinkedPRs renders linked PRs as "⛓ Backend #34, Infra #5".
+func formatLinkedPRs(ws *workspace.Workspace, links []prLink) string {
+	if len(links) == 0 {
+		return ""
+	}
+	parts := make([]string, len(links))
+	for i, l := range links {
+		num := fmt.Sprintf("#%d", l.pr.Number)
+		if l.pr.URL != "" {
+			num = ui.Hyperlink(l.pr.URL, num)
+		}
+		parts[i] = ws.DisplayNameFor(l.repo) + " " + num
+	}
+	return ui.Dim.Render("⛓ " + strings.Join(parts, ", "))
+}
+
+// --- Related PRs section ---
+
+const (
+	relatedStart = "<!-- ws:related-prs -->"
+	relatedEnd   = "<!-- /ws:related-prs -->"
+)
+
+// withRelatedSection returns body with its "Related PRs" section set to
+// refs (e.g. "org/backend#34"), replacing any section ws added before. With
+// no refs, the section is removed.
+func withRelatedSection(body string, refs []string) string {
+	if start := strings.Index(body, relatedStart); start >= 0 {
+		if end := strings.Index(body[start:], relatedEnd); end >= 0 {
+			body = strings.TrimRight(body[:start], "\n") + body[start+end+len(relatedEnd):]
+		}
+	}
+	body = strings.TrimRight(body, "\n")
+	if len(refs) == 0 {
+		return body
+	}
+
+	var b strings.Builder
+	b.WriteString(relatedStart + "\n### Related PRs\n\n")
+	for _, ref := range refs {
+		b.WriteString("- " + ref + "\n")
+	}
+	b.WriteString(relatedEnd)
+	if body == "" {
+		return b.String()
+	}
+	return body + "\n\n" + b.String()
+}
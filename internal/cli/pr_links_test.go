@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/brudil/workspace/internal/github"
+)
+
+func TestLinkPRs(t *testing.T) {
+	prsByRepo := map[string]map[string]*github.PR{
+		"frontend": {
+			"auth":       {Number: 1, HeadRefName: "auth"},
+			"solo":       {Number: 2, HeadRefName: "solo"},
+			"login-form": {Number: 3, HeadRefName: "login-form"},
+		},
+		"backend": {
+			"auth":     {Number: 10, HeadRefName: "auth"},
+			"token-ep": {Number: 11, HeadRefName: "token-ep"},
+		},
+	}
+	groups := map[string]map[string]string{
+		"frontend": {"login-form": "login"},
+		"backend":  {"token-ep": "login"},
+	}
+
+	links := linkPRs(prsByRepo, groups)
+
+	if len(links) != 2 {
+		t.Fatalf("got %d link groups, want 2 (auth and login): %v", len(links), links)
+	}
+	if _, ok := links["solo"]; ok {
+		t.Error("a PR in a single repo should not be linked")
+	}
+	related := links.related(groups, "frontend", "auth")
+	if len(related) != 1 || related[0].repo != "backend" || related[0].pr.Number != 10 {
+		t.Errorf("related(frontend, auth) = %v, want backend #10", related)
+	}
+	related = links.related(groups, "backend", "token-ep")
+	if len(related) != 1 || related[0].pr.Number != 3 {
+		t.Errorf("related(backend, token-ep) = %v, want frontend #3", related)
+	}
+	if got := links.related(groups, "frontend", "solo"); got != nil {
+		t.Errorf("related(frontend, solo) = %v, want none", got)
+	}
+}
+
+func TestWithRelatedSection(t *testing.T) {
+	section := "<!-- ws:related-prs -->\n### Related PRs\n\n- acme/backend#10\n<!-- /ws:related-prs -->"
+
+	tests := []struct {
+		name string
+		body string
+		refs []string
+		want string
+	}{
+		{"empty body", "", []string{"acme/backend#10"}, section},
+		{"appends", "Adds auth.\n", []string{"acme/backend#10"}, "Adds auth.\n\n" + section},
+		{
+			"replaces",
+			"Adds auth.\n\n<!-- ws:related-prs -->\n### Related PRs\n\n- acme/infra#2\n<!-- /ws:related-prs -->",
+			[]string{"acme/backend#10"},
+			"Adds auth.\n\n" + section,
+		},
+		{"removes", "Adds auth.\n\n" + section, nil, "Adds auth."},
+		{"unchanged", "Adds auth.\n\n" + section, []string{"acme/backend#10"}, "Adds auth.\n\n" + section},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withRelatedSection(tt.body, tt.refs); got != tt.want {
+				t.Errorf("withRelatedSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPRLinks_FollowsNewPRs(t *testing.T) {
+	m := goldenMCModel()
+	backendRow := func() mcRow {
+		for _, row := range m.rows {
+			if row.repo == "backend" && row.wt == "add-api" {
+				return row
+			}
+		}
+		t.Fatal("backend add-api row missing")
+		return mcRow{}
+	}
+
+	m.processPRs("backend", []github.PR{{Number: 7, HeadRefName: "add-api"}})
+	if backendRow().linked != nil {
+		t.Fatal("nothing to link with until another repo has a PR")
+	}
+
+	// A PR for the same branch in frontend links both, even as a ghost row.
+	m.processPRs("frontend", []github.PR{{Number: 42, HeadRefName: "add-api"}})
+
+	row := backendRow()
+	if len(row.linked) != 1 || row.linked[0].repo != "frontend" || row.linked[0].pr.Number != 42 {
+		t.Errorf("backend add-api linked = %v, want frontend #42", row.linked)
+	}
+	expr, err := parseFilterExpr("linked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expr.matches(m, row) {
+		t.Error("the linked filter should match a linked capsule")
+	}
+}
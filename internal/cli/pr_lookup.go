@@ -1,6 +1,12 @@
 package cli
 
-import "github.com/brudil/workspace/internal/github"
+import (
+	"maps"
+	"slices"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/workspace"
+)
 
 func lookupPR(prs map[string]*github.PR, branch, name string) *github.PR {
 	if branch != "" {
@@ -10,3 +16,42 @@ func lookupPR(prs map[string]*github.PR, branch, name string) *github.PR {
 	}
 	return prs[name]
 }
+
+// withRecordedPRs adds the PRs recorded by "ws pr create" that the open PR
+// list doesn't include yet, since GitHub can take a moment to list a new PR.
+// Records for PRs that are no longer open are dropped.
+func withRecordedPRs(ws *workspace.Workspace, gh github.Client, repo string, prs []github.PR) []github.PR {
+	bareDir := ws.BareDir(repo)
+	recorded := workspace.RecordedPRs(bareDir)
+	if len(recorded) == 0 {
+		return prs
+	}
+	listed := make(map[int]bool, len(prs))
+	for _, pr := range prs {
+		listed[pr.Number] = true
+	}
+	var missing []int
+	for _, number := range recorded {
+		if !listed[number] {
+			missing = append(missing, number)
+		}
+	}
+	if len(missing) == 0 {
+		return prs
+	}
+	slices.Sort(missing)
+
+	// A failed fetch still returns the PRs it got.
+	found, _ := gh.PRsFromNumbers(ws.Org, repo, missing)
+	for _, branch := range slices.Sorted(maps.Keys(recorded)) {
+		pr, ok := found[recorded[branch]]
+		switch {
+		case listed[recorded[branch]] || !ok:
+		case pr.State != "OPEN":
+			workspace.ClearCapsulePR(bareDir, branch)
+		default:
+			prs = append(prs, pr)
+		}
+	}
+	return prs
+}
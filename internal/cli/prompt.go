@@ -4,10 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -20,40 +24,97 @@ type PromptData struct {
 	RepoColor            string `json:"repo_color"`
 	CapsuleName          string `json:"capsule_name"`
 	IsCapsuleBoarded     bool   `json:"is_capsule_boarded"`
+	Branch               string `json:"branch,omitempty"`
+	IsDirty              bool   `json:"is_dirty,omitempty"`
+	DirtyCount           int    `json:"dirty_count,omitempty"`
+	Ahead                int    `json:"ahead,omitempty"`
+	Behind               int    `json:"behind,omitempty"`
+	StashCount           int    `json:"stash_count,omitempty"`
+	IsGitStateStale      bool   `json:"is_git_state_stale,omitempty"` // git didn't answer in time, so the above are cached
+	SiloTargetOf         string `json:"silo_target_of,omitempty"`     // the repo whose silo points at this capsule
+	PRNumber             int    `json:"pr_number,omitempty"`
+	PRState              string `json:"pr_state,omitempty"`  // open or merged
+	PRChecks             string `json:"pr_checks,omitempty"` // success, failure or pending
+}
+
+// promptFields are the optional groups of PromptData fields --fields picks
+// from. Only what's asked for is computed.
+var promptFields = []string{"branch", "dirty", "ahead_behind", "stash", "silo", "pr"}
+
+// parsePromptFields turns --fields into a set, expanding "all".
+func parsePromptFields(list []string) (map[string]bool, error) {
+	fields := make(map[string]bool)
+	for _, f := range list {
+		f = strings.TrimSpace(f)
+		switch {
+		case f == "all":
+			for _, name := range promptFields {
+				fields[name] = true
+			}
+		case slices.Contains(promptFields, f):
+			fields[f] = true
+		case f != "":
+			return nil, fmt.Errorf("unknown field %q (fields: %s, all)", f, strings.Join(promptFields, ", "))
+		}
+	}
+	return fields, nil
 }
 
 func newPromptCmd() *cobra.Command {
 	var format string
 	var tmpl string
+	var fieldList []string
+	var budget time.Duration
+	var refresh bool
 
 	cmd := &cobra.Command{
-		Use:           "prompt",
-		Short:         "Output workspace context for shell prompts",
-		Long:          "Outputs current repo and worktree info for use in shell prompts (starship, p10k, PS1).\nSilently exits with no output when not inside a workspace repo.",
+		Use:   "prompt",
+		Short: "Output workspace context for shell prompts",
+		Long: `Outputs current repo and worktree info for use in shell prompts (starship, p10k, PS1).
+Silently exits with no output when not inside a workspace repo.
+
+Git state is cached per capsule. If git takes longer than --budget, the
+cached state is shown and refreshed in the background for the next prompt.
+
+Fields: ` + strings.Join(promptFields, ", ") + `, all`,
 		Args:          cobra.NoArgs,
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPrompt(format, tmpl)
+			if refresh {
+				return runPromptRefresh()
+			}
+			fields, err := parsePromptFields(fieldList)
+			if err != nil {
+				return err
+			}
+			return runPrompt(format, tmpl, fields, budget)
 		},
 	}
 
 	cmd.Flags().StringVarP(&format, "format", "f", "short", "Output format: short, json")
 	cmd.Flags().StringVarP(&tmpl, "template", "t", "", "Go template for custom output (e.g. '{{.RepoName}}:{{.CapsuleName}}')")
+	cmd.Flags().StringSliceVar(&fieldList, "fields", nil, "Optional fields to fill in: "+strings.Join(promptFields, ", ")+", all")
+	cmd.Flags().DurationVar(&budget, "budget", defaultPromptBudget, "How long to wait on git before using cached state")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Refresh the cached git state and exit")
+	cmd.Flags().MarkHidden("refresh")
 	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"short", "json"}, cobra.ShellCompDirectiveNoFileComp
 	})
+	cmd.RegisterFlagCompletionFunc("fields", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return append(slices.Clone(promptFields), "all"), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	return cmd
 }
 
-func runPrompt(format, tmpl string) error {
+func runPrompt(format, tmpl string, fields map[string]bool, budget time.Duration) error {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil // silent
 	}
 
-	data, ok := resolvePromptData(cwd)
+	data, ok := resolvePromptData(cwd, fields, budget)
 	if !ok {
 		return nil
 	}
@@ -61,9 +122,29 @@ func runPrompt(format, tmpl string) error {
 	return formatPrompt(os.Stdout, data, format, tmpl)
 }
 
-// resolvePromptData detects workspace context from the given directory.
-// Returns false if not inside a workspace repo.
-func resolvePromptData(cwd string) (PromptData, bool) {
+// runPromptRefresh updates the current capsule's cached git state. It's run
+// in the background by a prompt that ran out of budget.
+func runPromptRefresh() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	root, err := config.Discover(cwd)
+	if err != nil {
+		return nil
+	}
+	repo, wt, ok := workspace.DetectRepo(root, cwd)
+	if !ok {
+		return nil
+	}
+	refreshPromptCache(filepath.Join(root, "repos", repo, wt), promptCachePath(root, repo, wt))
+	return nil
+}
+
+// resolvePromptData detects workspace context from the given directory,
+// filling in the optional fields asked for. Git state is waited on for at
+// most budget. Returns false if not inside a workspace repo.
+func resolvePromptData(cwd string, fields map[string]bool, budget time.Duration) (PromptData, bool) {
 	root, err := config.Discover(cwd)
 	if err != nil {
 		return PromptData{}, false
@@ -98,14 +179,71 @@ func resolvePromptData(cwd string) (PromptData, bool) {
 		isBoarded = true
 	}
 
-	return PromptData{
+	data := PromptData{
 		WorkspaceDisplayName: wsName,
 		RepoName:             repo,
 		RepoDisplayName:      repoDisplayName,
 		RepoColor:            color,
 		CapsuleName:          wt,
 		IsCapsuleBoarded:     isBoarded,
-	}, true
+	}
+	if fields["silo"] && cfg.Silo[repo] == wt {
+		data.SiloTargetOf = repo
+	}
+
+	// Every other field needs the branch, so comes from the git state.
+	if !fields["branch"] && !fields["dirty"] && !fields["ahead_behind"] && !fields["stash"] && !fields["pr"] {
+		return data, true
+	}
+	state, stale, ok := promptGitStateFor(filepath.Join(root, "repos", repo, wt), promptCachePath(root, repo, wt), budget)
+	if !ok {
+		return data, true
+	}
+	data.IsGitStateStale = stale
+	if fields["branch"] {
+		data.Branch = state.Branch
+	}
+	if fields["dirty"] {
+		data.IsDirty = state.DirtyCount > 0
+		data.DirtyCount = state.DirtyCount
+	}
+	if fields["ahead_behind"] {
+		data.Ahead, data.Behind = state.Ahead, state.Behind
+	}
+	if fields["stash"] {
+		data.StashCount = state.StashCount
+	}
+	if fields["pr"] {
+		if pr, prState := cachedCapsulePR(root, cfg.Workspace.Org, repo, wt, state.Branch); pr != nil {
+			data.PRNumber = pr.Number
+			data.PRState = prState
+			data.PRChecks = pr.StatusRollup
+		}
+	}
+	return data, true
+}
+
+// cachedCapsulePR returns the PR for the capsule's branch from the workspace
+// cache, and whether it's open or merged. Prompts are drawn too often to wait
+// on GitHub, so a miss is just nil.
+func cachedCapsulePR(root, org, repo, wt, branch string) (*github.PR, string) {
+	if wt == workspace.GroundDir || branch == "" {
+		return nil, ""
+	}
+	cache := github.NewCache(github.CacheDir(root))
+	open, _, _ := cache.OpenPRs(org, repo)
+	merged, _, _ := cache.MergedPRs(org, repo)
+	for _, list := range []struct {
+		prs   []github.PR
+		state string
+	}{{open, "open"}, {merged, "merged"}} {
+		for i := range list.prs {
+			if list.prs[i].HeadRefName == branch {
+				return &list.prs[i], list.state
+			}
+		}
+	}
+	return nil, ""
 }
 
 // formatPrompt writes prompt data to w in the requested format.
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brudil/workspace/internal/github"
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+// promptGitState is a capsule's git state as the prompt shows it, cached
+// per capsule so prompts don't run git on every redraw.
+type promptGitState struct {
+	Branch     string    `json:"branch"`
+	DirtyCount int       `json:"dirtyCount"`
+	Ahead      int       `json:"ahead"`
+	Behind     int       `json:"behind"`
+	StashCount int       `json:"stashCount"`
+	Key        string    `json:"key"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// promptCacheTTL is how long a cached state is trusted while its key holds.
+// The key misses edits to the working tree, so this bounds how stale the
+// dirty count can be.
+const promptCacheTTL = 3 * time.Second
+
+// defaultPromptBudget is how long ws prompt waits on git before falling back
+// to the cache.
+const defaultPromptBudget = 100 * time.Millisecond
+
+// promptCachePath is where a capsule's cached git state lives.
+func promptCachePath(root, repo, capsule string) string {
+	return filepath.Join(github.CacheDir(root), "prompt", url.PathEscape(repo)+"--"+url.PathEscape(capsule)+".json")
+}
+
+// gitStateKey changes whenever the worktree's HEAD or index is written, which
+// covers commits, checkouts, staging and stashing. It's "" if dir isn't a
+// git worktree.
+func gitStateKey(dir string) string {
+	gitDir := filepath.Join(dir, ".git")
+	if data, err := os.ReadFile(gitDir); err == nil {
+		linked, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return ""
+		}
+		if !filepath.IsAbs(linked) {
+			linked = filepath.Join(dir, linked)
+		}
+		gitDir = linked
+	}
+	var parts []string
+	for _, name := range []string{"HEAD", "index"} {
+		info, err := os.Stat(filepath.Join(gitDir, name))
+		if err != nil {
+			if name == "HEAD" {
+				return ""
+			}
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", name, info.ModTime().UnixNano(), info.Size()))
+	}
+	return strings.Join(parts, " ")
+}
+
+func readPromptCache(path string) (promptGitState, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return promptGitState{}, false
+	}
+	var s promptGitState
+	if json.Unmarshal(data, &s) != nil {
+		return promptGitState{}, false
+	}
+	return s, true
+}
+
+func writePromptCache(path string, s promptGitState) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(filepath.Dir(path), 0o755) != nil {
+		return
+	}
+	// A prompt and its background refresh can both write, so each writes
+	// its own file and renames it into place.
+	f, err := os.CreateTemp(filepath.Dir(path), ".prompt-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if f.Close() != nil || err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	os.Rename(f.Name(), path)
+}
+
+// readGitState asks git for a worktree's state, running each query at once.
+func readGitState(dir string) promptGitState {
+	var s promptGitState
+	var wg sync.WaitGroup
+	wg.Go(func() { s.Branch = workspace.GitCurrentBranch(dir) })
+	wg.Go(func() { s.DirtyCount = workspace.GitDirtyCount(dir) })
+	wg.Go(func() { s.Ahead, s.Behind = workspace.GitAheadBehind(dir) })
+	wg.Go(func() { s.StashCount = workspace.GitStashCount(dir) })
+	wg.Wait()
+	return s
+}
+
+// refreshPromptCache reads a worktree's git state and caches it.
+func refreshPromptCache(dir, path string) promptGitState {
+	key := gitStateKey(dir)
+	s := readGitState(dir)
+	s.Key, s.CheckedAt = key, time.Now()
+	writePromptCache(path, s)
+	return s
+}
+
+// promptGitStateFor returns a worktree's git state from the cache when it's
+// still good, and otherwise from git if git answers within budget. When it
+// doesn't, the cached state is returned as stale and a background ws prompt
+// refreshes the cache for the next redraw. ok is false when there's nothing
+// to show.
+func promptGitStateFor(dir, path string, budget time.Duration) (s promptGitState, stale, ok bool) {
+	cached, hit := readPromptCache(path)
+	if hit && cached.Key == gitStateKey(dir) && time.Since(cached.CheckedAt) < promptCacheTTL {
+		return cached, false, true
+	}
+
+	done := make(chan promptGitState, 1)
+	go func() { done <- refreshPromptCache(dir, path) }()
+	select {
+	case s := <-done:
+		return s, false, true
+	case <-time.After(budget):
+		startPromptRefresh(dir)
+		return cached, true, hit
+	}
+}
+
+// startPromptRefresh runs `workspace prompt --refresh` in dir, detached, so
+// the cache is filled in after this prompt has been drawn. Tests replace it.
+var startPromptRefresh = func(dir string) {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(exe, "prompt", "--refresh")
+	cmd.Dir = dir
+	detach(cmd)
+	if cmd.Start() == nil {
+		cmd.Process.Release()
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cli
+
+import "os/exec"
+
+// detach leaves cmd as is; released, it already outlives the prompt here.
+func detach(cmd *exec.Cmd) {}
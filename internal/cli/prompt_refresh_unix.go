@@ -0,0 +1,14 @@
+//go:build unix
+
+package cli
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in its own session, so it outlives the prompt and isn't
+// sent the terminal's signals.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -3,8 +3,12 @@ package cli
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/brudil/workspace/internal/github"
 )
 
 func setupPromptWorkspace(t *testing.T) string {
@@ -36,7 +40,7 @@ my-repo = ["feature-x"]
 }
 
 func TestResolvePromptData_NotInWorkspace(t *testing.T) {
-	_, ok := resolvePromptData("/tmp")
+	_, ok := resolvePromptData("/tmp", nil, defaultPromptBudget)
 	if ok {
 		t.Error("expected ok=false when not in a workspace")
 	}
@@ -45,7 +49,7 @@ func TestResolvePromptData_NotInWorkspace(t *testing.T) {
 func TestResolvePromptData_InWorkspaceRootNotRepo(t *testing.T) {
 	root := setupPromptWorkspace(t)
 
-	_, ok := resolvePromptData(root)
+	_, ok := resolvePromptData(root, nil, defaultPromptBudget)
 	if ok {
 		t.Error("expected ok=false when in workspace root but not a repo dir")
 	}
@@ -55,7 +59,7 @@ func TestResolvePromptData_InRepoWorktree(t *testing.T) {
 	root := setupPromptWorkspace(t)
 	cwd := filepath.Join(root, "repos", "my-repo", "feature-x")
 
-	data, ok := resolvePromptData(cwd)
+	data, ok := resolvePromptData(cwd, nil, defaultPromptBudget)
 	if !ok {
 		t.Fatal("expected ok=true when in a repo worktree")
 	}
@@ -86,7 +90,7 @@ func TestResolvePromptData_NotCurrentWorktree(t *testing.T) {
 	otherWT := filepath.Join(root, "repos", "my-repo", "main")
 	os.MkdirAll(otherWT, 0755)
 
-	data, ok := resolvePromptData(otherWT)
+	data, ok := resolvePromptData(otherWT, nil, defaultPromptBudget)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -108,7 +112,7 @@ my-repo = {}
 	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(content), 0644)
 	os.MkdirAll(filepath.Join(root, "repos", "my-repo", "main"), 0755)
 
-	data, ok := resolvePromptData(filepath.Join(root, "repos", "my-repo", "main"))
+	data, ok := resolvePromptData(filepath.Join(root, "repos", "my-repo", "main"), nil, defaultPromptBudget)
 	if !ok {
 		t.Fatal("expected ok=true")
 	}
@@ -123,6 +127,127 @@ my-repo = {}
 	}
 }
 
+func TestResolvePromptData_CachedPR(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := setupPromptWorkspace(t)
+	cwd := filepath.Join(root, "repos", "my-repo", "feature-x")
+	gitInitPrompt(t, cwd, "feat/x")
+	fields := map[string]bool{"pr": true}
+
+	data, _ := resolvePromptData(cwd, fields, defaultPromptBudget)
+	if data.PRNumber != 0 {
+		t.Errorf("PRNumber = %d, want 0 with nothing cached", data.PRNumber)
+	}
+
+	github.NewCache(github.CacheDir(root)).AddPR("test-org", "my-repo", github.PR{Number: 12, HeadRefName: "feat/x", StatusRollup: "pending"})
+	data, _ = resolvePromptData(cwd, fields, defaultPromptBudget)
+	if data.PRNumber != 12 || data.PRChecks != "pending" || data.PRState != "open" {
+		t.Errorf("PR = #%d %q %q, want #12 open pending from the cache", data.PRNumber, data.PRState, data.PRChecks)
+	}
+	if data.Branch != "" {
+		t.Errorf("Branch = %q, want empty when not asked for", data.Branch)
+	}
+}
+
+func gitInitPrompt(t *testing.T, dir, branch string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", branch},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@test.com"},
+		{"commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestResolvePromptData_GitFields(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := setupPromptWorkspace(t)
+	cwd := filepath.Join(root, "repos", "my-repo", "feature-x")
+	gitInitPrompt(t, cwd, "feat/x")
+	os.WriteFile(filepath.Join(cwd, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte("[silo]\nmy-repo = \"feature-x\"\n"), 0644)
+
+	fields, err := parsePromptFields([]string{"all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := resolvePromptData(cwd, fields, time.Minute)
+	if data.Branch != "feat/x" {
+		t.Errorf("Branch = %q, want feat/x", data.Branch)
+	}
+	if !data.IsDirty || data.DirtyCount != 1 {
+		t.Errorf("dirty = %v %d, want true 1", data.IsDirty, data.DirtyCount)
+	}
+	if data.SiloTargetOf != "my-repo" {
+		t.Errorf("SiloTargetOf = %q, want my-repo", data.SiloTargetOf)
+	}
+	if data.IsGitStateStale {
+		t.Error("expected fresh git state within the budget")
+	}
+	if _, ok := readPromptCache(promptCachePath(root, "my-repo", "feature-x")); !ok {
+		t.Error("expected the git state to be cached")
+	}
+}
+
+func TestPromptGitStateFor_Cache(t *testing.T) {
+	dir := t.TempDir()
+	gitInitPrompt(t, dir, "main")
+	path := filepath.Join(t.TempDir(), "state.json")
+	var refreshed []string
+	old := startPromptRefresh
+	startPromptRefresh = func(dir string) { refreshed = append(refreshed, dir) }
+	t.Cleanup(func() { startPromptRefresh = old })
+
+	// A fresh entry whose key still matches is used as it is.
+	writePromptCache(path, promptGitState{Branch: "cached", StashCount: 7, Key: gitStateKey(dir), CheckedAt: time.Now()})
+	s, stale, ok := promptGitStateFor(dir, path, time.Minute)
+	if !ok || stale || s.Branch != "cached" || s.StashCount != 7 {
+		t.Errorf("fresh cache = %+v stale=%v ok=%v, want the cached state", s, stale, ok)
+	}
+
+	// Out of budget, an old entry is shown as stale and refreshed later.
+	writePromptCache(path, promptGitState{Branch: "cached", Key: "old", CheckedAt: time.Now().Add(-time.Hour)})
+	s, stale, ok = promptGitStateFor(dir, path, 0)
+	if !ok || !stale || s.Branch != "cached" {
+		t.Errorf("over budget = %+v stale=%v ok=%v, want the stale cached state", s, stale, ok)
+	}
+	if len(refreshed) != 1 || refreshed[0] != dir {
+		t.Errorf("refreshed = %v, want a background refresh of %s", refreshed, dir)
+	}
+
+	// Within budget, git is asked again.
+	s, stale, ok = promptGitStateFor(dir, path, time.Minute)
+	if !ok || stale || s.Branch != "main" {
+		t.Errorf("within budget = %+v stale=%v ok=%v, want branch main from git", s, stale, ok)
+	}
+}
+
+func TestParsePromptFields(t *testing.T) {
+	fields, err := parsePromptFields([]string{"branch", " dirty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fields["branch"] || !fields["dirty"] || fields["pr"] {
+		t.Errorf("fields = %v, want branch and dirty", fields)
+	}
+	if _, err := parsePromptFields([]string{"colour"}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestPromptCmd_NoFieldsByDefault(t *testing.T) {
+	list, _ := newPromptCmd().Flags().GetStringSlice("fields")
+	if fields, _ := parsePromptFields(list); len(fields) != 0 {
+		t.Errorf("default fields = %v, want none so prompts don't run git", fields)
+	}
+}
+
 func TestFormatPrompt_Short(t *testing.T) {
 	f := tempFile(t)
 	defer f.Close()
@@ -179,7 +304,7 @@ func TestFormatPrompt_InSubdirectory(t *testing.T) {
 	subDir := filepath.Join(root, "repos", "my-repo", "feature-x", "src", "pkg")
 	os.MkdirAll(subDir, 0755)
 
-	data, ok := resolvePromptData(subDir)
+	data, ok := resolvePromptData(subDir, nil, defaultPromptBudget)
 	if !ok {
 		t.Fatal("expected ok=true when in a subdirectory of a worktree")
 	}
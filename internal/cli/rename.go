@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/ide"
+	"github.com/brudil/workspace/internal/mux"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newRenameCmd() *cobra.Command {
+	var renameBranch bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <repo> <capsule> <new-name>",
+		Short: "Rename a capsule",
+		Long: `Move a capsule to a new directory name. Its board and silo state, IDE
+workspace files and tmux window follow it.
+
+With --branch, the branch is renamed too: its last segment becomes new-name
+and the branch policy in ws.toml is applied. If the branch was pushed, it is
+pushed under the new name and the old name is deleted from origin.
+
+Examples:
+  ws rename frontend login-redirect-safari-fix login
+  ws rename . login-redirect-safari-fix login --branch`,
+		Args: cobra.ExactArgs(3),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return completeRepoNames(cmd, args, toComplete)
+			case 1:
+				return completeWorktreeNames(0)(cmd, args, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+
+			repo, err := ctx.ResolveRepo(args[0])
+			if err != nil {
+				return err
+			}
+			capsule, err := ctx.ResolveCapsule(repo, args[1])
+			if err != nil {
+				return err
+			}
+			newName := args[2]
+
+			oldPath := filepath.Join(ctx.WS.RepoDir(repo), capsule)
+			bareDir := ctx.WS.BareDir(repo)
+			branch := workspace.GitCurrentBranch(oldPath)
+
+			var newBranch string
+			pushed := false
+			if renameBranch {
+				if branch == "" || branch == "HEAD" {
+					return fmt.Errorf("capsule %s has no branch checked out", capsule)
+				}
+				if branch == ctx.WS.DefaultBranch {
+					return fmt.Errorf("cannot rename the default branch (%s)", branch)
+				}
+				newBranch = newName
+				if dir := path.Dir(branch); dir != "." {
+					newBranch = dir + "/" + newName
+				}
+				if newBranch, err = applyBranchPolicy(ctx, newBranch, workspace.BranchData{Repo: repo}); err != nil {
+					return err
+				}
+				pushed = workspace.GitRefExists(bareDir, "refs/remotes/origin/"+branch)
+				if pushed && !confirmRenamePushed(ctx, repo, branch) {
+					fmt.Fprintf(os.Stderr, "  %s Aborted\n", ui.Dim.Render("·"))
+					return nil
+				}
+			}
+
+			// The branch goes first, since it's the easier of the two to put
+			// back if moving the capsule fails.
+			branchRenamed := renameBranch && newBranch != branch
+			if branchRenamed {
+				if err := ctx.WS.RenameBranch(repo, branch, newBranch); err != nil {
+					return err
+				}
+			}
+			if err := ctx.WS.RenameCapsule(repo, capsule, newName); err != nil {
+				if branchRenamed {
+					ctx.WS.RenameBranch(repo, newBranch, branch)
+				}
+				return err
+			}
+			newPath := filepath.Join(ctx.WS.RepoDir(repo), newName)
+
+			if ctx.WS.IsBoarded(repo, newName) {
+				config.SaveBoarded(ctx.WS.Root, ctx.WS.Boarded)
+				if err := ide.Regenerate(ctx.WS.Root, ctx.WS.Boarded, ctx.WS.DisplayNames, ctx.WS.Org); err != nil {
+					fmt.Fprintf(os.Stderr, "  %s workspace files: %v\n", ui.Orange.Render("⚠"), err)
+				}
+			}
+			if ctx.WS.Silo[repo] == newName {
+				config.SaveSilo(ctx.WS.Root, ctx.WS.Silo)
+			}
+			display := ctx.WS.DisplayNameFor(repo)
+			if mx := mux.Detect(); mx != nil {
+				if id, ok := mx.Windows()[mux.WindowName(display, capsule)]; ok {
+					_ = mx.RenameWindow(id, mux.WindowName(display, newName))
+				}
+			}
+			fmt.Fprintf(os.Stderr, "  %s Renamed %s %s → %s\n", ui.Green.Render("✓"), ctx.WS.FormatRepoName(repo),
+				ui.TagDim.Render(capsule), ui.TagDim.Render(newName))
+
+			if branchRenamed {
+				fmt.Fprintf(os.Stderr, "  %s Branch %s → %s\n", ui.Green.Render("✓"), branch, newBranch)
+				if pushed {
+					if err := workspace.GitPush(newPath, newBranch); err != nil {
+						return fmt.Errorf("pushing %s: %w", newBranch, err)
+					}
+					if err := workspace.GitPushDelete(newPath, branch); err != nil {
+						fmt.Fprintf(os.Stderr, "  %s deleting %s from origin: %v\n", ui.Orange.Render("⚠"), branch, err)
+					} else {
+						fmt.Fprintf(os.Stderr, "  %s Renamed on origin\n", ui.Green.Render("✓"))
+					}
+				}
+			}
+
+			// Follow the capsule if the shell is inside it.
+			if cwd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(oldPath, cwd); err == nil && !strings.HasPrefix(rel, "..") {
+					printCd(filepath.Join(newPath, rel))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&renameBranch, "branch", false, "Rename the branch too, locally and on origin")
+	return cmd
+}
+
+// confirmRenamePushed warns before renaming a pushed branch that has an open
+// PR, since GitHub closes PRs whose head branch is deleted.
+func confirmRenamePushed(ctx *Context, repo, branch string) bool {
+	prs, err := ctx.GitHub.PRsForRepo(ctx.WS.Org, repo)
+	if err != nil {
+		return true
+	}
+	for _, pr := range prs {
+		if pr.HeadRefName == branch && pr.State == "OPEN" {
+			fmt.Fprintf(os.Stderr, "  %s PR #%d is open from %s; GitHub will close it when the old branch is deleted\n",
+				ui.Orange.Render("⚠"), pr.Number, branch)
+			confirmed, err := ui.Confirm("Rename anyway?")
+			return err == nil && confirmed
+		}
+	}
+	return true
+}
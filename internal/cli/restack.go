@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newRestackCmd() *cobra.Command {
+	var cont, abort bool
+
+	cmd := &cobra.Command{
+		Use:   "restack [repo] [capsule]",
+		Short: "Rebase stacked capsules onto their parents",
+		Long: `Rebase capsules that are stacked on another capsule back on top of their
+parent, parents first. When a parent has landed, its children are moved onto
+origin/<default-branch> instead. With a capsule, only it and the capsules
+stacked on it are restacked. Use "." (or nothing) as the repo to infer from
+the current directory.
+
+If a rebase stops on a conflict, resolve it in the capsule and run
+"ws restack --continue", or give up with "ws restack --abort".
+
+Examples:
+  ws restack
+  ws restack frontend
+  ws restack frontend my-feature
+  ws restack --continue`,
+		Args: cobra.MaximumNArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return completeRepoNames(cmd, args, toComplete)
+			case 1:
+				return completeWorktreeNames(0)(cmd, args, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cont && abort {
+				return fmt.Errorf("--continue and --abort can't be used together")
+			}
+
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+
+			repoArg := "."
+			if len(args) > 0 {
+				repoArg = args[0]
+			}
+			var repo string
+			if (cont || abort) && len(args) == 0 {
+				repo, err = pendingRestackRepo(ctx)
+			} else {
+				repo, err = ctx.ResolveRepo(repoArg)
+			}
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case abort:
+				if err := ctx.WS.AbortRestack(repo); err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "  %s Restack of %s abandoned\n", ui.Green.Render("✓"), ctx.WS.FormatRepoName(repo))
+				return nil
+			case cont:
+				return restackResult(ctx, repo, ctx.WS.ContinueRestack(repo, printRestackStep(os.Stderr)))
+			}
+
+			if steps, err := ctx.WS.PendingRestack(repo); err != nil {
+				return err
+			} else if len(steps) > 0 {
+				return fmt.Errorf("a restack of %s is already in progress; use --continue or --abort", repo)
+			}
+
+			var capsule string
+			if len(args) == 2 {
+				capsule, err = ctx.ResolveCapsule(repo, args[1])
+				if err != nil {
+					return err
+				}
+			}
+			return runRestack(ctx, repo, capsule)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cont, "continue", false, "Continue after resolving a conflict")
+	cmd.Flags().BoolVar(&abort, "abort", false, "Abandon a restack stopped on a conflict")
+	return cmd
+}
+
+func runRestack(ctx *Context, repo, capsule string) error {
+	bareDir := ctx.WS.BareDir(repo)
+	fmt.Fprintf(os.Stderr, "  Fetching %s...\n", ctx.WS.FormatRepoName(repo))
+	if err := workspace.GitFetch(bareDir); err != nil {
+		fmt.Fprintf(os.Stderr, "  %s fetch failed: %v\n", ui.Orange.Render("⚠"), err)
+	}
+
+	// Squash-merged parents aren't visible to git, so ask GitHub too.
+	landed := make(map[string]bool)
+	if prs, err := ctx.GitHub.MergedPRsForRepo(ctx.WS.Org, repo); err == nil {
+		for _, pr := range prs {
+			landed[pr.HeadRefName] = true
+		}
+	}
+
+	steps, err := ctx.WS.PlanRestack(repo, capsule, landed)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Fprintf(os.Stderr, "  Nothing stacked in %s\n", ctx.WS.FormatRepoName(repo))
+		return nil
+	}
+	return restackResult(ctx, repo, ctx.WS.RunRestack(repo, steps, printRestackStep(os.Stderr)))
+}
+
+// restackResult explains how to carry on when a restack stops on a conflict.
+func restackResult(ctx *Context, repo string, err error) error {
+	if !errors.Is(err, workspace.ErrRestackConflict) {
+		return err
+	}
+	steps, _ := ctx.WS.PendingRestack(repo)
+	if len(steps) == 0 {
+		return err
+	}
+	wtPath := filepath.Join(ctx.WS.RepoDir(repo), steps[0].Capsule)
+	fmt.Fprintf(os.Stderr, "  %s %s stopped on a conflict rebasing onto %s\n",
+		ui.Red.Render("✗"), ui.TagDim.Render(steps[0].Capsule), steps[0].Onto)
+	fmt.Fprintf(os.Stderr, "    Resolve it in %s, stage the files, then run ws restack --continue\n", wtPath)
+	fmt.Fprintf(os.Stderr, "    or run ws restack --abort to give up.\n")
+	return err
+}
+
+func printRestackStep(out io.Writer) func(workspace.RestackStep, bool) {
+	return func(step workspace.RestackStep, moved bool) {
+		switch {
+		case !moved:
+			fmt.Fprintf(out, "  %s %s already on %s\n", ui.Dim.Render("·"), step.Capsule, step.Onto)
+		case step.Landed:
+			fmt.Fprintf(out, "  %s %s moved onto %s (%s landed)\n", ui.Green.Render("✓"), step.Capsule, step.Onto, step.Parent)
+		default:
+			fmt.Fprintf(out, "  %s %s restacked onto %s\n", ui.Green.Render("✓"), step.Capsule, step.Onto)
+		}
+	}
+}
+
+// pendingRestackRepo finds the repo with a restack in progress, preferring
+// the one the current directory is in.
+func pendingRestackRepo(ctx *Context) (string, error) {
+	cwd, _ := os.Getwd()
+	if repo, _, ok := workspace.DetectRepo(ctx.WS.Root, cwd); ok {
+		if steps, _ := ctx.WS.PendingRestack(repo); len(steps) > 0 {
+			return repo, nil
+		}
+	}
+	var pending []string
+	for _, repo := range ctx.WS.RepoNames {
+		if steps, _ := ctx.WS.PendingRestack(repo); len(steps) > 0 {
+			pending = append(pending, repo)
+		}
+	}
+	switch len(pending) {
+	case 0:
+		return "", fmt.Errorf("no restack in progress")
+	case 1:
+		return pending[0], nil
+	default:
+		return ui.PickRepo(pending, ctx.WS.DisplayNames)
+	}
+}
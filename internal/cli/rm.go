@@ -3,20 +3,34 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/brudil/workspace/internal/config"
 	"github.com/brudil/workspace/internal/ide"
+	"github.com/brudil/workspace/internal/mux"
 	"github.com/brudil/workspace/internal/ui"
 	"github.com/brudil/workspace/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 func newBurnCmd() *cobra.Command {
-	return &cobra.Command{
+	var kill bool
+
+	cmd := &cobra.Command{
 		Use:     "burn [repo] <branch>",
 		Aliases: []string{"rm"},
 		Short:   "Remove a worktree",
-		Args:    cobra.RangeArgs(1, 2),
+		Long: `Remove a capsule's worktree.
+
+Processes working in the capsule or holding its files open are listed first.
+Those holding files open, like dev servers and watchers, must stop before the
+capsule is removed: you're asked whether to stop them, and --kill stops them
+without asking. Processes that are only working there, like shells, are left
+running. Its tmux, Zellij or WezTerm window is closed once it's gone.`,
+		Args: cobra.RangeArgs(1, 2),
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
 			case 0:
@@ -90,6 +104,10 @@ func newBurnCmd() *cobra.Command {
 				}
 			}
 
+			if err := stopCapsuleProcesses(ctx.WS, listOpenFiles(), repo, capsule, kill); err != nil {
+				return err
+			}
+
 			if ctx.WS.IsBoarded(repo, capsule) {
 				ctx.WS.Unboard(repo, capsule)
 				config.SaveBoarded(ctx.WS.Root, ctx.WS.Boarded)
@@ -103,7 +121,101 @@ func newBurnCmd() *cobra.Command {
 			}
 
 			fmt.Fprintf(os.Stderr, "  %s Removed %s %s\n", ui.Green.Render("✓"), ctx.WS.FormatRepoName(repo), ui.TagDim.Render(capsule))
+			closeCapsuleWindow(ctx.WS, repo, capsule)
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&kill, "kill", false, "Stop processes running in the capsule without asking")
+	return cmd
+}
+
+// stopTimeout is how long stopped processes get to exit.
+const stopTimeout = 5 * time.Second
+
+// listOpenFiles takes the snapshot stopCapsuleProcesses checks capsules
+// against, warning and returning nil if lsof can't.
+func listOpenFiles() *workspace.OpenFiles {
+	files, err := workspace.ListOpenFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  %s couldn't check for running processes: %v\n", ui.Orange.Render("⚠"), err)
+		return nil
+	}
+	return files
+}
+
+// stopCapsuleProcesses lists the processes still running in a capsule that is
+// about to be removed. Those holding its files open are stopped with kill or,
+// when interactive, if the user agrees, and removal is refused while any are
+// left. Processes only working in the capsule are left running.
+func stopCapsuleProcesses(ws *workspace.Workspace, files *workspace.OpenFiles, repo, capsule string, kill bool) error {
+	if files == nil {
+		return nil
+	}
+	procs := files.CapsuleProcesses(filepath.Join(ws.RepoDir(repo), capsule))
+	if len(procs) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "  %s %s %s is in use by %s\n", ui.Orange.Render("⚠"),
+		ws.FormatRepoName(repo), ui.TagDim.Render(capsule), fmt.Sprintf("%d %s", len(procs), pluralize(len(procs), "process", "processes")))
+	for _, p := range procs {
+		fmt.Fprintf(os.Stderr, "    %s %s\n", p.Command, ui.Dim.Render(describeProcess(p)))
+	}
+	holders := slices.DeleteFunc(procs, func(p workspace.Process) bool { return !p.HoldsFiles() })
+	if len(holders) == 0 {
+		return nil
+	}
+	if !kill && ui.IsInteractive() {
+		var err error
+		if kill, err = ui.Confirm(pluralize(len(holders), "Stop the one holding files open?", "Stop the ones holding files open?")); err != nil {
+			return err
+		}
+	}
+	if !kill {
+		return fmt.Errorf("%s; stop them or use --kill", holdingFilesError(holders))
+	}
+
+	stopped, err := workspace.StopProcesses(holders, stopTimeout)
+	for _, p := range stopped {
+		fmt.Fprintf(os.Stderr, "  %s Stopped %s %s\n", ui.Green.Render("✓"), p.Command, ui.Dim.Render(fmt.Sprintf("(pid %d)", p.PID)))
+	}
+	if err != nil {
+		return err
+	}
+	if left := slices.DeleteFunc(holders, func(p workspace.Process) bool { return slices.Contains(stopped, p) }); len(left) > 0 {
+		return fmt.Errorf("still %s", holdingFilesError(left))
+	}
+	return nil
+}
+
+// holdingFilesError names the processes keeping a capsule's files open, e.g.
+// "holding files open: node (pid 4121)".
+func holdingFilesError(holders []workspace.Process) string {
+	names := make([]string, len(holders))
+	for i, p := range holders {
+		names[i] = fmt.Sprintf("%s (pid %d)", p.Command, p.PID)
+	}
+	return "holding files open: " + strings.Join(names, ", ")
+}
+
+// describeProcess says how a process is using a capsule, e.g.
+// "pid 4121, working here, 3 open files".
+func describeProcess(p workspace.Process) string {
+	parts := []string{fmt.Sprintf("pid %d", p.PID)}
+	if p.InDir {
+		parts = append(parts, "working here")
+	}
+	if p.Open > 0 {
+		parts = append(parts, countOf(p.Open, "open file"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// closeCapsuleWindow closes a removed capsule's multiplexer window.
+func closeCapsuleWindow(ws *workspace.Workspace, repo, capsule string) {
+	name := mux.WindowName(ws.DisplayNameFor(repo), capsule)
+	if mux.KillCapsuleWindow(ws.DisplayNameFor(repo), capsule) {
+		fmt.Fprintf(os.Stderr, "  %s Closed window %s\n", ui.Green.Render("✓"), name)
+	}
 }
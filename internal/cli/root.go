@@ -1,6 +1,9 @@
 package cli
 
 import (
+	"os"
+
+	"github.com/brudil/workspace/internal/github"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +16,13 @@ func NewRootCmd(version string) *cobra.Command {
 
 	cmd.Version = version
 
+	cmd.PersistentFlags().BoolVar(&offlineMode, "offline", os.Getenv("WS_OFFLINE") != "", "Serve GitHub data from the cache and skip network calls (or set WS_OFFLINE)")
+
+	// Let background cache refreshes finish writing before the process exits.
+	cmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		github.WaitForRefreshes()
+	}
+
 	// Default command: show status when no subcommand given
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		return newStatusCmd().RunE(cmd, args)
@@ -23,8 +33,14 @@ func NewRootCmd(version string) *cobra.Command {
 	cmd.AddCommand(newStatusCmd())
 	cmd.AddCommand(newLiftCmd())
 	cmd.AddCommand(newDockCmd())
+	cmd.AddCommand(newRestackCmd())
+	cmd.AddCommand(newPRCmd())
+	cmd.AddCommand(newCacheCmd())
 	cmd.AddCommand(newBurnCmd())
+	cmd.AddCommand(newRenameCmd())
+	cmd.AddCommand(newNoteCmd())
 	cmd.AddCommand(newOpenCmd())
+	cmd.AddCommand(newIDECmd())
 	cmd.AddCommand(newMCCmd())
 	cmd.AddCommand(newDebriefCmd())
 	cmd.AddCommand(newPromptCmd())
@@ -35,6 +51,7 @@ func NewRootCmd(version string) *cobra.Command {
 	cmd.AddCommand(newBoardCmd())
 	cmd.AddCommand(newUnboardCmd())
 	cmd.AddCommand(newSiloCmd())
+	cmd.AddCommand(newTmuxCmd())
 
 	return cmd
 }
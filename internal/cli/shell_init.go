@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -14,13 +15,47 @@ func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
-const zshWrapper = `# ws — shell wrapper for workspace
-# Generated by: workspace shell-init zsh
-# Add to ~/.zshrc: eval "$(workspace shell-init zsh)"
+// fishQuote returns a fish single-quoted string, in which only \ and ' are
+// escaped.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// shellEnv is set by the fish and nushell wrappers to the shell they run in,
+// so the line printed to change directory is one that shell understands.
+const shellEnv = "WS_SHELL"
+
+// printCd prints what the ws wrapper runs to change directory to path.
+func printCd(path string) {
+	fmt.Println(cdLine(os.Getenv(shellEnv), path))
+}
+
+// cdLine is the line printCd prints for shell. Nushell can't evaluate text,
+// so its wrapper is given the bare path to cd to.
+func cdLine(shell, path string) string {
+	switch shell {
+	case "fish":
+		return "cd " + fishQuote(path)
+	case "nu":
+		return path
+	default:
+		return "cd " + shellQuote(path)
+	}
+}
+
+// cdCommands are the commands whose output the wrappers run, as they may
+// change directory.
+var cdCommands = []string{"jump", "j", "lift", "dock", "rename", "init", "mc"}
+
+// posixWrapper is the ws() function for zsh and bash.
+func posixWrapper(shell, rc string) string {
+	return fmt.Sprintf(`# ws — shell wrapper for workspace
+# Generated by: workspace shell-init %[1]s
+# Add to %[2]s: eval "$(workspace shell-init %[1]s)"
 
 ws() {
   case "${1:-}" in
-    jump|j|lift|dock|init|mc)
+    %[3]s)
       eval "$(command workspace "$@")"
       ;;
     *)
@@ -29,7 +64,63 @@ ws() {
   esac
 }
 
-`
+`, shell, rc, strings.Join(cdCommands, "|"))
+}
+
+func fishWrapper() string {
+	return fmt.Sprintf(`# ws — shell wrapper for workspace
+# Generated by: workspace shell-init fish
+# Add to ~/.config/fish/config.fish: workspace shell-init fish | source
+
+function ws --wraps workspace --description 'workspace shell wrapper'
+    switch "$argv[1]"
+        case %s
+            set -l out (env %s=fish workspace $argv)
+            set -l code $status
+            string join \n -- $out | source
+            return $code
+        case '*'
+            command workspace $argv
+    end
+end
+
+`, strings.Join(cdCommands, " "), shellEnv)
+}
+
+// nuInit is the ws command for nushell, with completions asked of
+// workspace __complete, since cobra doesn't generate nushell completions.
+func nuInit() string {
+	return fmt.Sprintf(`# ws — shell wrapper for workspace
+# Generated by: workspace shell-init nu
+# Nushell can't evaluate generated code, so save it where it's loaded from:
+#   workspace shell-init nu | save -f ($nu.default-config-dir | path join "ws.nu")
+# and add to config.nu: source ws.nu
+
+def "nu-complete ws" [context: string] {
+    let words = ($context | split row -r '\s+' | skip 1)
+    ^workspace __complete ...$words
+        | lines
+        | where {|line| not ($line | str starts-with ":") }
+        | each {|line|
+            let parts = ($line | split row "\t")
+            {value: $parts.0, description: ($parts | get 1? | default "")}
+        }
+}
+
+def --env --wrapped ws [...args: string@"nu-complete ws"] {
+    if ($args | is-not-empty) and ($args.0 in [%s]) {
+        let dir = (with-env {%s: nu} { ^workspace ...$args } | str trim)
+        if ($dir | is-not-empty) {
+            cd $dir
+        }
+    } else {
+        ^workspace ...$args
+    }
+}
+`, strings.Join(cdCommands, " "), shellEnv)
+}
+
+var shellInitShells = []string{"zsh", "bash", "fish", "nu"}
 
 func newShellInitCmd() *cobra.Command {
 	return &cobra.Command{
@@ -38,9 +129,18 @@ func newShellInitCmd() *cobra.Command {
 		Long: `Outputs a shell script that defines the ws() wrapper function and
 sets up tab completions. Source this in your shell's rc file:
 
-  eval "$(workspace shell-init zsh)"`,
+  eval "$(workspace shell-init zsh)"         # ~/.zshrc
+  eval "$(workspace shell-init bash)"        # ~/.bashrc
+  workspace shell-init fish | source         # ~/.config/fish/config.fish
+
+Nushell can't evaluate generated code, so save its script and source it from
+config.nu:
+
+  workspace shell-init nu | save -f ($nu.default-config-dir | path join "ws.nu")
+
+Shells: ` + strings.Join(shellInitShells, ", "),
 		Args:              cobra.ExactArgs(1),
-		ValidArgs:         []string{"zsh"},
+		ValidArgs:         shellInitShells,
 		ValidArgsFunction: cobra.NoFileCompletions,
 		SilenceErrors:     true,
 		SilenceUsage:      true,
@@ -50,8 +150,15 @@ sets up tab completions. Source this in your shell's rc file:
 			switch shell {
 			case "zsh":
 				return outputZshInit(cmd)
+			case "bash":
+				return outputBashInit(cmd)
+			case "fish":
+				return outputFishInit(cmd)
+			case "nu", "nushell":
+				fmt.Fprint(cmd.OutOrStdout(), nuInit())
+				return nil
 			default:
-				return fmt.Errorf("unsupported shell %q (supported: zsh)", shell)
+				return fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(shellInitShells, ", "))
 			}
 		},
 	}
@@ -61,7 +168,7 @@ func outputZshInit(cmd *cobra.Command) error {
 	out := cmd.OutOrStdout()
 
 	// Write the ws() wrapper function
-	fmt.Fprint(out, zshWrapper)
+	fmt.Fprint(out, posixWrapper("zsh", "~/.zshrc"))
 
 	// Generate completions for workspace, then alias to ws
 	var buf bytes.Buffer
@@ -74,3 +181,33 @@ func outputZshInit(cmd *cobra.Command) error {
 
 	return nil
 }
+
+func outputBashInit(cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+	fmt.Fprint(out, posixWrapper("bash", "~/.bashrc"))
+
+	// The completion function asks whichever command it completes, so ws
+	// goes through the wrapper to workspace __complete.
+	var buf bytes.Buffer
+	if err := cmd.Root().GenBashCompletionV2(&buf, true); err != nil {
+		return fmt.Errorf("generating completions: %w", err)
+	}
+	fmt.Fprint(out, buf.String())
+	fmt.Fprintln(out, "complete -o default -F __start_workspace ws")
+
+	return nil
+}
+
+func outputFishInit(cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+
+	// ws --wraps workspace, so it gets these completions too.
+	fmt.Fprint(out, fishWrapper())
+	var buf bytes.Buffer
+	if err := cmd.Root().GenFishCompletion(&buf, true); err != nil {
+		return fmt.Errorf("generating completions: %w", err)
+	}
+	fmt.Fprint(out, buf.String())
+
+	return nil
+}
@@ -2,6 +2,9 @@ package cli
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -48,7 +51,7 @@ func TestShellInitZsh(t *testing.T) {
 
 func TestShellInitUnsupportedShell(t *testing.T) {
 	cmd := NewRootCmd("test")
-	cmd.SetArgs([]string{"shell-init", "fish"})
+	cmd.SetArgs([]string{"shell-init", "tcsh"})
 
 	err := cmd.Execute()
 	if err == nil {
@@ -68,3 +71,91 @@ func TestShellInitNoArgs(t *testing.T) {
 		t.Fatal("expected error when no shell specified, got nil")
 	}
 }
+
+func TestCdLine(t *testing.T) {
+	path := `/ws/it's a \dir`
+	tests := map[string]string{
+		"":     `cd '/ws/it'\''s a \dir'`,
+		"fish": `cd '/ws/it\'s a \\dir'`,
+		"nu":   path,
+	}
+	for shell, want := range tests {
+		if got := cdLine(shell, path); got != want {
+			t.Errorf("cdLine(%q) = %s, want %s", shell, got, want)
+		}
+	}
+}
+
+// TestShellInit_Wrappers sources each shell's init script with a stand-in
+// workspace binary on PATH, and checks ws forwards its arguments and changes
+// directory after lift. Shells that aren't installed are skipped.
+func TestShellInit_Wrappers(t *testing.T) {
+	shells := []struct {
+		name, env string
+		args      []string
+		prelude   string
+	}{
+		{name: "bash", args: []string{"--norc", "--noprofile"}},
+		{name: "zsh", args: []string{"-f"}, prelude: "autoload -U compinit; compinit -u\n"},
+		{name: "fish", env: "fish", args: []string{"--no-config"}},
+		{name: "nu", env: "nu", args: []string{"--no-config-file"}},
+	}
+	for _, sh := range shells {
+		t.Run(sh.name, func(t *testing.T) {
+			shellPath, err := exec.LookPath(sh.name)
+			if err != nil {
+				t.Skipf("%s not installed", sh.name)
+			}
+			tmp := t.TempDir()
+			target := filepath.Join(tmp, "it's a capsule")
+			os.Mkdir(target, 0755)
+
+			cmd := NewRootCmd("test")
+			init := new(bytes.Buffer)
+			cmd.SetOut(init)
+			cmd.SetArgs([]string{"shell-init", sh.name})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("shell-init %s failed: %v", sh.name, err)
+			}
+			initPath := filepath.Join(tmp, "init")
+			os.WriteFile(initPath, init.Bytes(), 0644)
+
+			// The stand-in logs what it was given and prints what lift would.
+			bin := filepath.Join(tmp, "bin")
+			os.Mkdir(bin, 0755)
+			logPath := filepath.Join(tmp, "log")
+			cdPath := filepath.Join(tmp, "cd")
+			os.WriteFile(cdPath, []byte(cdLine(sh.env, target)+"\n"), 0644)
+			fake := "#!/bin/sh\nprintf '%s\\n' \"[${WS_SHELL:-}]\" \"$@\" >> " + shellQuote(logPath) + "\n" +
+				"case \"$1\" in lift) cat " + shellQuote(cdPath) + " ;; *) echo passed-through ;; esac\n"
+			os.WriteFile(filepath.Join(bin, "workspace"), []byte(fake), 0755)
+
+			script := filepath.Join(tmp, "script")
+			os.WriteFile(script, []byte(sh.prelude+"source '"+initPath+"'\n"+
+				"ws status --json\n"+
+				"ws lift repo-a 'a b'\n"+
+				"pwd\n"), 0644)
+
+			run := exec.Command(shellPath, append(sh.args, script)...)
+			run.Dir = tmp
+			run.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+			out, err := run.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%s failed: %v\n%s", sh.name, err, out)
+			}
+
+			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+			if lines[0] != "passed-through" {
+				t.Errorf("status output = %q, want passed-through\n%s", lines[0], out)
+			}
+			if got := lines[len(lines)-1]; got != target {
+				t.Errorf("pwd after lift = %q, want %q\n%s", got, target, out)
+			}
+			log, _ := os.ReadFile(logPath)
+			want := "[]\nstatus\n--json\n[" + sh.env + "]\nlift\nrepo-a\na b\n"
+			if string(log) != want {
+				t.Errorf("workspace got:\n%s\nwant:\n%s", log, want)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/brudil/workspace/internal/config"
@@ -62,56 +65,11 @@ func newSiloPointCmd() *cobra.Command {
 				}
 			}
 
-			siloDir := ctx.WS.SiloWorktree(repo)
-			capsuleDir := filepath.Join(ctx.WS.RepoDir(repo), capsule)
-
-			// Create .silo/ worktree if doesn't exist (detached HEAD to avoid branch conflicts)
-			if _, err := os.Stat(siloDir); os.IsNotExist(err) {
-				fmt.Fprintf(os.Stderr, "  Creating silo for %s...\n", ctx.WS.FormatRepoName(repo))
-				bareDir := ctx.WS.BareDir(repo)
-				if err := workspace.GitWorktreeAddDetached(bareDir, siloDir, ctx.WS.DefaultBranch); err != nil {
-					return fmt.Errorf("creating silo worktree: %w", err)
-				}
-			}
-
-			// Update silo state
-			if ctx.WS.Silo == nil {
-				ctx.WS.Silo = make(map[string]string)
-			}
-			ctx.WS.Silo[repo] = capsule
-			if err := config.SaveSilo(ctx.WS.Root, ctx.WS.Silo); err != nil {
-				return fmt.Errorf("saving silo state: %w", err)
-			}
-
-			// Full sync
-			fmt.Fprintf(os.Stderr, "  Syncing %s -> .silo...\n", capsule)
-			if _, err := workspace.FullSync(capsuleDir, siloDir); err != nil {
-				return fmt.Errorf("syncing: %w", err)
-			}
-
-			// Run after_create hook (precedence: ws.local.toml > ws.toml > ws.repo.toml)
-			hook, hasHook := ctx.WS.AfterCreateHooks[repo]
-			repoConfigPath := filepath.Join(ctx.WS.MainWorktree(repo), config.RepoFileName)
-			repoCfg, err := config.ParseRepoConfig(repoConfigPath)
-			if err != nil {
-				return err
-			}
-			if !hasHook && repoCfg != nil && repoCfg.Capsule.AfterCreate != "" {
-				hook = repoCfg.Capsule.AfterCreate
-				hasHook = true
-			}
-			if hasHook {
-				fmt.Fprintf(os.Stderr, "  Running after_create hook...\n")
-				if err := workspace.RunHook(siloDir, hook, os.Stderr, os.Stderr); err != nil {
-					fmt.Fprintf(os.Stderr, "  %s after_create hook failed: %v\n", ui.Orange.Render("⚠"), err)
-				}
-			}
-
-			// Run after_switch hook from ws.repo.toml
-			if repoCfg != nil && repoCfg.Silo.AfterSwitch != "" {
-				fmt.Fprintf(os.Stderr, "  Running after_switch hook...\n")
-				if err := workspace.RunHook(siloDir, repoCfg.Silo.AfterSwitch, os.Stderr, os.Stderr); err != nil {
-					fmt.Fprintf(os.Stderr, "  %s after_switch hook failed: %v\n", ui.Orange.Render("⚠"), err)
+			if err := pointSilo(ctx.WS, repo, capsule, os.Stderr); err != nil {
+				// Failed hooks have been reported, and the silo still moved.
+				var hookErr *siloHookError
+				if !errors.As(err, &hookErr) {
+					return err
 				}
 			}
 
@@ -122,6 +80,97 @@ func newSiloPointCmd() *cobra.Command {
 	}
 }
 
+// pointSilo points repo's silo at capsule: it creates the .silo worktree if
+// needed, records the new target, syncs the capsule across and runs the
+// after_create and after_switch hooks. Progress and hook output go to out.
+func pointSilo(ws *workspace.Workspace, repo, capsule string, out io.Writer) error {
+	siloDir := ws.SiloWorktree(repo)
+	capsuleDir := filepath.Join(ws.RepoDir(repo), capsule)
+
+	// Create .silo/ worktree if doesn't exist (detached HEAD to avoid branch conflicts)
+	if _, err := os.Stat(siloDir); os.IsNotExist(err) {
+		fmt.Fprintf(out, "  Creating silo for %s...\n", ws.FormatRepoName(repo))
+		bareDir := ws.BareDir(repo)
+		if err := workspace.GitWorktreeAddDetached(bareDir, siloDir, ws.DefaultBranch); err != nil {
+			return fmt.Errorf("creating silo worktree: %w", err)
+		}
+	}
+
+	// Update silo state
+	if ws.Silo == nil {
+		ws.Silo = make(map[string]string)
+	}
+	ws.Silo[repo] = capsule
+	if err := config.SaveSilo(ws.Root, ws.Silo); err != nil {
+		return fmt.Errorf("saving silo state: %w", err)
+	}
+
+	// Full sync
+	fmt.Fprintf(out, "  Syncing %s -> .silo...\n", capsule)
+	if _, err := workspace.FullSync(capsuleDir, siloDir); err != nil {
+		return fmt.Errorf("syncing: %w", err)
+	}
+
+	// Run after_create hook (precedence: ws.local.toml > ws.toml > ws.repo.toml)
+	hook, hasHook := ws.AfterCreateHooks[repo]
+	repoConfigPath := filepath.Join(ws.MainWorktree(repo), config.RepoFileName)
+	repoCfg, err := config.ParseRepoConfig(repoConfigPath)
+	if err != nil {
+		return err
+	}
+	if !hasHook && repoCfg != nil && repoCfg.Capsule.AfterCreate != "" {
+		hook = repoCfg.Capsule.AfterCreate
+		hasHook = true
+	}
+	// A failing hook doesn't undo the switch, but is returned once the
+	// rest have run so callers that discard out still hear of it.
+	var hookErrs []error
+	if hasHook {
+		if err := runSiloHook(siloDir, "after_create", hook, out); err != nil {
+			fmt.Fprintf(out, "  %s %v\n", ui.Orange.Render("⚠"), err)
+			hookErrs = append(hookErrs, err)
+		}
+	}
+
+	// Run after_switch hook from ws.repo.toml
+	if repoCfg != nil && repoCfg.Silo.AfterSwitch != "" {
+		if err := runSiloHook(siloDir, "after_switch", repoCfg.Silo.AfterSwitch, out); err != nil {
+			fmt.Fprintf(out, "  %s %v\n", ui.Orange.Render("⚠"), err)
+			hookErrs = append(hookErrs, err)
+		}
+	}
+	return errors.Join(hookErrs...)
+}
+
+// siloHookError is a silo hook failing after the silo has been pointed.
+type siloHookError struct {
+	hook string
+	err  error
+	last string // the last line the hook printed
+}
+
+func (e *siloHookError) Error() string {
+	msg := e.hook + " hook failed: " + e.err.Error()
+	if e.last != "" {
+		msg += " (" + e.last + ")"
+	}
+	return msg
+}
+
+func (e *siloHookError) Unwrap() error { return e.err }
+
+// runSiloHook runs one of a silo's hooks in dir, with its output going to out.
+func runSiloHook(dir, name, hook string, out io.Writer) error {
+	fmt.Fprintf(out, "  Running %s hook...\n", name)
+	var output bytes.Buffer
+	w := io.MultiWriter(out, &output)
+	if err := workspace.RunHook(dir, hook, w, w); err != nil {
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		return &siloHookError{hook: name, err: err, last: strings.TrimSpace(lines[len(lines)-1])}
+	}
+	return nil
+}
+
 func newSiloStopCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "stop <repo>",
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/ui"
@@ -16,7 +17,7 @@ import (
 )
 
 func newStatusCmd() *cobra.Command {
-	var format string
+	var format, tag string
 
 	cmd := &cobra.Command{
 		Use:               "status",
@@ -28,14 +29,15 @@ func newStatusCmd() *cobra.Command {
 				return err
 			}
 
+			outlines := statusOutlines(ctx.WS, tag)
 			switch format {
 			case "json":
-				return runStatusJSON(ctx.WS, ctx.GitHub)
+				return runStatusJSON(ctx.WS, ctx.GitHub, outlines)
 			case "llm":
-				return runStatusLLM(ctx.WS, ctx.GitHub)
+				return runStatusLLM(ctx.WS, ctx.GitHub, outlines)
 			}
 
-			m := newStatusModel(ctx.WS, ctx.GitHub)
+			m := newStatusModel(ctx.WS, ctx.GitHub, outlines)
 			p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
 			_, err = p.Run()
 			return err
@@ -43,6 +45,7 @@ func newStatusCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&format, "format", "f", "", "Output format: json, llm")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only show capsules with this tag")
 	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return []string{"json", "llm"}, cobra.ShellCompDirectiveNoFileComp
 	})
@@ -50,6 +53,16 @@ func newStatusCmd() *cobra.Command {
 	return cmd
 }
 
+// statusOutlines lists the repos and capsules to show, only those tagged
+// tag when it's set.
+func statusOutlines(ws *workspace.Workspace, tag string) []workspace.RepoOutline {
+	outlines := ws.StatusOutline(false)
+	if tag != "" {
+		outlines = ws.FilterOutlinesByTag(outlines, strings.TrimPrefix(tag, "#"))
+	}
+	return outlines
+}
+
 // --- bubbletea model ---
 
 type worktreeView struct {
@@ -68,7 +81,11 @@ type repoView struct {
 	err        error
 	prs        map[string]*github.PR // headRefName → PR, nil until loaded
 	prsLoaded  bool
-	siloTarget string // non-empty when silo is configured for this repo
+	prsAsOf    time.Time                        // set when the PRs came from the cache offline
+	issues     map[string]*github.Issue         // branch → issue the capsule was lifted from
+	prGroups   map[string]string                // branch → group recorded with "ws pr link"
+	metas      map[string]workspace.CapsuleMeta // capsule → note and tags
+	siloTarget string                           // non-empty when silo is configured for this repo
 }
 
 type statusModel struct {
@@ -90,13 +107,17 @@ type wtStatusMsg struct {
 
 // message sent when PR data for a repo is ready
 type repoPRsMsg struct {
-	repo string
-	prs  []github.PR
-	err  error
+	repo   string
+	prs    []github.PR
+	err    error
+	asOf   time.Time
+	issues map[string]*github.Issue
 }
 
-func newStatusModel(ws *workspace.Workspace, gh github.Client) statusModel {
-	outlines := ws.StatusOutline(false)
+// repoPRsBatchMsg carries the PRs of every repo from one batched query.
+type repoPRsBatchMsg []repoPRsMsg
+
+func newStatusModel(ws *workspace.Workspace, gh github.Client, outlines []workspace.RepoOutline) statusModel {
 	repos := make([]repoView, len(outlines))
 	total := 0
 	prTotal := 0
@@ -115,6 +136,8 @@ func newStatusModel(ws *workspace.Workspace, gh github.Client) statusModel {
 			siloTarget: ws.Silo[o.Name],
 		}
 		if o.Err == nil {
+			repos[i].prGroups = workspace.PRGroups(ws.BareDir(o.Name))
+			repos[i].metas = ws.CapsuleMetas(o.Name)
 			prTotal++
 		}
 	}
@@ -123,8 +146,9 @@ func newStatusModel(ws *workspace.Workspace, gh github.Client) statusModel {
 }
 
 func (m statusModel) Init() tea.Cmd {
-	// Fire off one command per worktree + one PR query per repo
+	// Fire off one command per worktree + one batched PR query
 	var cmds []tea.Cmd
+	var repos []string
 	for _, repo := range m.repos {
 		if repo.err != nil {
 			continue
@@ -132,7 +156,10 @@ func (m statusModel) Init() tea.Cmd {
 		for _, wt := range repo.worktrees {
 			cmds = append(cmds, m.queryWorktree(repo.name, wt.name))
 		}
-		cmds = append(cmds, m.queryRepoPRs(repo.name))
+		repos = append(repos, repo.name)
+	}
+	if len(repos) > 0 {
+		cmds = append(cmds, m.queryPRs(repos))
 	}
 	return tea.Batch(cmds...)
 }
@@ -146,11 +173,42 @@ func (m statusModel) queryWorktree(repo, wt string) tea.Cmd {
 	}
 }
 
-func (m statusModel) queryRepoPRs(repoName string) tea.Cmd {
+func (m statusModel) queryPRs(repos []string) tea.Cmd {
 	org := m.ws.Org
 	return func() tea.Msg {
-		prs, err := m.gh.PRsForRepo(org, repoName)
-		return repoPRsMsg{repo: repoName, prs: prs, err: err}
+		results := m.gh.PRsForRepos(org, repos)
+		batch := make(repoPRsBatchMsg, len(repos))
+		for i, repoName := range repos {
+			r := results[repoName]
+			prs := r.Open
+			if r.Err == nil {
+				prs = withRecordedPRs(m.ws, m.gh, repoName, prs)
+			}
+			batch[i] = repoPRsMsg{repo: repoName, prs: prs, err: r.Err, asOf: r.AsOf, issues: capsuleIssues(m.ws, m.gh, repoName)}
+		}
+		return batch
+	}
+}
+
+// applyRepoPRs stores one repo's PRs.
+func (m *statusModel) applyRepoPRs(msg repoPRsMsg) {
+	m.prDone++
+	for i := range m.repos {
+		if m.repos[i].name != msg.repo {
+			continue
+		}
+		if msg.err == nil {
+			m.repos[i].prs = make(map[string]*github.PR, len(msg.prs))
+			for j := range msg.prs {
+				m.repos[i].prs[msg.prs[j].HeadRefName] = &msg.prs[j]
+			}
+		} else {
+			m.prErrors++
+		}
+		m.repos[i].prsLoaded = true
+		m.repos[i].prsAsOf = msg.asOf
+		m.repos[i].issues = msg.issues
+		break
 	}
 }
 
@@ -180,21 +238,15 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case repoPRsMsg:
-		m.prDone++
-		for i := range m.repos {
-			if m.repos[i].name != msg.repo {
-				continue
-			}
-			if msg.err == nil {
-				m.repos[i].prs = make(map[string]*github.PR, len(msg.prs))
-				for j := range msg.prs {
-					m.repos[i].prs[msg.prs[j].HeadRefName] = &msg.prs[j]
-				}
-			} else {
-				m.prErrors++
-			}
-			m.repos[i].prsLoaded = true
-			break
+		m.applyRepoPRs(msg)
+		if m.done >= m.total && m.prDone >= m.prTotal {
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case repoPRsBatchMsg:
+		for _, r := range msg {
+			m.applyRepoPRs(r)
 		}
 		if m.done >= m.total && m.prDone >= m.prTotal {
 			return m, tea.Quit
@@ -247,6 +299,14 @@ func (m statusModel) View() string {
 
 	rule := strings.Repeat("─", maxHeaderWidth)
 
+	prsByRepo := make(map[string]map[string]*github.PR, len(m.repos))
+	groups := make(map[string]map[string]string, len(m.repos))
+	for _, repo := range m.repos {
+		prsByRepo[repo.name] = repo.prs
+		groups[repo.name] = repo.prGroups
+	}
+	links := linkPRs(prsByRepo, groups)
+
 	for i, repo := range m.repos {
 		borderColor := repoColors[i]
 
@@ -281,11 +341,30 @@ func (m statusModel) View() string {
 			if repo.prs != nil {
 				pr = lookupPR(repo.prs, wt.branch, wt.name)
 			}
-			lines = append(lines, formatWorktreeLine(wt, slices.Contains(repo.boarded, wt.name), cols, pr))
+			line := formatWorktreeLine(wt, slices.Contains(repo.boarded, wt.name), cols, pr)
+			if pr != nil && wt.loaded {
+				if linked := formatLinkedPRs(m.ws, links.related(groups, repo.name, pr.HeadRefName)); linked != "" {
+					line += " " + linked
+				}
+			}
+			if issue := repo.issues[wt.branch]; issue != nil && wt.loaded {
+				line += " " + formatIssue(issue)
+			}
+			meta := repo.metas[wt.name]
+			if len(meta.Tags) > 0 {
+				line += " " + formatTags(meta.Tags)
+			}
+			lines = append(lines, line)
+			if meta.Note != "" {
+				lines = append(lines, "    "+ui.Dim.Render("✎ "+noteSummary(meta.Note)))
+			}
 		}
 		if repo.siloTarget != "" {
 			lines = append(lines, ui.Dim.Render("silo → "+repo.siloTarget))
 		}
+		if !repo.prsAsOf.IsZero() {
+			lines = append(lines, ui.Dim.Render("PRs as of "+timeAgo(repo.prsAsOf)))
+		}
 		b.WriteString(renderRepoBlock(lines, 1, borderColor) + "\n\n")
 	}
 
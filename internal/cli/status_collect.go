@@ -3,6 +3,7 @@ package cli
 import (
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
@@ -11,6 +12,10 @@ import (
 type statusData struct {
 	statuses  [][]workspace.WorktreeStatus
 	prsByRepo map[string]map[string]*github.PR
+	prGroups  map[string]map[string]string                // repo → branch → group
+	prsAsOf   map[string]time.Time                        // repo → when its cached PRs were fetched, offline
+	issues    map[string]map[string]*github.Issue         // repo → branch → issue the capsule was lifted from
+	metas     map[string]map[string]workspace.CapsuleMeta // repo → capsule → note and tags
 }
 
 func collectStatusData(ws *workspace.Workspace, gh github.Client, outlines []workspace.RepoOutline) statusData {
@@ -20,14 +25,31 @@ func collectStatusData(ws *workspace.Workspace, gh github.Client, outlines []wor
 	result := statusData{
 		statuses:  make([][]workspace.WorktreeStatus, len(outlines)),
 		prsByRepo: make(map[string]map[string]*github.PR),
+		prGroups:  make(map[string]map[string]string),
+		prsAsOf:   make(map[string]time.Time),
+		issues:    make(map[string]map[string]*github.Issue),
+		metas:     make(map[string]map[string]workspace.CapsuleMeta),
 	}
 
+	var repos []string
 	for i, o := range outlines {
 		if o.Err != nil {
 			continue
 		}
 
+		repos = append(repos, o.Name)
 		result.statuses[i] = make([]workspace.WorktreeStatus, len(o.Worktrees))
+		result.prGroups[o.Name] = workspace.PRGroups(ws.BareDir(o.Name))
+		result.metas[o.Name] = ws.CapsuleMetas(o.Name)
+
+		wg.Add(1)
+		go func(repoName string) {
+			defer wg.Done()
+			issues := capsuleIssues(ws, gh, repoName)
+			mu.Lock()
+			result.issues[repoName] = issues
+			mu.Unlock()
+		}(o.Name)
 
 		for j, wtName := range o.Worktrees {
 			wg.Add(1)
@@ -41,22 +63,29 @@ func collectStatusData(ws *workspace.Workspace, gh github.Client, outlines []wor
 			}(i, j, o.Name, wtName)
 		}
 
-		wg.Add(1)
-		go func(repoName string) {
-			defer wg.Done()
-			prs, err := gh.PRsForRepo(ws.Org, repoName)
-			if err != nil {
-				return
+	}
+
+	// One batched query covers every repo's PRs.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for repoName, r := range gh.PRsForRepos(ws.Org, repos) {
+			if r.Err != nil {
+				continue
 			}
+			prs := withRecordedPRs(ws, gh, repoName, r.Open)
 			m := make(map[string]*github.PR, len(prs))
 			for k := range prs {
 				m[prs[k].HeadRefName] = &prs[k]
 			}
 			mu.Lock()
 			result.prsByRepo[repoName] = m
+			if !r.AsOf.IsZero() {
+				result.prsAsOf[repoName] = r.AsOf
+			}
 			mu.Unlock()
-		}(o.Name)
-	}
+		}
+	}()
 
 	wg.Wait()
 	return result
@@ -3,6 +3,7 @@ package cli
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
@@ -20,29 +21,53 @@ type repoJSON struct {
 	Boarded    []string       `json:"boarded"`
 	Error      string         `json:"error,omitempty"`
 	SiloTarget string         `json:"silo_target,omitempty"`
+	PRsAsOf    string         `json:"prs_as_of,omitempty"`
 	Worktrees  []worktreeJSON `json:"worktrees"`
 }
 
 type worktreeJSON struct {
-	Name   string  `json:"name"`
-	Branch string  `json:"branch"`
-	Dirty  bool    `json:"dirty"`
-	Ahead  int     `json:"ahead"`
-	Behind int     `json:"behind"`
-	PR     *prJSON `json:"pr,omitempty"`
+	Name   string     `json:"name"`
+	Branch string     `json:"branch"`
+	Dirty  bool       `json:"dirty"`
+	Ahead  int        `json:"ahead"`
+	Behind int        `json:"behind"`
+	PR     *prJSON    `json:"pr,omitempty"`
+	Issue  *issueJSON `json:"issue,omitempty"`
+	Meta   *metaJSON  `json:"meta,omitempty"`
+}
+
+type metaJSON struct {
+	Note      string   `json:"note,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	CreatedBy string   `json:"created_by,omitempty"`
+	Base      string   `json:"base,omitempty"`
+}
+
+type issueJSON struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	State  string `json:"state"`
+	URL    string `json:"url"`
 }
 
 type prJSON struct {
-	Number         int    `json:"number"`
-	Title          string `json:"title"`
-	State          string `json:"state"`
-	URL            string `json:"url"`
-	ReviewDecision string `json:"review_decision"`
-	CheckStatus    string `json:"check_status"`
+	Number         int            `json:"number"`
+	Title          string         `json:"title"`
+	State          string         `json:"state"`
+	URL            string         `json:"url"`
+	ReviewDecision string         `json:"review_decision"`
+	CheckStatus    string         `json:"check_status"`
+	Linked         []linkedPRJSON `json:"linked,omitempty"`
+}
+
+type linkedPRJSON struct {
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
 }
 
-func runStatusJSON(ws *workspace.Workspace, gh github.Client) error {
-	outlines := ws.StatusOutline(false)
+func runStatusJSON(ws *workspace.Workspace, gh github.Client, outlines []workspace.RepoOutline) error {
 
 	result := statusJSON{
 		Workspace: ws.Title(),
@@ -78,10 +103,24 @@ func runStatusJSON(ws *workspace.Workspace, gh github.Client) error {
 				Ahead:  st.Ahead,
 				Behind: st.Behind,
 			}
+			if issue := data.issues[o.Name][st.Branch]; issue != nil {
+				result.Repos[i].Worktrees[j].Issue = &issueJSON{Number: issue.Number, Title: issue.Title, State: issue.State, URL: issue.URL}
+			}
+			if meta, ok := data.metas[o.Name][st.Name]; ok {
+				mj := &metaJSON{Note: meta.Note, Tags: meta.Tags, CreatedBy: meta.CreatedBy, Base: meta.Base}
+				if !meta.CreatedAt.IsZero() {
+					mj.CreatedAt = meta.CreatedAt.Format(time.RFC3339)
+				}
+				result.Repos[i].Worktrees[j].Meta = mj
+			}
 		}
 	}
 
+	links := linkPRs(data.prsByRepo, data.prGroups)
 	for i := range result.Repos {
+		if asOf, ok := data.prsAsOf[result.Repos[i].Name]; ok {
+			result.Repos[i].PRsAsOf = asOf.Format(time.RFC3339)
+		}
 		prs := data.prsByRepo[result.Repos[i].Name]
 		if prs == nil {
 			continue
@@ -97,6 +136,9 @@ func runStatusJSON(ws *workspace.Workspace, gh github.Client) error {
 					ReviewDecision: pr.ReviewDecision,
 					CheckStatus:    pr.StatusRollup,
 				}
+				for _, l := range links.related(data.prGroups, result.Repos[i].Name, pr.HeadRefName) {
+					wt.PR.Linked = append(wt.PR.Linked, linkedPRJSON{Repo: l.repo, Number: l.pr.Number, URL: l.pr.URL})
+				}
 			}
 		}
 	}
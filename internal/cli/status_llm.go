@@ -6,13 +6,13 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
 )
 
-func runStatusLLM(ws *workspace.Workspace, gh github.Client) error {
-	outlines := ws.StatusOutline(false)
+func runStatusLLM(ws *workspace.Workspace, gh github.Client, outlines []workspace.RepoOutline) error {
 
 	reverseAliases := make(map[string][]string)
 	for alias, canonical := range ws.AliasMap {
@@ -28,6 +28,8 @@ func runStatusLLM(ws *workspace.Workspace, gh github.Client) error {
 		name   string
 		status workspace.WorktreeStatus
 		pr     *github.PR
+		issue  *github.Issue
+		meta   workspace.CapsuleMeta
 	}
 	type llmRepoEntry struct {
 		outline   workspace.RepoOutline
@@ -42,7 +44,7 @@ func runStatusLLM(ws *workspace.Workspace, gh github.Client) error {
 			if data.statuses[i] != nil {
 				st = data.statuses[i][j]
 			}
-			wts[j] = wtEntry{name: wtName, status: st}
+			wts[j] = wtEntry{name: wtName, status: st, issue: data.issues[o.Name][st.Branch], meta: data.metas[o.Name][wtName]}
 		}
 
 		prs := data.prsByRepo[o.Name]
@@ -74,11 +76,26 @@ func runStatusLLM(ws *workspace.Workspace, gh github.Client) error {
 
 		b.WriteString(formatLLMRepoHeader(name, ws.DisplayNames[name], reverseAliases[name]))
 		b.WriteByte('\n')
+		if asOf, ok := data.prsAsOf[name]; ok {
+			b.WriteString(fmt.Sprintf("  (offline: PRs cached as of %s)\n", asOf.Format(time.RFC3339)))
+		}
 
 		for _, wt := range repo.worktrees {
 			b.WriteString("  ")
 			b.WriteString(formatLLMWorktreeLine(wt.name, wt.status, wt.pr, slices.Contains(repo.outline.Boarded, wt.name)))
+			if wt.issue != nil {
+				b.WriteString(fmt.Sprintf(" issue #%d %q %s", wt.issue.Number, wt.issue.Title, wt.issue.State))
+			}
+			if len(wt.meta.Tags) > 0 {
+				b.WriteString(" tags:" + strings.Join(wt.meta.Tags, ","))
+			}
+			if origin := formatOrigin(wt.meta); origin != "" {
+				b.WriteString(" (" + origin + ")")
+			}
 			b.WriteByte('\n')
+			if wt.meta.Note != "" {
+				b.WriteString(fmt.Sprintf("    note: %q\n", wt.meta.Note))
+			}
 		}
 
 		if target, ok := ws.Silo[name]; ok {
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/brudil/workspace/internal/github"
 	"github.com/brudil/workspace/internal/workspace"
@@ -151,6 +153,48 @@ func TestStatusUpdate_RepoPRsMsg_Error(t *testing.T) {
 	}
 }
 
+func TestStatusUpdate_RepoPRsBatchMsg(t *testing.T) {
+	m := baseStatusModel()
+	m.repos = append(m.repos, repoView{name: "repo2"})
+	m.prTotal = 2
+
+	msg := repoPRsBatchMsg{
+		{repo: "repo1", prs: []github.PR{{Number: 10, HeadRefName: "feat"}}},
+		{repo: "repo2", err: fmt.Errorf("gh failed")},
+	}
+
+	result, _ := m.Update(msg)
+	sm := result.(statusModel)
+
+	if sm.prDone != 2 {
+		t.Errorf("prDone = %d, want 2", sm.prDone)
+	}
+	if sm.prErrors != 1 {
+		t.Errorf("prErrors = %d, want 1", sm.prErrors)
+	}
+	if pr := sm.repos[0].prs["feat"]; pr == nil || pr.Number != 10 {
+		t.Errorf("repo1 feat PR = %v, want #10", pr)
+	}
+	if !sm.repos[1].prsLoaded {
+		t.Error("repo2 prsLoaded = false, want true")
+	}
+}
+
+func TestStatusUpdate_OfflinePRsShowAsOf(t *testing.T) {
+	orig := nowFunc
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = orig }()
+
+	m := baseStatusModel()
+	result, _ := m.Update(repoPRsMsg{repo: "repo1", asOf: now.Add(-2 * time.Hour)})
+	sm := result.(statusModel)
+
+	if view := sm.View(); !strings.Contains(view, "PRs as of 2h ago") {
+		t.Errorf("view missing cached-as-of note:\n%s", view)
+	}
+}
+
 func TestStatusUpdate_KeyQ(t *testing.T) {
 	m := baseStatusModel()
 	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
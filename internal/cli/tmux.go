@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/mux"
+	tmuxpkg "github.com/brudil/workspace/internal/tmux"
+	"github.com/brudil/workspace/internal/ui"
+	"github.com/brudil/workspace/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+func newTmuxCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tmux",
+		Short: "Manage tmux windows for capsules",
+	}
+	cmd.AddCommand(newTmuxSessionCmd())
+	return cmd
+}
+
+func newTmuxSessionCmd() *cobra.Command {
+	var fresh, detach bool
+
+	cmd := &cobra.Command{
+		Use:   "session [name]",
+		Short: "Build a tmux session with a window per boarded capsule",
+		Long: `Build a tmux session with a window for every boarded capsule, laid out as
+each repo's ws.repo.toml [tmux] section says, then attach to it. The session
+is named after the workspace unless a name is given.
+
+If the session already exists, windows are added for boarded capsules that
+don't have one; with --fresh, it is killed and built again from scratch.
+
+Examples:
+  ws tmux session
+  ws tmux session --fresh
+  ws tmux session review --detach`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := LoadContext()
+			if err != nil {
+				return err
+			}
+			ws := ctx.WS
+
+			session := tmuxpkg.SessionName(ws.Title())
+			if len(args) == 1 {
+				session = tmuxpkg.SessionName(args[0])
+			}
+			if fresh && tmuxpkg.HasSession(session) {
+				if err := tmuxpkg.KillSession(session); err != nil {
+					return err
+				}
+			}
+
+			exists := tmuxpkg.HasSession(session)
+			open := map[string]bool{}
+			for _, name := range tmuxpkg.SessionWindows(session) {
+				open[name] = true
+			}
+
+			added := 0
+			for _, repo := range ws.RepoNames {
+				for _, capsule := range ws.Boarded[repo] {
+					path := filepath.Join(ws.RepoDir(repo), capsule)
+					if _, err := os.Stat(path); err != nil {
+						continue
+					}
+					name := tmuxpkg.WindowName(ws.DisplayNameFor(repo), capsule)
+					if open[name] {
+						continue
+					}
+					layout, err := repoTmuxLayout(ws, repo)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "  %s %v\n", ui.Orange.Render("⚠"), err)
+					}
+					if exists {
+						err = tmuxpkg.NewSessionWindow(session, name, path, layout)
+					} else {
+						err = tmuxpkg.NewSession(session, name, path, layout)
+						exists = err == nil
+					}
+					if err != nil {
+						return fmt.Errorf("opening %s: %w", name, err)
+					}
+					open[name] = true
+					added++
+					fmt.Fprintf(os.Stderr, "  %s %s %s\n", ui.Green.Render("✓"), ws.FormatRepoName(repo), ui.TagDim.Render(capsule))
+				}
+			}
+
+			if !exists {
+				return fmt.Errorf("nothing is boarded; board capsules with ws board first")
+			}
+			if added == 0 {
+				fmt.Fprintf(os.Stderr, "  %s\n", ui.Dim.Render("every boarded capsule already has a window"))
+			}
+			if detach {
+				return nil
+			}
+			return tmuxpkg.AttachSession(session)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fresh, "fresh", false, "Kill the session first and build it again")
+	cmd.Flags().BoolVarP(&detach, "detach", "d", false, "Build the session without attaching to it")
+	return cmd
+}
+
+// repoTmuxLayout reads the layout of repo's capsule windows from its
+// ws.repo.toml. A repo without one gets a single shell; so does one whose
+// ws.repo.toml can't be read, along with the error.
+func repoTmuxLayout(ws *workspace.Workspace, repo string) (tmuxpkg.Layout, error) {
+	cfg, err := config.ParseRepoConfig(filepath.Join(ws.MainWorktree(repo), config.RepoFileName))
+	if err != nil || cfg == nil {
+		return tmuxpkg.Layout{}, err
+	}
+	layout := tmuxpkg.Layout{Arrange: cfg.Tmux.Layout}
+	for _, p := range cfg.Tmux.Panes {
+		layout.Panes = append(layout.Panes, tmuxpkg.LayoutPane{
+			Split:   p.Split,
+			Size:    p.Size,
+			Command: p.Command,
+			Focus:   p.Focus,
+		})
+	}
+	return layout, nil
+}
+
+// openCapsuleWindow opens a window for a capsule in mx, laid out as its repo's
+// ws.repo.toml says.
+func openCapsuleWindow(mx mux.Multiplexer, ws *workspace.Workspace, repo, capsule string) error {
+	layout, err := repoTmuxLayout(ws, repo)
+	name := mux.WindowName(ws.DisplayNameFor(repo), capsule)
+	if werr := mx.NewWindow(name, filepath.Join(ws.RepoDir(repo), capsule), layout); werr != nil {
+		return werr
+	}
+	return err
+}
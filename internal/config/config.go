@@ -5,6 +5,9 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
 
 	"github.com/BurntSushi/toml"
 )
@@ -18,15 +21,78 @@ type Config struct {
 	Boarded   map[string][]string   `toml:"-"` // from ws.local.toml [boarded] section
 	Git       string                `toml:"-"` // from ws.local.toml only
 	Silo      map[string]string     `toml:"-"` // from ws.local.toml [silo] section
+	MC        MCConfig              `toml:"mc"`
+	Issues    IssuesConfig          `toml:"issues"`
+	Branches  BranchesConfig        `toml:"branches"`
+	IDE       IDEConfig             `toml:"ide"`
 }
 
 type LocalConfig struct {
-	Git     string                `toml:"git"`
-	Repos   map[string]RepoConfig `toml:"repos"`
-	Boarded map[string][]string   `toml:"boarded"`
-	Silo    map[string]string     `toml:"silo"`
+	Git      string                `toml:"git"`
+	Repos    map[string]RepoConfig `toml:"repos"`
+	Boarded  map[string][]string   `toml:"boarded"`
+	Silo     map[string]string     `toml:"silo"`
+	MC       MCConfig              `toml:"mc,omitempty"`
+	Issues   IssuesConfig          `toml:"issues,omitempty"`
+	Branches BranchesConfig        `toml:"branches,omitempty"`
+	IDE      IDEConfig             `toml:"ide,omitempty"`
 }
 
+// IssuesConfig holds settings for capsules lifted from issues, from the
+// [issues] section.
+type IssuesConfig struct {
+	// BranchTemplate is a text/template for the branch name, rendered with
+	// .Number, .Title, .User and .Repo. slug turns a title into a
+	// branch-safe string.
+	BranchTemplate string `toml:"branch_template,omitempty"`
+}
+
+// BranchesConfig holds the branch naming policy from the [branches] section.
+// Only CapsuleTemplate can be overridden in ws.local.toml.
+type BranchesConfig struct {
+	Prefix          string `toml:"prefix,omitempty"`     // template put in front of new branches, e.g. "{{.User}}/"
+	Charset         string `toml:"charset,omitempty"`    // regexp character class of allowed characters, e.g. "a-z0-9/._-"
+	MaxLength       int    `toml:"max_length,omitempty"` // 0 means no limit
+	Pattern         string `toml:"pattern,omitempty"`    // regexp new branches must match
+	Fix             bool   `toml:"fix,omitempty"`        // rewrite names that break charset or max_length instead of rejecting them
+	CapsuleTemplate string `toml:"capsule_template,omitempty"`
+}
+
+// IDEConfig holds editor settings from the [ide] section.
+type IDEConfig struct {
+	// Generate lists the editors whose workspace files ws init sets up,
+	// e.g. ["vscode", "idea"]. ws.local.toml replaces the list.
+	Generate []string `toml:"generate,omitempty"`
+}
+
+// MCConfig holds mission control settings from the [mc] section.
+type MCConfig struct {
+	Views    map[string]MCView `toml:"views,omitempty"`
+	Commands []MCCommand       `toml:"commands,omitempty"`
+	Keys     map[string]string `toml:"keys,omitempty"` // action → key; "" unbinds
+}
+
+// MCView is a named filter expression that mission control can apply by
+// key, from the palette, or via `ws mc --view <name>`.
+type MCView struct {
+	Filter string `toml:"filter"`
+	Key    string `toml:"key,omitempty"`
+}
+
+// MCCommand is a user-defined command palette entry from [[mc.commands]].
+// Run is a text/template rendered against the selected row and executed with
+// sh in the row's directory, or in a new tmux window when Tmux is set.
+type MCCommand struct {
+	Label string `toml:"label"`
+	Run   string `toml:"run"`
+	Scope string `toml:"scope,omitempty"` // always (default), worktree, remote, pr, repo
+	Key   string `toml:"key,omitempty"`
+	Tmux  bool   `toml:"tmux,omitempty"`
+}
+
+// MCCommandScopes lists the valid values for MCCommand.Scope.
+var MCCommandScopes = []string{"always", "worktree", "remote", "pr", "repo"}
+
 const RepoFileName = "ws.repo.toml"
 
 type SiloRepoConfig struct {
@@ -35,8 +101,44 @@ type SiloRepoConfig struct {
 }
 
 type RepoFileConfig struct {
-	Capsule CapsuleConfig  `toml:"capsule"`
-	Silo    SiloRepoConfig `toml:"silo"`
+	Capsule CapsuleConfig    `toml:"capsule"`
+	Silo    SiloRepoConfig   `toml:"silo"`
+	VSCode  VSCodeRepoConfig `toml:"vscode"`
+	Tmux    TmuxRepoConfig   `toml:"tmux"`
+}
+
+// TmuxRepoConfig lays out the tmux window opened for each of the repo's
+// capsules. The first pane is the window's own; each later one is split off
+// the pane before it.
+type TmuxRepoConfig struct {
+	Layout string           `toml:"layout"` // tmux layout applied once the panes exist, e.g. "main-vertical"
+	Panes  []TmuxPaneConfig `toml:"panes"`
+}
+
+// TmuxPaneConfig is one [[tmux.panes]] entry.
+type TmuxPaneConfig struct {
+	Split   string `toml:"split"` // "right" (default) or "below"
+	Size    string `toml:"size"`  // e.g. "30%" or a number of cells
+	Command string `toml:"command"`
+	Focus   bool   `toml:"focus"`
+}
+
+// TmuxSplits lists the valid values for TmuxPaneConfig.Split.
+var TmuxSplits = []string{"right", "below"}
+
+// VSCodeRepoConfig is what a repo adds to the VS Code workspace file while
+// its capsules are boarded. Tasks and launch configurations are added once
+// per boarded capsule.
+type VSCodeRepoConfig struct {
+	Settings   map[string]any   `toml:"settings"`
+	Extensions []string         `toml:"extensions"`
+	Tasks      []map[string]any `toml:"tasks"`
+	Launch     []map[string]any `toml:"launch"`
+}
+
+// IsZero reports whether the repo adds nothing to the workspace file.
+func (c VSCodeRepoConfig) IsZero() bool {
+	return len(c.Settings) == 0 && len(c.Extensions) == 0 && len(c.Tasks) == 0 && len(c.Launch) == 0
 }
 
 type CapsuleConfig struct {
@@ -54,6 +156,11 @@ func ParseRepoConfig(path string) (*RepoFileConfig, error) {
 	if _, err := toml.DecodeFile(path, &cfg); err != nil {
 		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
+	for i, p := range cfg.Tmux.Panes {
+		if p.Split != "" && !slices.Contains(TmuxSplits, p.Split) {
+			return nil, fmt.Errorf("parsing %s: invalid split %q for tmux pane %d: must be one of %s", path, p.Split, i+1, strings.Join(TmuxSplits, ", "))
+		}
+	}
 	return &cfg, nil
 }
 
@@ -103,6 +210,10 @@ func Merge(base, local *Config) *Config {
 	merged := &Config{
 		Workspace: base.Workspace,
 		Repos:     make(map[string]RepoConfig, len(base.Repos)),
+		MC:        base.MC,
+		Issues:    base.Issues,
+		Branches:  base.Branches,
+		IDE:       base.IDE,
 	}
 	maps.Copy(merged.Repos, base.Repos)
 	for name, localRepo := range local.Repos {
@@ -163,15 +274,111 @@ func Load(startDir string) (*Config, string, error) {
 		if local.Git != "" {
 			cfg.Git = local.Git
 		}
+
+		cfg.MC = MergeMC(cfg.MC, local.MC)
+
+		if local.Issues.BranchTemplate != "" {
+			cfg.Issues.BranchTemplate = local.Issues.BranchTemplate
+		}
+		if local.Branches.CapsuleTemplate != "" {
+			cfg.Branches.CapsuleTemplate = local.Branches.CapsuleTemplate
+		}
+		if local.IDE.Generate != nil {
+			cfg.IDE.Generate = local.IDE.Generate
+		}
 	}
 
 	if cfg.Git != "" && cfg.Git != "ssh" && cfg.Git != "https" {
 		return nil, "", fmt.Errorf("invalid git protocol %q in %s: must be \"ssh\" or \"https\"", cfg.Git, LocalFileName)
 	}
 
+	if err := validateMC(cfg.MC); err != nil {
+		return nil, "", err
+	}
+
 	return cfg, root, nil
 }
 
+// MergeMC returns base with local mission control settings applied on top.
+// Views are keyed by name; a local view replaces a shared one of the same name.
+// Commands are keyed by label; a local command replaces a shared one in place
+// and new local commands are appended. Key bindings are keyed by action.
+func MergeMC(base, local MCConfig) MCConfig {
+	merged := MCConfig{}
+	if len(base.Views) > 0 || len(local.Views) > 0 {
+		merged.Views = make(map[string]MCView, len(base.Views)+len(local.Views))
+		maps.Copy(merged.Views, base.Views)
+		maps.Copy(merged.Views, local.Views)
+	}
+	if len(base.Keys) > 0 || len(local.Keys) > 0 {
+		merged.Keys = make(map[string]string, len(base.Keys)+len(local.Keys))
+		maps.Copy(merged.Keys, base.Keys)
+		maps.Copy(merged.Keys, local.Keys)
+	}
+	merged.Commands = slices.Clone(base.Commands)
+	for _, c := range local.Commands {
+		i := slices.IndexFunc(merged.Commands, func(b MCCommand) bool { return b.Label == c.Label })
+		if i >= 0 {
+			merged.Commands[i] = c
+		} else {
+			merged.Commands = append(merged.Commands, c)
+		}
+	}
+	return merged
+}
+
+func validateMC(mc MCConfig) error {
+	names := slices.Sorted(maps.Keys(mc.Views))
+	keys := make(map[string]string)
+	for _, name := range names {
+		v := mc.Views[name]
+		if v.Filter == "" {
+			return fmt.Errorf("view %q in [mc.views] has no filter", name)
+		}
+		if v.Key == "" {
+			continue
+		}
+		if len(v.Key) != 1 || v.Key[0] < '0' || v.Key[0] > '9' {
+			return fmt.Errorf("invalid key %q for view %q in [mc.views]: must be a single digit", v.Key, name)
+		}
+		if other, ok := keys[v.Key]; ok {
+			return fmt.Errorf("key %q is bound to both view %q and view %q", v.Key, other, name)
+		}
+		keys[v.Key] = name
+	}
+
+	labels := make(map[string]bool)
+	for _, c := range mc.Commands {
+		if c.Label == "" {
+			return fmt.Errorf("command in [[mc.commands]] has no label")
+		}
+		if labels[c.Label] {
+			return fmt.Errorf("command %q is defined twice in [[mc.commands]]", c.Label)
+		}
+		labels[c.Label] = true
+		if c.Run == "" {
+			return fmt.Errorf("command %q in [[mc.commands]] has no run", c.Label)
+		}
+		if _, err := template.New(c.Label).Parse(c.Run); err != nil {
+			return fmt.Errorf("command %q in [[mc.commands]]: %w", c.Label, err)
+		}
+		if c.Scope != "" && !slices.Contains(MCCommandScopes, c.Scope) {
+			return fmt.Errorf("invalid scope %q for command %q: must be one of %s", c.Scope, c.Label, strings.Join(MCCommandScopes, ", "))
+		}
+		if c.Key == "" {
+			continue
+		}
+		if len([]rune(c.Key)) != 1 {
+			return fmt.Errorf("invalid key %q for command %q: must be a single character", c.Key, c.Label)
+		}
+		if other, ok := keys[c.Key]; ok {
+			return fmt.Errorf("key %q is bound to both %q and command %q", c.Key, other, c.Label)
+		}
+		keys[c.Key] = c.Label
+	}
+	return nil
+}
+
 func UpdateLocal(root string, fn func(*LocalConfig)) error {
 	path := filepath.Join(root, LocalFileName)
 
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -558,6 +559,106 @@ color = "#00ff00"
 	}
 }
 
+func TestLoad_IssuesBranchTemplate(t *testing.T) {
+	root := t.TempDir()
+	base := `[workspace]
+org = "test-org"
+default_branch = "main"
+
+[issues]
+branch_template = "{{.Number}}-{{slug .Title}}"
+
+[repos.repo-a]
+`
+	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+
+	cfg, _, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Issues.BranchTemplate != "{{.Number}}-{{slug .Title}}" {
+		t.Errorf("branch_template = %q", cfg.Issues.BranchTemplate)
+	}
+
+	local := `[issues]
+branch_template = "{{.User}}/{{.Number}}"
+`
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte(local), 0644)
+	cfg, _, err = Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Issues.BranchTemplate != "{{.User}}/{{.Number}}" {
+		t.Errorf("branch_template = %q, want the local override", cfg.Issues.BranchTemplate)
+	}
+}
+
+func TestLoad_BranchesPolicy(t *testing.T) {
+	root := t.TempDir()
+	base := `[workspace]
+org = "test-org"
+default_branch = "main"
+
+[branches]
+prefix = "{{.User}}/"
+charset = "a-z0-9/-"
+max_length = 50
+fix = true
+capsule_template = "{{.Name}}"
+
+[repos.repo-a]
+`
+	local := `[branches]
+prefix = "ignored/"
+capsule_template = "{{trunc 12 .Name}}"
+`
+	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte(local), 0644)
+
+	cfg, _, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b := cfg.Branches
+	if b.Prefix != "{{.User}}/" || b.Charset != "a-z0-9/-" || b.MaxLength != 50 || !b.Fix {
+		t.Errorf("branches = %+v, want the shared policy", b)
+	}
+	if b.CapsuleTemplate != "{{trunc 12 .Name}}" {
+		t.Errorf("capsule_template = %q, want the local override", b.CapsuleTemplate)
+	}
+}
+
+func TestLoad_IDEGenerate(t *testing.T) {
+	root := t.TempDir()
+	base := `[workspace]
+org = "test-org"
+default_branch = "main"
+
+[ide]
+generate = ["vscode", "idea"]
+
+[repos.repo-a]
+`
+	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+
+	cfg, _, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.IDE.Generate, []string{"vscode", "idea"}) {
+		t.Errorf("generate = %v, want the shared list", cfg.IDE.Generate)
+	}
+
+	// A local list replaces the shared one, even when empty.
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte("[ide]\ngenerate = []\n"), 0644)
+	if cfg, _, err = Load(root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.IDE.Generate) != 0 {
+		t.Errorf("generate = %v, want the local override", cfg.IDE.Generate)
+	}
+}
+
 func TestLoad_GitProtocolDefault(t *testing.T) {
 	root := t.TempDir()
 	content := `[workspace]
@@ -799,3 +900,289 @@ after_switch = "npm run db:migrate"
 		t.Errorf("capsule.after_create = %q, want %q", cfg.Capsule.AfterCreate, "npm install")
 	}
 }
+
+func TestLoad_MCViews(t *testing.T) {
+	root := t.TempDir()
+	base := `[workspace]
+org = "test-org"
+default_branch = "main"
+
+[repos.repo-a]
+
+[mc.views.failing]
+filter = "pr:failing"
+key = "5"
+
+[mc.views.stale]
+filter = "age>14d"
+`
+	local := `[mc.views.stale]
+filter = "age>7d"
+key = "6"
+
+[mc.views.mine]
+filter = "mine dirty"
+`
+	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte(local), 0644)
+
+	cfg, _, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.MC.Views) != 3 {
+		t.Fatalf("views count = %d, want 3", len(cfg.MC.Views))
+	}
+	if got := cfg.MC.Views["failing"]; got.Filter != "pr:failing" || got.Key != "5" {
+		t.Errorf("failing = %+v, want shared view", got)
+	}
+	if got := cfg.MC.Views["stale"]; got.Filter != "age>7d" || got.Key != "6" {
+		t.Errorf("stale = %+v, want local override", got)
+	}
+	if got := cfg.MC.Views["mine"]; got.Filter != "mine dirty" {
+		t.Errorf("mine = %+v, want local-only view", got)
+	}
+}
+
+func TestLoad_InvalidMCViews(t *testing.T) {
+	tests := []struct {
+		name  string
+		views string
+	}{
+		{"missing filter", "[mc.views.a]\nkey = \"5\"\n"},
+		{"non-digit key", "[mc.views.a]\nfilter = \"dirty\"\nkey = \"x\"\n"},
+		{"duplicate key", "[mc.views.a]\nfilter = \"dirty\"\nkey = \"5\"\n[mc.views.b]\nfilter = \"live\"\nkey = \"5\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			base := "[workspace]\norg = \"test-org\"\ndefault_branch = \"main\"\n\n[repos.repo-a]\n\n" + tt.views
+			os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+
+			if _, _, err := Load(root); err == nil {
+				t.Error("expected error for invalid view")
+			}
+		})
+	}
+}
+
+func TestSaveBoarded_PreservesMCViews(t *testing.T) {
+	root := t.TempDir()
+	local := `[mc.views.mine]
+filter = "mine"
+`
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte(local), 0644)
+
+	if err := SaveBoarded(root, map[string][]string{"repo-a": {"feat"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lc := &LocalConfig{}
+	if _, err := toml.DecodeFile(filepath.Join(root, "ws.local.toml"), lc); err != nil {
+		t.Fatalf("re-parse error: %v", err)
+	}
+	if lc.MC.Views["mine"].Filter != "mine" {
+		t.Errorf("views = %+v, want mine preserved", lc.MC.Views)
+	}
+}
+
+func TestLoad_MCCommands(t *testing.T) {
+	root := t.TempDir()
+	base := `[workspace]
+org = "test-org"
+default_branch = "main"
+
+[repos.repo-a]
+
+[[mc.commands]]
+label = "Run tests"
+run = "make test"
+scope = "worktree"
+key = "t"
+
+[[mc.commands]]
+label = "Lint"
+run = "make lint"
+`
+	local := `[[mc.commands]]
+label = "Run tests"
+run = "go test ./..."
+scope = "worktree"
+key = "t"
+
+[[mc.commands]]
+label = "Open PR"
+run = "open {{.PR.URL}}"
+scope = "pr"
+tmux = true
+`
+	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte(local), 0644)
+
+	cfg, _, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmds := cfg.MC.Commands
+	if len(cmds) != 3 {
+		t.Fatalf("commands count = %d, want 3", len(cmds))
+	}
+	if cmds[0].Label != "Run tests" || cmds[0].Run != "go test ./..." {
+		t.Errorf("commands[0] = %+v, want local override in place", cmds[0])
+	}
+	if cmds[1].Label != "Lint" {
+		t.Errorf("commands[1] = %+v, want shared Lint", cmds[1])
+	}
+	if cmds[2].Label != "Open PR" || !cmds[2].Tmux {
+		t.Errorf("commands[2] = %+v, want local Open PR appended", cmds[2])
+	}
+}
+
+func TestLoad_InvalidMCCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands string
+	}{
+		{"missing label", "[[mc.commands]]\nrun = \"x\"\n"},
+		{"missing run", "[[mc.commands]]\nlabel = \"x\"\n"},
+		{"bad scope", "[[mc.commands]]\nlabel = \"x\"\nrun = \"x\"\nscope = \"galaxy\"\n"},
+		{"bad template", "[[mc.commands]]\nlabel = \"x\"\nrun = \"{{.Repo\"\n"},
+		{"long key", "[[mc.commands]]\nlabel = \"x\"\nrun = \"x\"\nkey = \"tt\"\n"},
+		{"duplicate label", "[[mc.commands]]\nlabel = \"x\"\nrun = \"x\"\n[[mc.commands]]\nlabel = \"x\"\nrun = \"y\"\n"},
+		{"key clashes with view", "[mc.views.a]\nfilter = \"dirty\"\nkey = \"5\"\n[[mc.commands]]\nlabel = \"x\"\nrun = \"x\"\nkey = \"5\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			base := "[workspace]\norg = \"test-org\"\ndefault_branch = \"main\"\n\n[repos.repo-a]\n\n" + tt.commands
+			os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+
+			if _, _, err := Load(root); err == nil {
+				t.Error("expected error for invalid command")
+			}
+		})
+	}
+}
+
+func TestLoad_MCKeys(t *testing.T) {
+	root := t.TempDir()
+	base := `[workspace]
+org = "test-org"
+default_branch = "main"
+
+[repos.repo-a]
+
+[mc.keys]
+open = "e"
+github = "g"
+`
+	local := `[mc.keys]
+open = "O"
+refresh = ""
+`
+	os.WriteFile(filepath.Join(root, "ws.toml"), []byte(base), 0644)
+	os.WriteFile(filepath.Join(root, "ws.local.toml"), []byte(local), 0644)
+
+	cfg, _, err := Load(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := cfg.MC.Keys
+	if keys["open"] != "O" {
+		t.Errorf("open = %q, want local override", keys["open"])
+	}
+	if keys["github"] != "g" {
+		t.Errorf("github = %q, want shared binding", keys["github"])
+	}
+	if key, ok := keys["refresh"]; !ok || key != "" {
+		t.Errorf("refresh = %q (set %v), want explicit unbind", key, ok)
+	}
+}
+
+func TestParseRepoConfig_VSCode(t *testing.T) {
+	content := `[vscode]
+extensions = ["golang.go"]
+
+[vscode.settings]
+"go.buildTags" = "integration"
+
+[[vscode.tasks]]
+label = "test"
+type = "shell"
+command = "go test ./..."
+
+[[vscode.launch]]
+name = "Server"
+type = "go"
+request = "launch"
+program = "${workspaceFolder}/cmd/server"
+args = ["--branch", "${ws:branch}"]
+`
+	path := filepath.Join(t.TempDir(), "ws.repo.toml")
+	os.WriteFile(path, []byte(content), 0644)
+
+	cfg, err := ParseRepoConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vs := cfg.VSCode
+	if vs.IsZero() {
+		t.Fatal("expected vscode config")
+	}
+	if vs.Settings["go.buildTags"] != "integration" {
+		t.Errorf("settings = %v", vs.Settings)
+	}
+	if len(vs.Extensions) != 1 || vs.Extensions[0] != "golang.go" {
+		t.Errorf("extensions = %v", vs.Extensions)
+	}
+	if len(vs.Tasks) != 1 || vs.Tasks[0]["label"] != "test" {
+		t.Errorf("tasks = %v", vs.Tasks)
+	}
+	if len(vs.Launch) != 1 || vs.Launch[0]["program"] != "${workspaceFolder}/cmd/server" {
+		t.Errorf("launch = %v", vs.Launch)
+	}
+}
+
+func TestParseRepoConfig_Tmux(t *testing.T) {
+	content := `[tmux]
+layout = "main-vertical"
+
+[[tmux.panes]]
+command = "nvim ."
+focus = true
+
+[[tmux.panes]]
+split = "below"
+size = "30%"
+command = "npm test -- --watch"
+`
+	path := filepath.Join(t.TempDir(), "ws.repo.toml")
+	os.WriteFile(path, []byte(content), 0644)
+
+	cfg, err := ParseRepoConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Tmux.Layout != "main-vertical" {
+		t.Errorf("layout = %q, want main-vertical", cfg.Tmux.Layout)
+	}
+	if len(cfg.Tmux.Panes) != 2 {
+		t.Fatalf("panes = %d, want 2", len(cfg.Tmux.Panes))
+	}
+	if p := cfg.Tmux.Panes[0]; p.Command != "nvim ." || !p.Focus {
+		t.Errorf("pane 1 = %+v", p)
+	}
+	if p := cfg.Tmux.Panes[1]; p.Split != "below" || p.Size != "30%" {
+		t.Errorf("pane 2 = %+v", p)
+	}
+}
+
+func TestParseRepoConfig_TmuxInvalidSplit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ws.repo.toml")
+	os.WriteFile(path, []byte("[[tmux.panes]]\nsplit = \"left\"\n"), 0644)
+
+	_, err := ParseRepoConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `invalid split "left"`) {
+		t.Errorf("err = %v, want invalid split", err)
+	}
+}
@@ -0,0 +1,258 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Cache is an on-disk cache of forge responses for one workspace. Each entry
+// is a JSON file under <dir>/<kind>/ holding the response, when it was
+// fetched and, for conditional requests, its ETag.
+type Cache struct {
+	dir string
+}
+
+// Entry kinds, each with its own subdirectory (and TTL, see cacheTTL).
+const (
+	kindOpenPRs   = "open-prs"
+	kindMergedPRs = "merged-prs"
+	kindPR        = "pr"
+	kindPRDetail  = "pr-detail"
+	kindIssue     = "issue"
+	kindRuns      = "runs"
+	kindBranches  = "branches"
+	kindUser      = "user"
+)
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetchedAt"`
+	CheckedAt time.Time       `json:"checkedAt"` // last fetched or revalidated
+	ETag      string          `json:"etag,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// CacheDir returns the cache directory for the workspace at root:
+// $XDG_CACHE_HOME/ws/<name>-<hash>, falling back to the platform's user
+// cache directory and then the temp dir.
+func CacheDir(root string) string {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			base = dir
+		} else {
+			base = os.TempDir()
+		}
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(base, "ws", fmt.Sprintf("%s-%x", filepath.Base(root), sum[:4]))
+}
+
+// NewCache returns a cache stored in dir. The directory is created on the
+// first write.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the directory the cache is stored in.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// path returns the file for an entry, escaping each key part so branch
+// names with slashes stay in one file.
+func (c *Cache) path(kind string, parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = url.PathEscape(p)
+	}
+	return filepath.Join(c.dir, kind, strings.Join(escaped, "--")+".json")
+}
+
+// read returns the entry at path. Misses and corrupt entries report false.
+func (c *Cache) read(path string) (cacheEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+// write stores e at path atomically. Errors are silently swallowed
+// (best-effort).
+func (c *Cache) write(path string, e cacheEntry) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_ = os.MkdirAll(filepath.Dir(path), 0o755)
+	// Background refreshes and other ws processes can write the same entry
+	// at once, so each writes its own temp file and renames it into place.
+	f, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if f.Close() != nil || err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	_ = os.Rename(f.Name(), path)
+}
+
+// store writes v as a freshly fetched entry.
+func (c *Cache) store(path string, v any, etag string) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	now := nowFunc()
+	c.write(path, cacheEntry{FetchedAt: now, CheckedAt: now, ETag: etag, Data: data})
+}
+
+// readData returns the entry at path decoded into T.
+func readData[T any](c *Cache, path string) (T, cacheEntry, bool) {
+	var v T
+	e, ok := c.read(path)
+	if !ok || json.Unmarshal(e.Data, &v) != nil {
+		return v, cacheEntry{}, false
+	}
+	return v, e, true
+}
+
+// OpenPRs returns a repo's cached open PRs, however old, and when they were
+// fetched. It never goes to the network.
+func (c *Cache) OpenPRs(org, repo string) ([]PR, time.Time, bool) {
+	prs, e, ok := readData[[]PR](c, c.path(kindOpenPRs, org, repo))
+	return prs, e.FetchedAt, ok
+}
+
+// MergedPRs returns a repo's cached recently merged PRs and when they were
+// fetched, without fetching.
+func (c *Cache) MergedPRs(org, repo string) ([]PR, time.Time, bool) {
+	prs, e, ok := readData[[]PR](c, c.path(kindMergedPRs, org, repo))
+	return prs, e.FetchedAt, ok
+}
+
+// AddPR inserts pr into a repo's cached open PRs, replacing any cached PR
+// for the same head branch, so a just-opened PR shows up before the next
+// refresh.
+func (c *Cache) AddPR(org, repo string, pr PR) {
+	path := c.path(kindOpenPRs, org, repo)
+	prs, e, ok := readData[[]PR](c, path)
+	prs = slices.DeleteFunc(prs, func(p PR) bool {
+		return p.Number == pr.Number || p.HeadRefName == pr.HeadRefName
+	})
+	data, err := json.Marshal(append(prs, pr))
+	if err != nil {
+		return
+	}
+	if !ok {
+		// A lone PR isn't the repo's full list; leave it due for a fetch.
+		e = cacheEntry{}
+	}
+	e.Data = data
+	c.write(path, e)
+}
+
+// Expire marks a repo's cached PR lists as due for a fetch, keeping them
+// for cache-only reads.
+func (c *Cache) Expire(org, repo string) {
+	for _, kind := range []string{kindOpenPRs, kindMergedPRs} {
+		path := c.path(kind, org, repo)
+		if e, ok := c.read(path); ok {
+			e.CheckedAt = time.Time{}
+			c.write(path, e)
+		}
+	}
+}
+
+// Branches returns the cached worktree-to-branch mappings of a repo, or nil.
+func (c *Cache) Branches(org, repo string) map[string]string {
+	branches, _, _ := readData[map[string]string](c, c.path(kindBranches, org, repo))
+	return branches
+}
+
+// SetBranches caches the worktree-to-branch mappings of a repo.
+func (c *Cache) SetBranches(org, repo string, branches map[string]string) {
+	c.store(c.path(kindBranches, org, repo), branches, "")
+}
+
+// User returns the cached GitHub login, or "" on a miss.
+func (c *Cache) User() string {
+	login, _, _ := readData[string](c, c.path(kindUser, "login"))
+	return login
+}
+
+// SetUser caches the GitHub login.
+func (c *Cache) SetUser(login string) {
+	c.store(c.path(kindUser, "login"), login, "")
+}
+
+// remove deletes the entry at path.
+func (c *Cache) remove(path string) {
+	_ = os.Remove(path)
+}
+
+// CacheStats summarises what a cache holds.
+type CacheStats struct {
+	Entries int
+	Bytes   int64
+	Kinds   map[string]int // entries per kind
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// Stats walks the cache and counts its entries.
+func (c *Cache) Stats() (CacheStats, error) {
+	stats := CacheStats{Kinds: make(map[string]int)}
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+		stats.Kinds[filepath.Base(filepath.Dir(path))]++
+		if mod := info.ModTime(); stats.Oldest.IsZero() || mod.Before(stats.Oldest) {
+			stats.Oldest = mod
+		}
+		if mod := info.ModTime(); mod.After(stats.Newest) {
+			stats.Newest = mod
+		}
+		return nil
+	})
+	return stats, err
+}
+
+// Clear removes every entry and returns how many there were.
+func (c *Cache) Clear() (int, error) {
+	stats, err := c.Stats()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		return 0, err
+	}
+	return stats.Entries, nil
+}
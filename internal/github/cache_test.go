@@ -0,0 +1,175 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCacheDir_UsesXDGCacheHome(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", base)
+
+	dir := CacheDir("/home/me/acme")
+	if !strings.HasPrefix(dir, filepath.Join(base, "ws", "acme-")) {
+		t.Errorf("CacheDir() = %q, want it under %s/ws/acme-", dir, base)
+	}
+	if other := CacheDir("/home/me/other/acme"); other == dir {
+		t.Errorf("workspaces with the same name share cache dir %q", dir)
+	}
+}
+
+func TestCache_OpenPRsRoundTrip(t *testing.T) {
+	c := NewCache(t.TempDir())
+	prs := []PR{
+		{Number: 42, Title: "My PR", HeadRefName: "my-branch", State: "OPEN", ReviewDecision: "APPROVED", StatusRollup: "success", URL: "https://github.com/org/repo/pull/42", Author: "octocat"},
+	}
+	c.store(c.path(kindOpenPRs, "org", "repo"), prs, "")
+
+	got, fetchedAt, ok := c.OpenPRs("org", "repo")
+	if !ok || len(got) != 1 {
+		t.Fatalf("OpenPRs() = %v, %v; want one PR", got, ok)
+	}
+	if got[0] != prs[0] {
+		t.Errorf("PR = %+v, want %+v", got[0], prs[0])
+	}
+	if fetchedAt.IsZero() {
+		t.Error("fetchedAt is zero")
+	}
+}
+
+func TestCache_ConcurrentWritesStayWhole(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCache(dir)
+	path := c.path(kindOpenPRs, "org", "repo")
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			prs := make([]PR, 50)
+			for j := range prs {
+				prs[j] = PR{Number: i, Title: strings.Repeat("x", 100)}
+			}
+			c.store(path, prs, "")
+		})
+	}
+	wg.Wait()
+
+	if got, _, ok := c.OpenPRs("org", "repo"); !ok || len(got) != 50 {
+		t.Errorf("OpenPRs() = %d PRs, %v; want one writer's 50", len(got), ok)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(tmps) != 0 {
+		t.Errorf("temp files left behind: %v", tmps)
+	}
+}
+
+func TestCache_Miss(t *testing.T) {
+	c := NewCache(t.TempDir())
+	if prs, _, ok := c.OpenPRs("org", "repo"); ok || prs != nil {
+		t.Errorf("OpenPRs() = %v, %v; want a miss", prs, ok)
+	}
+	if got := c.User(); got != "" {
+		t.Errorf("User() = %q, want empty on miss", got)
+	}
+	if got := c.Branches("org", "repo"); got != nil {
+		t.Errorf("Branches() = %v, want nil on miss", got)
+	}
+}
+
+func TestCache_CorruptEntryIsAMiss(t *testing.T) {
+	c := NewCache(t.TempDir())
+	path := c.path(kindOpenPRs, "org", "repo")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	os.WriteFile(path, []byte("not json"), 0o644)
+
+	if _, _, ok := c.OpenPRs("org", "repo"); ok {
+		t.Error("expected a corrupt entry to read as a miss")
+	}
+}
+
+func TestCache_PathEscapesSlashes(t *testing.T) {
+	c := NewCache(t.TempDir())
+	path := c.path(kindRuns, "org", "repo", "feat/login", "5")
+	if filepath.Dir(path) != filepath.Join(c.Dir(), kindRuns) {
+		t.Errorf("path() = %q, want a single file under %s", path, kindRuns)
+	}
+}
+
+func TestCache_AddPRReplacesSameBranch(t *testing.T) {
+	c := NewCache(t.TempDir())
+	c.store(c.path(kindOpenPRs, "org", "repo"), []PR{
+		{Number: 1, HeadRefName: "feature-a"},
+		{Number: 2, HeadRefName: "feature-b", Title: "Old"},
+	}, "")
+
+	c.AddPR("org", "repo", PR{Number: 3, HeadRefName: "feature-b", Title: "New"})
+
+	got, _, _ := c.OpenPRs("org", "repo")
+	if len(got) != 2 {
+		t.Fatalf("got %d PRs, want 2", len(got))
+	}
+	if got[1].Number != 3 || got[1].Title != "New" {
+		t.Errorf("PR[1] = %+v, want the new PR for feature-b", got[1])
+	}
+}
+
+func TestCache_AddPROnMissLeavesEntryExpired(t *testing.T) {
+	c := NewCache(t.TempDir())
+	c.AddPR("org", "repo", PR{Number: 7, HeadRefName: "feature"})
+
+	got, _, ok := c.OpenPRs("org", "repo")
+	if !ok || len(got) != 1 || got[0].Number != 7 {
+		t.Errorf("OpenPRs() = %+v, want just PR 7", got)
+	}
+	e, _ := c.read(c.path(kindOpenPRs, "org", "repo"))
+	if entryFreshness(kindOpenPRs, e, true) != missing {
+		t.Error("a lone added PR should still be due for a fetch")
+	}
+}
+
+func TestCache_UserAndBranches(t *testing.T) {
+	c := NewCache(t.TempDir())
+	c.SetUser("octocat")
+	c.SetBranches("org", "repo", map[string]string{"feat": "feat/login"})
+
+	if got := c.User(); got != "octocat" {
+		t.Errorf("User() = %q, want %q", got, "octocat")
+	}
+	if got := c.Branches("org", "repo")["feat"]; got != "feat/login" {
+		t.Errorf("Branches()[feat] = %q, want %q", got, "feat/login")
+	}
+}
+
+func TestCache_StatsAndClear(t *testing.T) {
+	c := NewCache(filepath.Join(t.TempDir(), "nested", "cache"))
+
+	stats, err := c.Stats()
+	if err != nil || stats.Entries != 0 {
+		t.Fatalf("Stats() on a missing dir = %+v, %v; want empty", stats, err)
+	}
+
+	c.store(c.path(kindOpenPRs, "org", "a"), []PR{}, "")
+	c.store(c.path(kindOpenPRs, "org", "b"), []PR{}, "")
+	c.SetUser("octocat")
+
+	stats, err = c.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error: %v", err)
+	}
+	if stats.Entries != 3 || stats.Kinds[kindOpenPRs] != 2 || stats.Kinds[kindUser] != 1 {
+		t.Errorf("Stats() = %+v, want 3 entries, 2 open-prs, 1 user", stats)
+	}
+	if stats.Bytes == 0 || stats.Oldest.IsZero() || stats.Newest.IsZero() {
+		t.Errorf("Stats() = %+v, want sizes and times", stats)
+	}
+
+	n, err := c.Clear()
+	if err != nil || n != 3 {
+		t.Errorf("Clear() = %d, %v; want 3", n, err)
+	}
+	if _, err := os.Stat(c.Dir()); !os.IsNotExist(err) {
+		t.Error("cache dir still exists after Clear()")
+	}
+}
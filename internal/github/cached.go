@@ -0,0 +1,433 @@
+package github
+
+import (
+	"errors"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long each kind of entry is served without asking the
+// forge again.
+var cacheTTL = map[string]time.Duration{
+	kindOpenPRs:   time.Minute,
+	kindMergedPRs: 5 * time.Minute,
+	kindPR:        time.Minute,
+	kindPRDetail:  time.Minute,
+	kindIssue:     5 * time.Minute,
+	kindRuns:      30 * time.Second,
+}
+
+const (
+	// maxStale is the oldest an entry can be and still be served while it's
+	// refreshed; older entries are fetched again before they're used.
+	maxStale = 24 * time.Hour
+
+	// revalidateFor is how long a PR list is kept on 304s before it's
+	// fetched in full anyway, since check results don't change its ETag.
+	revalidateFor = 10 * time.Minute
+
+	// probeConcurrency caps concurrent conditional requests.
+	probeConcurrency = 8
+)
+
+var nowFunc = time.Now
+
+// ErrOffline is returned offline for data that isn't in the cache, and for
+// calls that would change something on the forge.
+var ErrOffline = errors.New("offline, and not in the cache")
+
+// reachTimeout bounds the check that the forge is reachable.
+const reachTimeout = 1500 * time.Millisecond
+
+// forgeReachable reports whether GitHub's API host accepts connections.
+var forgeReachable = func() bool {
+	host := os.Getenv("GH_HOST")
+	if host == "" {
+		host = "api.github.com"
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, "443"), reachTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// refreshes tracks background refreshes, so the process can let them finish
+// writing the cache before it exits.
+var refreshes sync.WaitGroup
+
+// WaitForRefreshes blocks until background cache refreshes have finished.
+func WaitForRefreshes() {
+	refreshes.Wait()
+}
+
+// prsValidator is implemented by clients that can make conditional requests
+// for a repo's PRs (see PRsETag).
+type prsValidator interface {
+	PRsETag(org, repo, etag string) (string, bool, error)
+}
+
+func (LiveClient) PRsETag(org, repo, etag string) (string, bool, error) {
+	return PRsETag(org, repo, etag)
+}
+
+// CachedClient serves Client calls from a Cache, going to the wrapped client
+// when an entry is missing or older than its TTL.
+type CachedClient struct {
+	Client Client
+	Cache  *Cache
+
+	// ServeStale returns entries past their TTL straight away and refreshes
+	// them in the background (stale-while-revalidate). Background refreshes
+	// revalidate PR lists with conditional requests when the client can.
+	ServeStale bool
+
+	// Offline serves every call from the cache, however old the entry, and
+	// returns ErrOffline on a miss.
+	Offline bool
+
+	// DetectOffline checks that the forge is reachable before the first
+	// call that needs it, and goes offline for the process if it isn't.
+	DetectOffline bool
+
+	detect      sync.Once
+	unreachable bool
+}
+
+// offline reports whether calls must be served from the cache.
+func (c *CachedClient) offline() bool {
+	if c.Offline {
+		return true
+	}
+	if !c.DetectOffline {
+		return false
+	}
+	c.detect.Do(func() { c.unreachable = !forgeReachable() })
+	return c.unreachable
+}
+
+// IsOffline reports whether c serves everything from its cache, either
+// because it was asked to or because the forge can't be reached.
+func IsOffline(c Client) bool {
+	cc, ok := c.(*CachedClient)
+	return ok && cc.offline()
+}
+
+// Offline returns a client sharing c's cache that never goes to the
+// network. Clients without a cache are returned unchanged.
+func Offline(c Client) Client {
+	if cc, ok := c.(*CachedClient); ok {
+		return &CachedClient{Client: cc.Client, Cache: cc.Cache, Offline: true}
+	}
+	return c
+}
+
+// Login returns the GitHub login of the current user, from c's cache when
+// it has one. It returns "" when the login can't be found.
+func Login(c Client) string {
+	cache := CacheFor(c)
+	if cache != nil {
+		if login := cache.User(); login != "" {
+			return login
+		}
+	}
+	if IsOffline(c) {
+		return ""
+	}
+	login, err := CurrentUser()
+	if err != nil {
+		return ""
+	}
+	if login != "" && cache != nil {
+		cache.SetUser(login)
+	}
+	return login
+}
+
+// CacheFor returns the cache behind c, or nil if c isn't cached.
+func CacheFor(c Client) *Cache {
+	if cc, ok := c.(*CachedClient); ok {
+		return cc.Cache
+	}
+	return nil
+}
+
+// WithoutStale returns a client sharing c's cache that fetches expired
+// entries before returning them, for views that won't pick up a background
+// refresh. Other clients are returned unchanged.
+func WithoutStale(c Client) Client {
+	if cc, ok := c.(*CachedClient); ok {
+		return &CachedClient{Client: cc.Client, Cache: cc.Cache, Offline: cc.Offline, DetectOffline: cc.DetectOffline}
+	}
+	return c
+}
+
+type freshness int
+
+const (
+	missing freshness = iota
+	stale
+	fresh
+)
+
+func entryFreshness(kind string, e cacheEntry, ok bool) freshness {
+	if !ok {
+		return missing
+	}
+	switch age := nowFunc().Sub(e.CheckedAt); {
+	case age < cacheTTL[kind]:
+		return fresh
+	case age < maxStale:
+		return stale
+	}
+	return missing
+}
+
+// cached serves one entry from the cache, calling fetch when it's missing or
+// expired. With ServeStale, an expired entry is returned as is and fetch runs
+// in the background to refresh it.
+func cached[T any](c *CachedClient, kind string, parts []string, fetch func() (T, error)) (T, error) {
+	path := c.Cache.path(kind, parts...)
+	v, e, ok := readData[T](c.Cache, path)
+	if c.offline() {
+		if !ok {
+			return v, ErrOffline
+		}
+		return v, nil
+	}
+	switch entryFreshness(kind, e, ok) {
+	case fresh:
+		return v, nil
+	case stale:
+		if c.ServeStale {
+			refreshes.Go(func() {
+				if v, err := fetch(); err == nil {
+					c.Cache.store(path, v, "")
+				}
+			})
+			return v, nil
+		}
+	}
+	v, err := fetch()
+	if err == nil {
+		c.Cache.store(path, v, "")
+	}
+	return v, err
+}
+
+func (c *CachedClient) PRsForRepo(org, repo string) ([]PR, error) {
+	return cached(c, kindOpenPRs, []string{org, repo}, func() ([]PR, error) {
+		return c.Client.PRsForRepo(org, repo)
+	})
+}
+
+func (c *CachedClient) MergedPRsForRepo(org, repo string) ([]PR, error) {
+	return cached(c, kindMergedPRs, []string{org, repo}, func() ([]PR, error) {
+		return c.Client.MergedPRsForRepo(org, repo)
+	})
+}
+
+func (c *CachedClient) PRFromNumber(org, repo string, number int) (*PR, error) {
+	pr, err := cached(c, kindPR, []string{org, repo, strconv.Itoa(number)}, func() (*PR, error) {
+		return c.Client.PRFromNumber(org, repo, number)
+	})
+	if errors.Is(err, ErrOffline) {
+		// The PR may still be in a cached list.
+		for _, kind := range []string{kindOpenPRs, kindMergedPRs} {
+			prs, _, _ := readData[[]PR](c.Cache, c.Cache.path(kind, org, repo))
+			if i := slices.IndexFunc(prs, func(p PR) bool { return p.Number == number }); i >= 0 {
+				return &prs[i], nil
+			}
+		}
+	}
+	return pr, err
+}
+
+// PRsFromNumbers serves each PR from the cache and fetches the rest with one
+// batched call. Offline, the PRs that aren't cached are left out.
+func (c *CachedClient) PRsFromNumbers(org, repo string, numbers []int) (map[int]PR, error) {
+	result := make(map[int]PR, len(numbers))
+	offline := c.offline()
+	var missing []int
+	for _, n := range numbers {
+		pr, e, ok := readData[*PR](c.Cache, c.Cache.path(kindPR, org, repo, strconv.Itoa(n)))
+		if pr != nil && (offline || entryFreshness(kindPR, e, ok) == fresh) {
+			result[n] = *pr
+			continue
+		}
+		missing = append(missing, n)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	if offline {
+		return result, ErrOffline
+	}
+	fetched, err := c.Client.PRsFromNumbers(org, repo, missing)
+	for n, pr := range fetched {
+		c.Cache.store(c.Cache.path(kindPR, org, repo, strconv.Itoa(n)), &pr, "")
+		result[n] = pr
+	}
+	return result, err
+}
+
+func (c *CachedClient) IssueFromNumber(org, repo string, number int) (*Issue, error) {
+	return cached(c, kindIssue, []string{org, repo, strconv.Itoa(number)}, func() (*Issue, error) {
+		return c.Client.IssueFromNumber(org, repo, number)
+	})
+}
+
+func (c *CachedClient) PRDetail(org, repo string, number int) (PRDetailResult, error) {
+	return cached(c, kindPRDetail, []string{org, repo, strconv.Itoa(number)}, func() (PRDetailResult, error) {
+		return c.Client.PRDetail(org, repo, number)
+	})
+}
+
+func (c *CachedClient) WorkflowRuns(org, repo, branch string, limit int) ([]WorkflowRun, error) {
+	return cached(c, kindRuns, []string{org, repo, branch, strconv.Itoa(limit)}, func() ([]WorkflowRun, error) {
+		return c.Client.WorkflowRuns(org, repo, branch, limit)
+	})
+}
+
+// PRsForRepos serves each repo's open and merged PRs from the cache and
+// fetches the rest with one batched call.
+func (c *CachedClient) PRsForRepos(org string, repos []string) map[string]RepoPRs {
+	result := make(map[string]RepoPRs, len(repos))
+	offline := c.offline()
+	var expired, refresh []string
+	for _, repo := range repos {
+		open, oe, ook := readData[[]PR](c.Cache, c.Cache.path(kindOpenPRs, org, repo))
+		merged, me, mok := readData[[]PR](c.Cache, c.Cache.path(kindMergedPRs, org, repo))
+		f := min(entryFreshness(kindOpenPRs, oe, ook), entryFreshness(kindMergedPRs, me, mok))
+		switch {
+		case offline && ook:
+			result[repo] = RepoPRs{Open: open, Merged: merged, AsOf: oe.FetchedAt}
+		case offline:
+			result[repo] = RepoPRs{Err: ErrOffline}
+		case f == fresh:
+			result[repo] = RepoPRs{Open: open, Merged: merged}
+		case f == stale && c.ServeStale:
+			result[repo] = RepoPRs{Open: open, Merged: merged}
+			refresh = append(refresh, repo)
+		default:
+			expired = append(expired, repo)
+		}
+	}
+
+	if len(refresh) > 0 {
+		refreshes.Go(func() {
+			c.fetchPRs(org, c.revalidatePRs(org, refresh))
+		})
+	}
+	if len(expired) > 0 {
+		maps.Copy(result, c.fetchPRs(org, expired))
+	}
+	return result
+}
+
+// fetchPRs fetches and caches the PRs of repos in one batched call.
+func (c *CachedClient) fetchPRs(org string, repos []string) map[string]RepoPRs {
+	if len(repos) == 0 {
+		return nil
+	}
+	result := c.Client.PRsForRepos(org, repos)
+	for repo, r := range result {
+		if r.Err != nil {
+			continue
+		}
+		etag := ""
+		if e, ok := c.Cache.read(c.Cache.path(kindOpenPRs, org, repo)); ok {
+			etag = e.ETag
+		}
+		c.Cache.store(c.Cache.path(kindOpenPRs, org, repo), r.Open, etag)
+		c.Cache.store(c.Cache.path(kindMergedPRs, org, repo), r.Merged, etag)
+	}
+	return result
+}
+
+// revalidatePRs makes a conditional request for each repo whose cached PRs
+// were fetched recently enough, marking them checked when nothing changed.
+// It returns the repos that need fetching.
+func (c *CachedClient) revalidatePRs(org string, repos []string) []string {
+	v, ok := c.Client.(prsValidator)
+	if !ok {
+		return repos
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var changed []string
+	sem := make(chan struct{}, probeConcurrency)
+	for _, repo := range repos {
+		openPath := c.Cache.path(kindOpenPRs, org, repo)
+		e, ok := c.Cache.read(openPath)
+		if !ok || nowFunc().Sub(e.FetchedAt) >= revalidateFor {
+			mu.Lock()
+			changed = append(changed, repo)
+			mu.Unlock()
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			etag, modified, err := v.PRsETag(org, repo, e.ETag)
+			if err != nil || modified {
+				if err == nil {
+					// Keep the new ETag for the fetch that follows.
+					e.ETag = etag
+					c.Cache.write(openPath, e)
+				}
+				mu.Lock()
+				changed = append(changed, repo)
+				mu.Unlock()
+				return
+			}
+			now := nowFunc()
+			for _, kind := range []string{kindOpenPRs, kindMergedPRs} {
+				path := c.Cache.path(kind, org, repo)
+				if e, ok := c.Cache.read(path); ok {
+					e.CheckedAt = now
+					c.Cache.write(path, e)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return changed
+}
+
+// CreatePR opens the PR and adds it to the cached open PRs, so it shows up
+// before GitHub lists it.
+func (c *CachedClient) CreatePR(org, repo string, opts CreatePROptions) (*PR, error) {
+	if c.offline() {
+		return nil, ErrOffline
+	}
+	pr, err := c.Client.CreatePR(org, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Author == "" {
+		pr.Author = c.Cache.User()
+	}
+	c.Cache.AddPR(org, repo, *pr)
+	return pr, nil
+}
+
+// EditPRBody edits the PR and drops its cached detail.
+func (c *CachedClient) EditPRBody(org, repo string, number int, body string) error {
+	if c.offline() {
+		return ErrOffline
+	}
+	if err := c.Client.EditPRBody(org, repo, number, body); err != nil {
+		return err
+	}
+	c.Cache.remove(c.Cache.path(kindPRDetail, org, repo, strconv.Itoa(number)))
+	return nil
+}
@@ -0,0 +1,300 @@
+package github
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingClient records which repos each call asked for.
+type countingClient struct {
+	mu       sync.Mutex
+	batches  [][]string
+	probes   []string
+	numbers  [][]int
+	modified bool
+	err      error
+}
+
+func (c *countingClient) PRsForRepo(org, repo string) ([]PR, error) {
+	return c.PRsForRepos(org, []string{repo})[repo].Open, c.err
+}
+
+func (c *countingClient) MergedPRsForRepo(org, repo string) ([]PR, error) {
+	return c.PRsForRepos(org, []string{repo})[repo].Merged, c.err
+}
+
+func (c *countingClient) PRsForRepos(org string, repos []string) map[string]RepoPRs {
+	c.mu.Lock()
+	c.batches = append(c.batches, slices.Clone(repos))
+	c.mu.Unlock()
+	result := make(map[string]RepoPRs, len(repos))
+	for _, repo := range repos {
+		result[repo] = RepoPRs{
+			Open:   []PR{{Number: 1, HeadRefName: repo + "-feature", State: "OPEN"}},
+			Merged: []PR{{Number: 2, HeadRefName: repo + "-done", State: "MERGED"}},
+			Err:    c.err,
+		}
+	}
+	return result
+}
+
+func (c *countingClient) PRFromNumber(org, repo string, number int) (*PR, error) {
+	return &PR{Number: number}, c.err
+}
+
+func (c *countingClient) PRsFromNumbers(org, repo string, numbers []int) (map[int]PR, error) {
+	c.mu.Lock()
+	c.numbers = append(c.numbers, slices.Clone(numbers))
+	c.mu.Unlock()
+	result := make(map[int]PR, len(numbers))
+	for _, n := range numbers {
+		result[n] = PR{Number: n, State: "MERGED"}
+	}
+	return result, c.err
+}
+
+func (c *countingClient) IssueFromNumber(org, repo string, number int) (*Issue, error) {
+	return &Issue{Number: number, State: "OPEN"}, c.err
+}
+
+func (c *countingClient) PRDetail(org, repo string, number int) (PRDetailResult, error) {
+	return PRDetailResult{Title: "detail"}, c.err
+}
+
+func (c *countingClient) WorkflowRuns(org, repo, branch string, limit int) ([]WorkflowRun, error) {
+	return nil, c.err
+}
+
+func (c *countingClient) CreatePR(org, repo string, opts CreatePROptions) (*PR, error) {
+	return &PR{Number: 9, HeadRefName: opts.Head, State: "OPEN"}, c.err
+}
+
+func (c *countingClient) EditPRBody(org, repo string, number int, body string) error {
+	return c.err
+}
+
+func (c *countingClient) PRsETag(org, repo, etag string) (string, bool, error) {
+	c.mu.Lock()
+	c.probes = append(c.probes, repo)
+	c.mu.Unlock()
+	if c.modified || etag == "" {
+		return `"new"`, true, nil
+	}
+	return etag, false, nil
+}
+
+// pinCacheClock sets the cache clock to a time the test can move forward.
+func pinCacheClock(t *testing.T) *time.Time {
+	t.Helper()
+	now := time.Date(2025, 1, 20, 12, 0, 0, 0, time.UTC)
+	orig := nowFunc
+	nowFunc = func() time.Time { return now }
+	t.Cleanup(func() { nowFunc = orig })
+	return &now
+}
+
+func TestCachedClient_PRsForReposServesFreshEntries(t *testing.T) {
+	pinCacheClock(t)
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir())}
+
+	first := c.PRsForRepos("org", []string{"a", "b"})
+	second := c.PRsForRepos("org", []string{"a", "b", "c"})
+
+	if len(inner.batches) != 2 || !slices.Equal(inner.batches[1], []string{"c"}) {
+		t.Errorf("batches = %v, want [a b] then just [c]", inner.batches)
+	}
+	if second["a"].Open[0].Number != first["a"].Open[0].Number || len(second["a"].Merged) != 1 {
+		t.Errorf("cached a = %+v, want the fetched PRs", second["a"])
+	}
+}
+
+func TestCachedClient_ExpiredEntriesAreFetched(t *testing.T) {
+	now := pinCacheClock(t)
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir())}
+
+	c.PRsForRepos("org", []string{"a"})
+	*now = now.Add(2 * time.Minute)
+	c.PRsForRepos("org", []string{"a"})
+
+	if len(inner.batches) != 2 {
+		t.Errorf("got %d fetches, want 2 once the entry expired", len(inner.batches))
+	}
+}
+
+func TestCachedClient_ServeStaleRefreshesInBackground(t *testing.T) {
+	now := pinCacheClock(t)
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir()), ServeStale: true}
+
+	c.PRsForRepos("org", []string{"a"})
+	*now = now.Add(2 * time.Minute)
+	got := c.PRsForRepos("org", []string{"a"})
+	WaitForRefreshes()
+
+	if len(got["a"].Open) != 1 {
+		t.Errorf("stale result = %+v, want the cached PRs", got["a"])
+	}
+	// The first probe has no ETag to send, so it fetches and keeps the new one.
+	if len(inner.probes) != 1 || len(inner.batches) != 2 {
+		t.Fatalf("probes = %v, batches = %v; want one of each after the first fetch", inner.probes, inner.batches)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	c.PRsForRepos("org", []string{"a"})
+	WaitForRefreshes()
+	if len(inner.probes) != 2 || len(inner.batches) != 2 {
+		t.Errorf("probes = %v, batches = %v; want a 304 to skip the fetch", inner.probes, inner.batches)
+	}
+
+	// A 304 counts as a fresh check.
+	c.PRsForRepos("org", []string{"a"})
+	WaitForRefreshes()
+	if len(inner.probes) != 2 {
+		t.Errorf("probes = %v, want no probe right after a 304", inner.probes)
+	}
+}
+
+func TestCachedClient_RevalidationGivesWayToFullFetch(t *testing.T) {
+	now := pinCacheClock(t)
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir()), ServeStale: true}
+
+	c.PRsForRepos("org", []string{"a"})
+	*now = now.Add(revalidateFor)
+	c.PRsForRepos("org", []string{"a"})
+	WaitForRefreshes()
+
+	if len(inner.probes) != 0 || len(inner.batches) != 2 {
+		t.Errorf("probes = %v, batches = %v; want a full fetch without a probe", inner.probes, inner.batches)
+	}
+}
+
+func TestCachedClient_ErrorsAreNotCached(t *testing.T) {
+	pinCacheClock(t)
+	inner := &countingClient{err: errors.New("offline")}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir())}
+
+	if _, err := c.PRFromNumber("org", "repo", 5); err == nil {
+		t.Fatal("expected the client's error")
+	}
+	inner.err = nil
+	pr, err := c.PRFromNumber("org", "repo", 5)
+	if err != nil || pr.Number != 5 {
+		t.Errorf("PRFromNumber() = %+v, %v; want PR 5 from the client", pr, err)
+	}
+}
+
+func TestCachedClient_PRsFromNumbersFetchesOnlyMisses(t *testing.T) {
+	pinCacheClock(t)
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir())}
+
+	c.PRFromNumber("org", "repo", 5)
+	got, err := c.PRsFromNumbers("org", "repo", []int{5, 6, 7})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("PRsFromNumbers() = %+v, %v; want three PRs", got, err)
+	}
+	if len(inner.numbers) != 1 || !slices.Equal(inner.numbers[0], []int{6, 7}) {
+		t.Errorf("fetched %v, want one call for 6 and 7", inner.numbers)
+	}
+	c.PRsFromNumbers("org", "repo", []int{6, 7})
+	if len(inner.numbers) != 1 {
+		t.Errorf("fetched %v, want the second call served from the cache", inner.numbers)
+	}
+}
+
+func TestCachedClient_CreatePRAddsToCache(t *testing.T) {
+	pinCacheClock(t)
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir())}
+	c.Cache.SetUser("octocat")
+
+	pr, err := c.CreatePR("org", "repo", CreatePROptions{Head: "feat"})
+	if err != nil {
+		t.Fatalf("CreatePR() error: %v", err)
+	}
+	if pr.Author != "octocat" {
+		t.Errorf("Author = %q, want the cached login", pr.Author)
+	}
+	prs, _, _ := c.Cache.OpenPRs("org", "repo")
+	if len(prs) != 1 || prs[0].Number != 9 {
+		t.Errorf("cached PRs = %+v, want the new PR", prs)
+	}
+}
+
+func TestWithoutStale(t *testing.T) {
+	c := &CachedClient{Client: &countingClient{}, Cache: NewCache(t.TempDir()), ServeStale: true}
+	fresh, ok := WithoutStale(c).(*CachedClient)
+	if !ok || fresh.ServeStale || fresh.Cache != c.Cache {
+		t.Errorf("WithoutStale() = %+v, want the same cache without ServeStale", fresh)
+	}
+	if CacheFor(LiveClient{}) != nil {
+		t.Error("CacheFor(LiveClient{}) should be nil")
+	}
+}
+
+func TestCachedClient_OfflineServesAnyCachedEntry(t *testing.T) {
+	now := pinCacheClock(t)
+	inner := &countingClient{}
+	cache := NewCache(t.TempDir())
+	(&CachedClient{Client: inner, Cache: cache}).PRsForRepos("org", []string{"a"})
+	fetchedAt := *now
+	*now = now.Add(48 * time.Hour)
+
+	c := Offline(&CachedClient{Client: inner, Cache: cache})
+	got := c.PRsForRepos("org", []string{"a", "b"})
+
+	if len(inner.batches) != 1 {
+		t.Errorf("batches = %v, want no fetch offline", inner.batches)
+	}
+	if len(got["a"].Open) != 1 || !got["a"].AsOf.Equal(fetchedAt) {
+		t.Errorf("a = %+v, want the cached PRs as of %v", got["a"], fetchedAt)
+	}
+	if !errors.Is(got["b"].Err, ErrOffline) {
+		t.Errorf("b.Err = %v, want ErrOffline", got["b"].Err)
+	}
+}
+
+func TestCachedClient_OfflinePRFromNumberUsesCachedLists(t *testing.T) {
+	pinCacheClock(t)
+	inner := &countingClient{}
+	cache := NewCache(t.TempDir())
+	(&CachedClient{Client: inner, Cache: cache}).PRsForRepos("org", []string{"a"})
+
+	c := Offline(&CachedClient{Client: inner, Cache: cache})
+	pr, err := c.PRFromNumber("org", "a", 2)
+	if err != nil || pr.HeadRefName != "a-done" {
+		t.Errorf("PRFromNumber(2) = %+v, %v; want the merged PR from the cache", pr, err)
+	}
+	if _, err := c.PRFromNumber("org", "a", 3); !errors.Is(err, ErrOffline) {
+		t.Errorf("PRFromNumber(3) error = %v, want ErrOffline", err)
+	}
+	if _, err := c.CreatePR("org", "a", CreatePROptions{Head: "feat"}); !errors.Is(err, ErrOffline) {
+		t.Errorf("CreatePR() error = %v, want ErrOffline", err)
+	}
+}
+
+func TestCachedClient_DetectOffline(t *testing.T) {
+	pinCacheClock(t)
+	orig := forgeReachable
+	forgeReachable = func() bool { return false }
+	t.Cleanup(func() { forgeReachable = orig })
+
+	inner := &countingClient{}
+	c := &CachedClient{Client: inner, Cache: NewCache(t.TempDir()), DetectOffline: true}
+	if !IsOffline(c) {
+		t.Error("IsOffline() = false, want true when the forge is unreachable")
+	}
+	c.PRsForRepos("org", []string{"a"})
+	if len(inner.batches) != 0 {
+		t.Errorf("batches = %v, want no fetch", inner.batches)
+	}
+	if IsOffline(LiveClient{}) {
+		t.Error("IsOffline(LiveClient{}) should be false")
+	}
+}
@@ -4,9 +4,14 @@ package github
 type Client interface {
 	PRsForRepo(org, repo string) ([]PR, error)
 	MergedPRsForRepo(org, repo string) ([]PR, error)
+	PRsForRepos(org string, repos []string) map[string]RepoPRs
 	PRFromNumber(org, repo string, number int) (*PR, error)
+	PRsFromNumbers(org, repo string, numbers []int) (map[int]PR, error)
+	IssueFromNumber(org, repo string, number int) (*Issue, error)
 	PRDetail(org, repo string, number int) (PRDetailResult, error)
 	WorkflowRuns(org, repo, branch string, limit int) ([]WorkflowRun, error)
+	CreatePR(org, repo string, opts CreatePROptions) (*PR, error)
+	EditPRBody(org, repo string, number int, body string) error
 }
 
 // LiveClient calls the real gh CLI.
@@ -20,10 +25,22 @@ func (LiveClient) MergedPRsForRepo(org, repo string) ([]PR, error) {
 	return MergedPRsForRepo(org, repo)
 }
 
+func (LiveClient) PRsForRepos(org string, repos []string) map[string]RepoPRs {
+	return PRsForRepos(org, repos)
+}
+
 func (LiveClient) PRFromNumber(org, repo string, number int) (*PR, error) {
 	return PRFromNumber(org, repo, number)
 }
 
+func (LiveClient) PRsFromNumbers(org, repo string, numbers []int) (map[int]PR, error) {
+	return PRsFromNumbers(org, repo, numbers)
+}
+
+func (LiveClient) IssueFromNumber(org, repo string, number int) (*Issue, error) {
+	return IssueFromNumber(org, repo, number)
+}
+
 func (LiveClient) PRDetail(org, repo string, number int) (PRDetailResult, error) {
 	return PRDetail(org, repo, number)
 }
@@ -31,3 +48,11 @@ func (LiveClient) PRDetail(org, repo string, number int) (PRDetailResult, error)
 func (LiveClient) WorkflowRuns(org, repo, branch string, limit int) ([]WorkflowRun, error) {
 	return WorkflowRuns(org, repo, branch, limit)
 }
+
+func (LiveClient) CreatePR(org, repo string, opts CreatePROptions) (*PR, error) {
+	return CreatePR(org, repo, opts)
+}
+
+func (LiveClient) EditPRBody(org, repo string, number int, body string) error {
+	return EditPRBody(org, repo, number, body)
+}
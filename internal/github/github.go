@@ -3,8 +3,8 @@ package github
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"net/http"
+	"strconv"
 	"strings"
 
 	gh "github.com/cli/go-gh/v2"
@@ -31,6 +31,14 @@ type WorkflowRun struct {
 	CreatedAt  string `json:"createdAt"`
 }
 
+// Issue represents an issue a capsule was lifted from.
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	State  string `json:"state"` // OPEN or CLOSED
+	URL    string `json:"url"`
+}
+
 // CheckRun represents a single CI check.
 type CheckRun struct {
 	Name       string `json:"name"`
@@ -148,6 +156,108 @@ func PRFromNumber(org, repo string, number int) (*PR, error) {
 	return &pr, nil
 }
 
+// CreatePROptions describes a pull request to open.
+type CreatePROptions struct {
+	Head  string
+	Base  string
+	Title string
+	Body  string
+	Draft bool
+}
+
+// CreatePR opens a pull request and returns it. The head branch must
+// already be pushed.
+func CreatePR(org, repo string, opts CreatePROptions) (*PR, error) {
+	fullRepo := repoSlug(org, repo)
+	args := []string{
+		"pr", "create",
+		"--repo", fullRepo,
+		"--head", opts.Head,
+		"--base", opts.Base,
+		"--title", opts.Title,
+		"--body", opts.Body,
+	}
+	if opts.Draft {
+		args = append(args, "--draft")
+	}
+	stdOut, _, err := gh.Exec(args...)
+	if err != nil {
+		return nil, fmt.Errorf("gh pr create for %s: %w", fullRepo, err)
+	}
+
+	// gh prints the new PR's URL as the last line.
+	lines := strings.Split(strings.TrimSpace(stdOut.String()), "\n")
+	url := strings.TrimSpace(lines[len(lines)-1])
+	number, ok := prNumberFromURL(url)
+	if !ok {
+		return nil, fmt.Errorf("unexpected gh pr create output: %q", url)
+	}
+	return &PR{
+		Number:      number,
+		Title:       opts.Title,
+		HeadRefName: opts.Head,
+		State:       "OPEN",
+		URL:         url,
+	}, nil
+}
+
+// EditPRBody replaces the body of a pull request.
+func EditPRBody(org, repo string, number int, body string) error {
+	fullRepo := repoSlug(org, repo)
+	_, _, err := gh.Exec(
+		"pr", "edit",
+		fmt.Sprintf("%d", number),
+		"--repo", fullRepo,
+		"--body", body,
+	)
+	if err != nil {
+		return fmt.Errorf("gh pr edit %d for %s: %w", number, fullRepo, err)
+	}
+	return nil
+}
+
+// IssueFromNumber fetches an issue by number.
+func IssueFromNumber(org, repo string, number int) (*Issue, error) {
+	fullRepo := repoSlug(org, repo)
+	stdOut, _, err := gh.Exec(
+		"issue", "view",
+		fmt.Sprintf("%d", number),
+		"--repo", fullRepo,
+		"--json", "number,title,state,url",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gh issue view %d for %s: %w", number, fullRepo, err)
+	}
+
+	var issue Issue
+	if err := json.Unmarshal(stdOut.Bytes(), &issue); err != nil {
+		return nil, fmt.Errorf("parsing gh output: %w", err)
+	}
+	return &issue, nil
+}
+
+// CurrentUser returns the login of the user gh is authenticated as.
+func CurrentUser() (string, error) {
+	stdOut, _, err := gh.Exec("api", "user", "-q", ".login")
+	if err != nil {
+		return "", fmt.Errorf("gh api user: %w", err)
+	}
+	return strings.TrimSpace(stdOut.String()), nil
+}
+
+// prNumberFromURL extracts the number from a ".../pull/<n>" URL.
+func prNumberFromURL(url string) (int, bool) {
+	i := strings.LastIndex(url, "/pull/")
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(url[i+len("/pull/"):])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
 // PRDetailResult holds all data returned by PRDetail.
 type PRDetailResult struct {
 	Title   string
@@ -194,87 +304,51 @@ func PRDetail(org, repo string, number int) (PRDetailResult, error) {
 	}, nil
 }
 
-// CacheDir returns the directory used for PR cache files.
-func CacheDir() string {
-	return filepath.Join(os.TempDir(), "ws-pr-cache")
-}
-
-// WritePRCache writes PR data to a cache file atomically.
-// Errors are silently swallowed (best-effort).
-func WritePRCache(cacheDir, org, repo string, prs []PR) {
-	data, err := json.Marshal(prs)
-	if err != nil {
-		return
-	}
-	_ = os.MkdirAll(cacheDir, 0o755)
-	tmp := filepath.Join(cacheDir, fmt.Sprintf("%s-%s-prs.tmp", org, repo))
-	target := filepath.Join(cacheDir, fmt.Sprintf("%s-%s-prs.json", org, repo))
-	if err := os.WriteFile(tmp, data, 0o644); err != nil {
-		return
-	}
-	_ = os.Rename(tmp, target)
-}
-
-// ReadPRCache reads cached PR data for a repo.
-// Returns nil, nil on miss or corruption.
-func ReadPRCache(cacheDir, org, repo string) ([]PR, error) {
-	path := filepath.Join(cacheDir, fmt.Sprintf("%s-%s-prs.json", org, repo))
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, nil
+// PRsETag makes a conditional request for a repo's most recently updated
+// PR. GitHub answers 304 Not Modified, which doesn't count against the rate
+// limit, unless a PR was opened, pushed to, reviewed, merged or closed since
+// etag was returned. Check results don't touch the ETag.
+func PRsETag(org, repo, etag string) (string, bool, error) {
+	fullRepo := repoSlug(org, repo)
+	args := []string{"api", "--include", fmt.Sprintf("repos/%s/pulls?state=all&sort=updated&direction=desc&per_page=1", fullRepo)}
+	if etag != "" {
+		args = append(args, "-H", "If-None-Match: "+etag)
 	}
-	var prs []PR
-	if err := json.Unmarshal(data, &prs); err != nil {
-		return nil, nil
+	// gh exits non-zero on a 304 but still prints the response head.
+	stdOut, _, err := gh.Exec(args...)
+	status, header := parseResponseHead(stdOut.String())
+	switch status {
+	case http.StatusNotModified:
+		return etag, false, nil
+	case http.StatusOK:
+		return header.Get("ETag"), true, nil
 	}
-	return prs, nil
-}
-
-// WriteBranchCache writes worktree-to-branch mappings to a cache file.
-func WriteBranchCache(cacheDir, org, repo string, branches map[string]string) {
-	data, err := json.Marshal(branches)
-	if err != nil {
-		return
-	}
-	_ = os.MkdirAll(cacheDir, 0o755)
-	tmp := filepath.Join(cacheDir, fmt.Sprintf("%s-%s-branches.tmp", org, repo))
-	target := filepath.Join(cacheDir, fmt.Sprintf("%s-%s-branches.json", org, repo))
-	if err := os.WriteFile(tmp, data, 0o644); err != nil {
-		return
+	if err == nil {
+		err = fmt.Errorf("unexpected status %d", status)
 	}
-	_ = os.Rename(tmp, target)
+	return "", true, fmt.Errorf("gh api pulls for %s: %w", fullRepo, err)
 }
 
-// ReadBranchCache reads cached worktree-to-branch mappings.
-// Returns nil on miss or corruption.
-func ReadBranchCache(cacheDir, org, repo string) map[string]string {
-	path := filepath.Join(cacheDir, fmt.Sprintf("%s-%s-branches.json", org, repo))
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil
-	}
-	var branches map[string]string
-	if err := json.Unmarshal(data, &branches); err != nil {
-		return nil
+// parseResponseHead parses the status line and headers "gh api --include"
+// prints before the body.
+func parseResponseHead(out string) (int, http.Header) {
+	header := make(http.Header)
+	lines := strings.Split(out, "\n")
+	fields := strings.Fields(lines[0])
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return 0, header
 	}
-	return branches
-}
-
-// WriteUserCache writes the GitHub username to a cache file.
-func WriteUserCache(cacheDir, login string) {
-	_ = os.MkdirAll(cacheDir, 0o755)
-	path := filepath.Join(cacheDir, "gh-user.txt")
-	_ = os.WriteFile(path, []byte(login), 0o644)
-}
-
-// ReadUserCache reads the cached GitHub username. Returns "" on miss.
-func ReadUserCache(cacheDir string) string {
-	path := filepath.Join(cacheDir, "gh-user.txt")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return ""
+	status, _ := strconv.Atoi(fields[1])
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			break
+		}
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			header.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
 	}
-	return strings.TrimSpace(string(data))
+	return status, header
 }
 
 // extractAuthor extracts the author login from raw PR JSON.
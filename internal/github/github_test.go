@@ -2,8 +2,6 @@ package github
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
 	"testing"
 )
 
@@ -160,158 +158,43 @@ func TestExtractAuthor_InvalidJSON(t *testing.T) {
 	}
 }
 
-// --- PR cache function tests ---
+// --- prNumberFromURL tests ---
 
-func TestWritePRCache_And_ReadPRCache(t *testing.T) {
-	cacheDir := t.TempDir()
-	prs := []PR{
-		{Number: 1, Title: "First PR", HeadRefName: "feature-a"},
-		{Number: 2, Title: "Second PR", HeadRefName: "feature-b"},
+func TestPRNumberFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want int
+		ok   bool
+	}{
+		{"https://github.com/org/repo/pull/42", 42, true},
+		{"https://github.example.com/org/repo/pull/7", 7, true},
+		{"https://github.com/org/repo/issues/42", 0, false},
+		{"https://github.com/org/repo/pull/new", 0, false},
+		{"", 0, false},
 	}
-
-	WritePRCache(cacheDir, "my-org", "my-repo", prs)
-
-	got, err := ReadPRCache(cacheDir, "my-org", "my-repo")
-	if err != nil {
-		t.Fatalf("ReadPRCache() error: %v", err)
-	}
-	if len(got) != 2 {
-		t.Fatalf("got %d PRs, want 2", len(got))
-	}
-	if got[0].Title != "First PR" {
-		t.Errorf("PR[0].Title = %q, want %q", got[0].Title, "First PR")
-	}
-	if got[1].HeadRefName != "feature-b" {
-		t.Errorf("PR[1].HeadRefName = %q, want %q", got[1].HeadRefName, "feature-b")
-	}
-}
-
-func TestWritePRCache_PreservesAllFields(t *testing.T) {
-	cacheDir := t.TempDir()
-	prs := []PR{
-		{
-			Number:         42,
-			Title:          "My PR",
-			HeadRefName:    "my-branch",
-			State:          "OPEN",
-			ReviewDecision: "APPROVED",
-			StatusRollup:   "success",
-			URL:            "https://github.com/org/repo/pull/42",
-			Author:         "octocat",
-		},
-	}
-
-	WritePRCache(cacheDir, "org", "repo", prs)
-
-	got, err := ReadPRCache(cacheDir, "org", "repo")
-	if err != nil {
-		t.Fatalf("ReadPRCache() error: %v", err)
-	}
-	if len(got) != 1 {
-		t.Fatalf("got %d PRs, want 1", len(got))
-	}
-	pr := got[0]
-	if pr.Number != 42 {
-		t.Errorf("Number = %d, want 42", pr.Number)
-	}
-	if pr.State != "OPEN" {
-		t.Errorf("State = %q, want %q", pr.State, "OPEN")
-	}
-	if pr.ReviewDecision != "APPROVED" {
-		t.Errorf("ReviewDecision = %q, want %q", pr.ReviewDecision, "APPROVED")
-	}
-	if pr.StatusRollup != "success" {
-		t.Errorf("StatusRollup = %q, want %q", pr.StatusRollup, "success")
-	}
-	if pr.URL != "https://github.com/org/repo/pull/42" {
-		t.Errorf("URL = %q, want %q", pr.URL, "https://github.com/org/repo/pull/42")
-	}
-	if pr.Author != "octocat" {
-		t.Errorf("Author = %q, want %q", pr.Author, "octocat")
-	}
-}
-
-func TestReadPRCache_Miss(t *testing.T) {
-	cacheDir := t.TempDir()
-	got, err := ReadPRCache(cacheDir, "org", "repo")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-	if got != nil {
-		t.Errorf("expected nil on cache miss, got %v", got)
+	for _, tt := range tests {
+		got, ok := prNumberFromURL(tt.url)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("prNumberFromURL(%q) = %d, %v; want %d, %v", tt.url, got, ok, tt.want, tt.ok)
+		}
 	}
 }
 
-func TestReadPRCache_CorruptedFile(t *testing.T) {
-	cacheDir := t.TempDir()
-	path := filepath.Join(cacheDir, "org-repo-prs.json")
-	os.WriteFile(path, []byte("not json"), 0644)
+// --- parseResponseHead tests ---
 
-	got, err := ReadPRCache(cacheDir, "org", "repo")
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestParseResponseHead(t *testing.T) {
+	out := "HTTP/2.0 304 Not Modified\r\nEtag: W/\"abc\"\r\nX-Ratelimit-Remaining: 4999\r\n\r\n"
+	status, header := parseResponseHead(out)
+	if status != 304 {
+		t.Errorf("status = %d, want 304", status)
 	}
-	if got != nil {
-		t.Errorf("expected nil on corrupted cache, got %v", got)
+	if got := header.Get("ETag"); got != `W/"abc"` {
+		t.Errorf("ETag = %q, want %q", got, `W/"abc"`)
 	}
 }
 
-func TestWritePRCache_CreatesDirectory(t *testing.T) {
-	baseDir := t.TempDir()
-	cacheDir := filepath.Join(baseDir, "nested", "cache")
-
-	prs := []PR{{Number: 1, Title: "Test"}}
-	WritePRCache(cacheDir, "org", "repo", prs)
-
-	got, err := ReadPRCache(cacheDir, "org", "repo")
-	if err != nil {
-		t.Fatalf("ReadPRCache() error: %v", err)
-	}
-	if len(got) != 1 {
-		t.Fatalf("got %d PRs, want 1", len(got))
-	}
-}
-
-func TestWritePRCache_EmptySlice(t *testing.T) {
-	cacheDir := t.TempDir()
-	WritePRCache(cacheDir, "org", "repo", []PR{})
-
-	got, err := ReadPRCache(cacheDir, "org", "repo")
-	if err != nil {
-		t.Fatalf("ReadPRCache() error: %v", err)
-	}
-	if len(got) != 0 {
-		t.Errorf("got %d PRs, want 0", len(got))
-	}
-}
-
-// --- User cache function tests ---
-
-func TestWriteUserCache_And_ReadUserCache(t *testing.T) {
-	cacheDir := t.TempDir()
-	WriteUserCache(cacheDir, "octocat")
-
-	got := ReadUserCache(cacheDir)
-	if got != "octocat" {
-		t.Errorf("ReadUserCache() = %q, want %q", got, "octocat")
-	}
-}
-
-func TestReadUserCache_Miss(t *testing.T) {
-	cacheDir := t.TempDir()
-	got := ReadUserCache(cacheDir)
-	if got != "" {
-		t.Errorf("ReadUserCache() = %q, want empty on miss", got)
-	}
-}
-
-func TestCacheDir(t *testing.T) {
-	dir := CacheDir()
-	if dir == "" {
-		t.Error("CacheDir() should not be empty")
-	}
-	expected := filepath.Join(os.TempDir(), "ws-pr-cache")
-	if dir != expected {
-		t.Errorf("CacheDir() = %q, want %q", dir, expected)
+func TestParseResponseHead_NoHead(t *testing.T) {
+	if status, _ := parseResponseHead(""); status != 0 {
+		t.Errorf("status = %d, want 0", status)
 	}
 }
@@ -0,0 +1,266 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gh "github.com/cli/go-gh/v2"
+)
+
+// RepoPRs holds the open and recently merged PRs of one repo, as returned by
+// PRsForRepos. Err is set when that repo couldn't be fetched. AsOf is set
+// when the PRs came from the cache while offline, to when they were fetched.
+type RepoPRs struct {
+	Open   []PR
+	Merged []PR
+	Err    error
+	AsOf   time.Time
+}
+
+// reposPerQuery caps how many repos share one GraphQL query, keeping each
+// query well inside GitHub's node limits.
+const reposPerQuery = 10
+
+const (
+	openPRLimit   = 100
+	mergedPRLimit = 30
+)
+
+const prFields = `
+fragment prFields on PullRequest {
+  number
+  title
+  headRefName
+  state
+  reviewDecision
+  url
+  mergedAt
+  author { login }
+  commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+}`
+
+// PRsForRepos fetches open and recently merged PRs, with their check and
+// review state, for every repo using aliased GraphQL queries — one per
+// reposPerQuery repos, run concurrently — instead of two gh calls per repo.
+func PRsForRepos(org string, repos []string) map[string]RepoPRs {
+	result := make(map[string]RepoPRs, len(repos))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for chunk := range slices.Chunk(repos, reposPerQuery) {
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+			prs := queryRepoPRs(org, chunk)
+			mu.Lock()
+			for repo, r := range prs {
+				result[repo] = r
+			}
+			mu.Unlock()
+		}(chunk)
+	}
+	wg.Wait()
+	return result
+}
+
+// queryRepoPRs runs one aliased query for a handful of repos.
+func queryRepoPRs(org string, repos []string) map[string]RepoPRs {
+	args := []string{"api", "graphql", "-f", "query=" + buildPRsQuery(len(repos)), "-f", "owner=" + org}
+	for i, repo := range repos {
+		args = append(args, "-f", fmt.Sprintf("r%d=%s", i, repo))
+	}
+	stdOut, _, err := gh.Exec(args...)
+
+	// gh exits non-zero when any repo errors, but still prints the data it
+	// got for the others.
+	if stdOut.Len() == 0 {
+		if err == nil {
+			err = errors.New("empty response")
+		}
+		result := make(map[string]RepoPRs, len(repos))
+		for _, repo := range repos {
+			result[repo] = RepoPRs{Err: fmt.Errorf("gh api graphql for %s/%s: %w", org, repo, err)}
+		}
+		return result
+	}
+	return parsePRsResponse(stdOut.Bytes(), org, repos)
+}
+
+// buildPRsQuery builds a query with one aliased repository field (r0, r1,
+// ...) per repo, each selecting its open and merged PRs.
+func buildPRsQuery(n int) string {
+	var b strings.Builder
+	b.WriteString("query($owner: String!")
+	for i := range n {
+		fmt.Fprintf(&b, ", $r%d: String!", i)
+	}
+	b.WriteString(") {\n")
+	for i := range n {
+		fmt.Fprintf(&b, "  r%d: repository(owner: $owner, name: $r%d) {\n", i, i)
+		fmt.Fprintf(&b, "    open: pullRequests(states: OPEN, first: %d, orderBy: {field: CREATED_AT, direction: DESC}) { nodes { ...prFields } }\n", openPRLimit)
+		fmt.Fprintf(&b, "    merged: pullRequests(states: MERGED, first: %d, orderBy: {field: UPDATED_AT, direction: DESC}) { nodes { ...prFields } }\n", mergedPRLimit)
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	b.WriteString(prFields)
+	return b.String()
+}
+
+type graphQLPR struct {
+	Number         int    `json:"number"`
+	Title          string `json:"title"`
+	HeadRefName    string `json:"headRefName"`
+	State          string `json:"state"`
+	ReviewDecision string `json:"reviewDecision"`
+	URL            string `json:"url"`
+	MergedAt       string `json:"mergedAt"`
+	Author         *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Commits struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string `json:"state"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+type graphQLRepo struct {
+	Open struct {
+		Nodes []graphQLPR `json:"nodes"`
+	} `json:"open"`
+	Merged struct {
+		Nodes []graphQLPR `json:"nodes"`
+	} `json:"merged"`
+}
+
+// parsePRsResponse maps an aliased query's response back to repos. A repo
+// whose alias is null gets the matching error from the response.
+func parsePRsResponse(data []byte, org string, repos []string) map[string]RepoPRs {
+	var resp struct {
+		Data   map[string]*graphQLRepo `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+			Path    []any  `json:"path"`
+		} `json:"errors"`
+	}
+	result := make(map[string]RepoPRs, len(repos))
+	if err := json.Unmarshal(data, &resp); err != nil {
+		for _, repo := range repos {
+			result[repo] = RepoPRs{Err: fmt.Errorf("parsing gh output: %w", err)}
+		}
+		return result
+	}
+
+	for i, repo := range repos {
+		alias := fmt.Sprintf("r%d", i)
+		r := resp.Data[alias]
+		if r == nil {
+			msg := "no data returned"
+			for _, e := range resp.Errors {
+				if len(e.Path) > 0 && e.Path[0] == any(alias) {
+					msg = e.Message
+					break
+				}
+			}
+			result[repo] = RepoPRs{Err: fmt.Errorf("gh api graphql for %s/%s: %s", org, repo, msg)}
+			continue
+		}
+		result[repo] = RepoPRs{Open: convertPRs(r.Open.Nodes), Merged: convertPRs(r.Merged.Nodes)}
+	}
+	return result
+}
+
+// PRsFromNumbers fetches several PRs of one repo by number with one aliased
+// query, instead of a gh call per PR. Numbers with no PR are left out.
+func PRsFromNumbers(org, repo string, numbers []int) (map[int]PR, error) {
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	stdOut, _, err := gh.Exec("api", "graphql", "-f", "query="+buildPRNumbersQuery(numbers), "-f", "owner="+org, "-f", "repo="+repo)
+	// As with PRsForRepos, gh exits non-zero when any alias errors.
+	if stdOut.Len() == 0 {
+		if err == nil {
+			err = errors.New("empty response")
+		}
+		return nil, fmt.Errorf("gh api graphql for %s: %w", repoSlug(org, repo), err)
+	}
+	return parsePRNumbersResponse(stdOut.Bytes(), numbers)
+}
+
+// buildPRNumbersQuery builds a query with one aliased pullRequest field (p0,
+// p1, ...) per number.
+func buildPRNumbersQuery(numbers []int) string {
+	var b strings.Builder
+	b.WriteString("query($owner: String!, $repo: String!) {\n")
+	b.WriteString("  repository(owner: $owner, name: $repo) {\n")
+	for i, n := range numbers {
+		fmt.Fprintf(&b, "    p%d: pullRequest(number: %d) { ...prFields }\n", i, n)
+	}
+	b.WriteString("  }\n}\n")
+	b.WriteString(prFields)
+	return b.String()
+}
+
+func parsePRNumbersResponse(data []byte, numbers []int) (map[int]PR, error) {
+	var resp struct {
+		Data struct {
+			Repository map[string]*graphQLPR `json:"repository"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parsing gh output: %w", err)
+	}
+	result := make(map[int]PR, len(numbers))
+	for i, n := range numbers {
+		if node := resp.Data.Repository[fmt.Sprintf("p%d", i)]; node != nil {
+			result[n] = convertPRs([]graphQLPR{*node})[0]
+		}
+	}
+	return result, nil
+}
+
+func convertPRs(nodes []graphQLPR) []PR {
+	prs := make([]PR, len(nodes))
+	for i, n := range nodes {
+		prs[i] = PR{
+			Number:         n.Number,
+			Title:          n.Title,
+			HeadRefName:    n.HeadRefName,
+			State:          n.State,
+			ReviewDecision: n.ReviewDecision,
+			URL:            n.URL,
+			MergedAt:       n.MergedAt,
+		}
+		if n.Author != nil {
+			prs[i].Author = n.Author.Login
+		}
+		if len(n.Commits.Nodes) > 0 {
+			if rollup := n.Commits.Nodes[0].Commit.StatusCheckRollup; rollup != nil {
+				prs[i].StatusRollup = rollupStatus(rollup.State)
+			}
+		}
+	}
+	return prs
+}
+
+// rollupStatus maps a GraphQL StatusState to the statuses deriveStatus
+// produces from gh's statusCheckRollup.
+func rollupStatus(state string) string {
+	switch state {
+	case "SUCCESS":
+		return "success"
+	case "FAILURE", "ERROR":
+		return "failure"
+	case "PENDING", "EXPECTED":
+		return "pending"
+	}
+	return ""
+}
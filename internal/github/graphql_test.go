@@ -0,0 +1,147 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- buildPRsQuery tests ---
+
+func TestBuildPRsQuery_AliasesEachRepo(t *testing.T) {
+	q := buildPRsQuery(3)
+	for _, want := range []string{
+		"query($owner: String!, $r0: String!, $r1: String!, $r2: String!)",
+		"r0: repository(owner: $owner, name: $r0)",
+		"r2: repository(owner: $owner, name: $r2)",
+		"fragment prFields on PullRequest",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("query missing %q:\n%s", want, q)
+		}
+	}
+	if strings.Contains(q, "$r3") {
+		t.Errorf("query has an alias for a fourth repo:\n%s", q)
+	}
+}
+
+// --- parsePRsResponse tests ---
+
+func TestParsePRsResponse(t *testing.T) {
+	data := []byte(`{
+		"data": {
+			"r0": {
+				"open": {"nodes": [{
+					"number": 42,
+					"title": "Add login",
+					"headRefName": "feat-login",
+					"state": "OPEN",
+					"reviewDecision": "APPROVED",
+					"url": "https://github.com/acme/frontend/pull/42",
+					"author": {"login": "alice"},
+					"commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "FAILURE"}}}]}
+				}]},
+				"merged": {"nodes": [{
+					"number": 40,
+					"title": "Fix header",
+					"headRefName": "fix-header",
+					"state": "MERGED",
+					"mergedAt": "2025-01-15T10:30:00Z",
+					"author": null,
+					"commits": {"nodes": [{"commit": {"statusCheckRollup": null}}]}
+				}]}
+			},
+			"r1": null
+		},
+		"errors": [
+			{"message": "Could not resolve to a Repository with the name 'acme/gone'.", "path": ["r1"]}
+		]
+	}`)
+
+	got := parsePRsResponse(data, "acme", []string{"frontend", "gone"})
+
+	fe := got["frontend"]
+	if fe.Err != nil {
+		t.Fatalf("frontend: unexpected error %v", fe.Err)
+	}
+	if len(fe.Open) != 1 || len(fe.Merged) != 1 {
+		t.Fatalf("frontend: got %d open, %d merged; want 1, 1", len(fe.Open), len(fe.Merged))
+	}
+	open := fe.Open[0]
+	if open.Number != 42 || open.HeadRefName != "feat-login" || open.Author != "alice" {
+		t.Errorf("open PR = %+v", open)
+	}
+	if open.ReviewDecision != "APPROVED" || open.StatusRollup != "failure" {
+		t.Errorf("open PR review/status = %q/%q, want APPROVED/failure", open.ReviewDecision, open.StatusRollup)
+	}
+	merged := fe.Merged[0]
+	if merged.MergedAt != "2025-01-15T10:30:00Z" || merged.Author != "" || merged.StatusRollup != "" {
+		t.Errorf("merged PR = %+v", merged)
+	}
+
+	gone := got["gone"]
+	if gone.Err == nil || !strings.Contains(gone.Err.Error(), "Could not resolve") {
+		t.Errorf("gone: err = %v, want the repo's GraphQL error", gone.Err)
+	}
+}
+
+func TestParsePRsResponse_InvalidJSON(t *testing.T) {
+	got := parsePRsResponse([]byte("not json"), "acme", []string{"a", "b"})
+	for _, repo := range []string{"a", "b"} {
+		if got[repo].Err == nil {
+			t.Errorf("%s: expected an error", repo)
+		}
+	}
+}
+
+// --- PRsFromNumbers tests ---
+
+func TestBuildPRNumbersQuery_AliasesEachNumber(t *testing.T) {
+	q := buildPRNumbersQuery([]int{7, 12})
+	for _, want := range []string{
+		"repository(owner: $owner, name: $repo)",
+		"p0: pullRequest(number: 7) { ...prFields }",
+		"p1: pullRequest(number: 12) { ...prFields }",
+		"fragment prFields on PullRequest",
+	} {
+		if !strings.Contains(q, want) {
+			t.Errorf("query missing %q:\n%s", want, q)
+		}
+	}
+}
+
+func TestParsePRNumbersResponse(t *testing.T) {
+	data := []byte(`{
+		"data": {
+			"repository": {
+				"p0": {"number": 7, "headRefName": "feat-a", "state": "MERGED", "author": {"login": "alice"}},
+				"p1": null
+			}
+		},
+		"errors": [{"message": "Could not resolve to a PullRequest with the number of 12."}]
+	}`)
+	got, err := parsePRNumbersResponse(data, []int{7, 12})
+	if err != nil {
+		t.Fatalf("parsePRNumbersResponse() error: %v", err)
+	}
+	if len(got) != 1 || got[7].HeadRefName != "feat-a" || got[7].State != "MERGED" || got[7].Author != "alice" {
+		t.Errorf("got %+v, want only PR 7", got)
+	}
+}
+
+// --- rollupStatus tests ---
+
+func TestRollupStatus(t *testing.T) {
+	tests := map[string]string{
+		"SUCCESS":  "success",
+		"FAILURE":  "failure",
+		"ERROR":    "failure",
+		"PENDING":  "pending",
+		"EXPECTED": "pending",
+		"":         "",
+	}
+	for state, want := range tests {
+		if got := rollupStatus(state); got != want {
+			t.Errorf("rollupStatus(%q) = %q, want %q", state, got, want)
+		}
+	}
+}
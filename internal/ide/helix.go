@@ -0,0 +1,63 @@
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	helixIgnoreStart = "# ws:hidden"
+	helixIgnoreEnd   = "# /ws:hidden"
+)
+
+// GenerateHelix keeps a section of .helix/ignore listing the directories that
+// aren't boarded capsules, so Helix's file picker and global search only see
+// the board. Lines outside the section are left alone. No-op if the .helix/
+// directory doesn't exist.
+func GenerateHelix(root string, boarded map[string][]string) error {
+	dir := filepath.Join(root, ".helix")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	path := filepath.Join(dir, "ignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	out := withHelixSection(string(data), hiddenPaths(root, boarded))
+	if out == string(data) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(out), 0644)
+}
+
+// withHelixSection returns content with the ws section set to paths,
+// replacing any section ws added before.
+func withHelixSection(content string, paths []string) string {
+	if start := strings.Index(content, helixIgnoreStart+"\n"); start >= 0 {
+		if end := strings.Index(content[start:], helixIgnoreEnd); end >= 0 {
+			content = content[:start] + strings.TrimPrefix(content[start+end+len(helixIgnoreEnd):], "\n")
+		}
+	}
+	content = strings.TrimRight(content, "\n")
+	if len(paths) == 0 {
+		if content == "" {
+			return ""
+		}
+		return content + "\n"
+	}
+
+	var b strings.Builder
+	if content != "" {
+		b.WriteString(content + "\n\n")
+	}
+	b.WriteString(helixIgnoreStart + "\n")
+	b.WriteString("# Generated by ws: directories that aren't boarded capsules.\n")
+	for _, p := range paths {
+		b.WriteString("/" + p + "/\n")
+	}
+	b.WriteString(helixIgnoreEnd + "\n")
+	return b.String()
+}
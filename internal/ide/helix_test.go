@@ -0,0 +1,59 @@
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateHelix_KeepsUserLines(t *testing.T) {
+	root := t.TempDir()
+	makeCapsules(t, root, "api", ".ground", "old")
+	os.MkdirAll(filepath.Join(root, ".helix"), 0755)
+	path := filepath.Join(root, ".helix", "ignore")
+	os.WriteFile(path, []byte("node_modules/\n"), 0644)
+
+	boarded := map[string][]string{"api": {".ground"}}
+	if err := GenerateHelix(root, boarded); err != nil {
+		t.Fatalf("GenerateHelix() error: %v", err)
+	}
+	want := `node_modules/
+
+# ws:hidden
+# Generated by ws: directories that aren't boarded capsules.
+/repos/api/.bare/
+/repos/api/old/
+# /ws:hidden
+`
+	data, _ := os.ReadFile(path)
+	if string(data) != want {
+		t.Errorf("ignore =\n%s\nwant\n%s", data, want)
+	}
+
+	// Boarding the other capsule rewrites the section in place.
+	boarded["api"] = append(boarded["api"], "old")
+	if err := GenerateHelix(root, boarded); err != nil {
+		t.Fatalf("GenerateHelix() error: %v", err)
+	}
+	want = `node_modules/
+
+# ws:hidden
+# Generated by ws: directories that aren't boarded capsules.
+/repos/api/.bare/
+# /ws:hidden
+`
+	data, _ = os.ReadFile(path)
+	if string(data) != want {
+		t.Errorf("ignore =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestGenerateHelix_NoDir(t *testing.T) {
+	root := t.TempDir()
+	if err := GenerateHelix(root, map[string][]string{"api": {"main"}}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".helix")); !os.IsNotExist(err) {
+		t.Error(".helix/ should not have been created")
+	}
+}
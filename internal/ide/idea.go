@@ -13,14 +13,49 @@ import (
 // and .idea/jb-workspace.xml.
 // No-op if .idea/ directory doesn't exist.
 func GenerateIDEA(root string, boarded map[string][]string, org string) error {
-	ideaDir := filepath.Join(root, ".idea")
-	if _, err := os.Stat(ideaDir); os.IsNotExist(err) {
+	if _, err := os.Stat(filepath.Join(root, ".idea")); os.IsNotExist(err) {
 		return nil
 	}
+	return writeIDEAProject(root, root, boarded, org)
+}
+
+// GenerateCapsuleIDEA sets up an IntelliJ project in dir with one capsule as
+// its only module, for opening it in a window of its own. The module starts
+// from the repo's .iml in the shared project when there is one, so SDK and
+// facet config carry over.
+func GenerateCapsuleIDEA(root, dir, repo, capsule, org string, displayNames map[string]string) error {
+	ideaDir := filepath.Join(dir, ".idea")
+	if err := os.MkdirAll(ideaDir, 0755); err != nil {
+		return err
+	}
+	namePath := filepath.Join(ideaDir, ".name")
+	if _, err := os.Stat(namePath); os.IsNotExist(err) {
+		name := repo
+		if dn, ok := displayNames[repo]; ok {
+			name = dn
+		}
+		os.WriteFile(namePath, []byte(name+" ("+capsule+")"), 0644)
+	}
+	return writeIDEAProject(root, dir, map[string][]string{repo: {capsule}}, org)
+}
 
+// writeIDEAProject writes the .idea of the project in projectDir for the
+// boarded capsules of the workspace at root.
+func writeIDEAProject(root, projectDir string, boarded map[string][]string, org string) error {
+	ideaDir := filepath.Join(projectDir, ".idea")
 	modulesDir := filepath.Join(ideaDir, "modules")
 	os.MkdirAll(modulesDir, 0755)
 
+	// Capsules are found relative to the project and module directories.
+	toRoot, err := filepath.Rel(projectDir, root)
+	if err != nil {
+		return err
+	}
+	reposPath := "repos/"
+	if toRoot != "." {
+		reposPath = filepath.ToSlash(toRoot) + "/repos/"
+	}
+
 	// Sort repos for deterministic output
 	repos := make([]string, 0, len(boarded))
 	for repo := range boarded {
@@ -43,6 +78,9 @@ func GenerateIDEA(root string, boarded map[string][]string, org string) error {
 	for _, repo := range repos {
 		// Find an existing .iml for this repo to use as a template (preserves SDK/facet config)
 		repoTemplate := findRepoIMLTemplate(modulesDir, repo)
+		if repoTemplate == "" && projectDir != root {
+			repoTemplate = findRepoIMLTemplate(filepath.Join(root, ".idea", "modules"), repo)
+		}
 
 		for _, capsule := range boarded[repo] {
 			imlName := repo + "-" + capsule + ".iml"
@@ -54,7 +92,7 @@ func GenerateIDEA(root string, boarded map[string][]string, org string) error {
 				imlName, imlName))
 
 			// Write .iml file — use existing template for this repo if available
-			contentURL := fmt.Sprintf("file://$MODULE_DIR$/../../repos/%s/%s", repo, capsule)
+			contentURL := fmt.Sprintf("file://$MODULE_DIR$/../../%s%s/%s", reposPath, repo, capsule)
 			iml := imlFromTemplate(repoTemplate, contentURL)
 			if err := os.WriteFile(filepath.Join(modulesDir, imlName), []byte(iml), 0644); err != nil {
 				return err
@@ -62,8 +100,8 @@ func GenerateIDEA(root string, boarded map[string][]string, org string) error {
 
 			// VCS entry
 			vcsEntries = append(vcsEntries, fmt.Sprintf(
-				`    <mapping directory="$PROJECT_DIR$/repos/%s/%s" vcs="Git" />`,
-				repo, capsule))
+				`    <mapping directory="$PROJECT_DIR$/%s%s/%s" vcs="Git" />`,
+				reposPath, repo, capsule))
 		}
 	}
 
@@ -99,8 +137,8 @@ func GenerateIDEA(root string, boarded map[string][]string, org string) error {
 		remoteURL := fmt.Sprintf("https://github.com/%s/%s.git", org, repo)
 		for _, capsule := range boarded[repo] {
 			projectEntries = append(projectEntries, fmt.Sprintf(
-				"    <project name=%q path=\"$PROJECT_DIR$/repos/%s/%s\">\n      <vcs id=\"Git\" remoteUrl=%q />\n    </project>",
-				capsule, repo, capsule, remoteURL))
+				"    <project name=%q path=\"$PROJECT_DIR$/%s%s/%s\">\n      <vcs id=\"Git\" remoteUrl=%q />\n    </project>",
+				capsule, reposPath, repo, capsule, remoteURL))
 		}
 	}
 	jbWorkspaceXML := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
@@ -147,7 +185,7 @@ func findRepoIMLTemplate(modulesDir, repo string) string {
 // If template is non-empty, replaces the content URL in it. Otherwise generates a bare default.
 func imlFromTemplate(template, contentURL string) string {
 	if template != "" {
-		return contentURLRe.ReplaceAllString(template, fmt.Sprintf(`<content url="%s"`, contentURL))
+		return contentURLRe.ReplaceAllLiteralString(template, fmt.Sprintf(`<content url="%s"`, contentURL))
 	}
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <module type="WEB_MODULE" version="4">
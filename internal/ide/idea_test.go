@@ -159,3 +159,33 @@ func TestGenerateIDEA_DoesNotTouchWorkspaceXML(t *testing.T) {
 		t.Error("workspace.xml was modified")
 	}
 }
+
+func TestGenerateCapsuleIDEA_UsesSharedTemplate(t *testing.T) {
+	root := t.TempDir()
+	sharedModules := filepath.Join(root, ".idea", "modules")
+	os.MkdirAll(sharedModules, 0755)
+	os.WriteFile(filepath.Join(sharedModules, "api-main.iml"),
+		[]byte(`<module type="JAVA_MODULE"><content url="file://old" /></module>`), 0644)
+	dir := filepath.Join(root, ".windows", "api", "feature-x")
+
+	if err := GenerateCapsuleIDEA(root, dir, "api", "feature-x", "test-org", nil); err != nil {
+		t.Fatalf("GenerateCapsuleIDEA() error: %v", err)
+	}
+
+	iml, err := os.ReadFile(filepath.Join(dir, ".idea", "modules", "api-feature-x.iml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<module type="JAVA_MODULE"><content url="file://$MODULE_DIR$/../../../../../repos/api/feature-x" /></module>`
+	if string(iml) != want {
+		t.Errorf("iml = %s, want %s", iml, want)
+	}
+	vcs, _ := os.ReadFile(filepath.Join(dir, ".idea", "vcs.xml"))
+	if !strings.Contains(string(vcs), `$PROJECT_DIR$/../../../repos/api/feature-x`) {
+		t.Errorf("vcs.xml = %s", vcs)
+	}
+	name, _ := os.ReadFile(filepath.Join(dir, ".idea", ".name"))
+	if string(name) != "api (feature-x)" {
+		t.Errorf(".name = %q", name)
+	}
+}
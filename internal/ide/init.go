@@ -0,0 +1,62 @@
+package ide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// TemplateDir is where a workspace's config repo can keep its own starting
+// files for each editor, laid out as they are at the workspace root, e.g.
+// ide/workspace.code-workspace or ide/.idea/.
+const TemplateDir = "ide"
+
+// IsSetUp reports whether the editor's files exist in root.
+func (g Generator) IsSetUp(root string) bool {
+	_, err := os.Stat(filepath.Join(root, g.Path))
+	return err == nil
+}
+
+// Init sets up the editor's files in root and generates them from b. The
+// files are copied from TemplateDir when the config repo has them, and
+// otherwise start out blank. Files that already exist are only regenerated.
+func (g Generator) Init(root string, b Board) error {
+	if !g.IsSetUp(root) {
+		if err := g.scaffold(root); err != nil {
+			return fmt.Errorf("%s: %w", g.Name, err)
+		}
+	}
+	if err := g.Generate(root, b); err != nil {
+		return fmt.Errorf("%s: %w", g.Name, err)
+	}
+	return nil
+}
+
+func (g Generator) scaffold(root string) error {
+	dst := filepath.Join(root, g.Path)
+	src := filepath.Join(root, TemplateDir, g.Path)
+	info, err := os.Stat(src)
+	switch {
+	case err == nil && info.IsDir():
+		return os.CopyFS(dst, os.DirFS(src))
+	case err == nil:
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, data, 0644)
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if g.Blank == "" {
+		return os.MkdirAll(dst, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, []byte(g.Blank), 0644)
+}
@@ -0,0 +1,68 @@
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratorInit_Blank(t *testing.T) {
+	root := t.TempDir()
+	b := Board{Boarded: map[string][]string{"api": {"main"}}}
+	for _, name := range GeneratorNames() {
+		g, _ := LookupGenerator(name)
+		if g.IsSetUp(root) {
+			t.Fatalf("%s is set up before Init", name)
+		}
+		if err := g.Init(root, b); err != nil {
+			t.Fatalf("%s: Init() error: %v", name, err)
+		}
+		if !g.IsSetUp(root) {
+			t.Errorf("%s is not set up after Init", name)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, "workspace.code-workspace"))
+	if !strings.Contains(string(data), `"path": "repos/api/main"`) {
+		t.Errorf("workspace file wasn't generated:\n%s", data)
+	}
+}
+
+func TestGeneratorInit_Template(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, TemplateDir, ".idea", "modules"), 0755)
+	os.WriteFile(filepath.Join(root, TemplateDir, ".idea", "misc.xml"), []byte("<project/>"), 0644)
+	os.WriteFile(filepath.Join(root, TemplateDir, "workspace.code-workspace"),
+		[]byte("{\n  // from the team\n  \"settings\": {\"editor.tabSize\": 2}\n}\n"), 0644)
+
+	b := Board{Boarded: map[string][]string{"api": {"main"}}, Org: "test-org"}
+	for _, name := range []string{"vscode", "idea"} {
+		g, _ := LookupGenerator(name)
+		if err := g.Init(root, b); err != nil {
+			t.Fatalf("%s: Init() error: %v", name, err)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, "workspace.code-workspace"))
+	for _, want := range []string{"// from the team", `"editor.tabSize": 2`, `"path": "repos/api/main"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("workspace file missing %s:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".idea", "misc.xml")); err != nil {
+		t.Error("misc.xml wasn't copied from the template")
+	}
+	if _, err := os.Stat(filepath.Join(root, ".idea", "modules", "api-main.iml")); err != nil {
+		t.Error("api-main.iml wasn't generated")
+	}
+
+	// A second Init leaves what's there and regenerates it.
+	g, _ := LookupGenerator("vscode")
+	if err := g.Init(root, b); err != nil {
+		t.Fatalf("Init() error: %v", err)
+	}
+	if again, _ := os.ReadFile(filepath.Join(root, "workspace.code-workspace")); string(again) != string(data) {
+		t.Errorf("second Init changed the file:\n%s", again)
+	}
+}
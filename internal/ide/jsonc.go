@@ -0,0 +1,513 @@
+package ide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSONC is JSON with comments and trailing commas, which VS Code writes and
+// reads for its settings and workspace files. Rather than round-tripping the
+// document through a parser, which would lose the comments, ws edits the
+// bytes of a single top-level member and leaves everything else untouched.
+
+// jsoncMember locates a member of the top-level object.
+type jsoncMember struct {
+	keyStart   int // offset of the key's opening quote
+	valueStart int
+	valueEnd   int // offset just past the value
+}
+
+// jsoncObject describes the top-level object of a JSONC document.
+type jsoncObject struct {
+	open    int // offset of '{'
+	close   int // offset of '}'
+	members map[string]jsoncMember
+	first   int // keyStart of the first member, or -1
+}
+
+// jsoncError reports where a JSONC document stopped making sense.
+type jsoncError struct {
+	line, col int
+	msg       string
+}
+
+func (e *jsoncError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.line, e.col, e.msg)
+}
+
+type jsoncScanner struct {
+	data []byte
+	pos  int
+}
+
+func (s *jsoncScanner) errorf(format string, args ...any) error {
+	line, col := 1, 1
+	for _, c := range s.data[:min(s.pos, len(s.data))] {
+		if c == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return &jsoncError{line: line, col: col, msg: fmt.Sprintf(format, args...)}
+}
+
+// skip moves past whitespace and comments.
+func (s *jsoncScanner) skip() error {
+	for s.pos < len(s.data) {
+		switch c := s.data[s.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			s.pos++
+		case bytes.HasPrefix(s.data[s.pos:], []byte("//")):
+			if i := bytes.IndexByte(s.data[s.pos:], '\n'); i >= 0 {
+				s.pos += i + 1
+			} else {
+				s.pos = len(s.data)
+			}
+		case bytes.HasPrefix(s.data[s.pos:], []byte("/*")):
+			i := bytes.Index(s.data[s.pos+2:], []byte("*/"))
+			if i < 0 {
+				return s.errorf("unterminated comment")
+			}
+			s.pos += i + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// peek skips whitespace and comments and returns the next byte, or 0 at the
+// end of the document.
+func (s *jsoncScanner) peek() (byte, error) {
+	if err := s.skip(); err != nil {
+		return 0, err
+	}
+	if s.pos >= len(s.data) {
+		return 0, nil
+	}
+	return s.data[s.pos], nil
+}
+
+func (s *jsoncScanner) expect(c byte) error {
+	next, err := s.peek()
+	if err != nil {
+		return err
+	}
+	if next != c {
+		return s.errorf("expected %q", c)
+	}
+	s.pos++
+	return nil
+}
+
+// str reads a string literal.
+func (s *jsoncScanner) str() (string, error) {
+	if next, err := s.peek(); err != nil {
+		return "", err
+	} else if next != '"' {
+		return "", s.errorf("expected a string")
+	}
+	start := s.pos
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos++
+		case '\n':
+			return "", s.errorf("newline in string")
+		case '"':
+			s.pos++
+			var v string
+			if err := json.Unmarshal(s.data[start:s.pos], &v); err != nil {
+				return "", s.errorf("invalid string: %v", err)
+			}
+			return v, nil
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
+
+// value moves past one value of any kind.
+func (s *jsoncScanner) value() error {
+	next, err := s.peek()
+	if err != nil {
+		return err
+	}
+	switch next {
+	case '{':
+		_, err := s.object(nil)
+		return err
+	case '[':
+		return s.array(nil)
+	case '"':
+		_, err := s.str()
+		return err
+	case 0:
+		return s.errorf("unexpected end of file")
+	}
+	start := s.pos
+	for s.pos < len(s.data) && strings.IndexByte("+-.0123456789Eaeflnrstu", s.data[s.pos]) >= 0 {
+		s.pos++
+	}
+	if s.pos == start || !json.Valid(s.data[start:s.pos]) {
+		s.pos = start
+		return s.errorf("unexpected %q", next)
+	}
+	return nil
+}
+
+// object reads an object, recording where each member is when members is
+// non-nil. It returns the keyStart of the first member, or -1.
+func (s *jsoncScanner) object(members map[string]jsoncMember) (int, error) {
+	first := -1
+	if err := s.expect('{'); err != nil {
+		return first, err
+	}
+	for {
+		next, err := s.peek()
+		if err != nil {
+			return first, err
+		}
+		if next == '}' {
+			s.pos++
+			return first, nil
+		}
+		keyStart := s.pos
+		if first < 0 {
+			first = keyStart
+		}
+		key, err := s.str()
+		if err != nil {
+			return first, err
+		}
+		if err := s.expect(':'); err != nil {
+			return first, err
+		}
+		if _, err := s.peek(); err != nil {
+			return first, err
+		}
+		valueStart := s.pos
+		if err := s.value(); err != nil {
+			return first, err
+		}
+		if members != nil {
+			members[key] = jsoncMember{keyStart: keyStart, valueStart: valueStart, valueEnd: s.pos}
+		}
+		if next, err = s.peek(); err != nil {
+			return first, err
+		}
+		switch next {
+		case ',':
+			s.pos++
+		case '}':
+		default:
+			return first, s.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// array reads an array, appending the span of each element to elems when
+// elems is non-nil.
+func (s *jsoncScanner) array(elems *[][2]int) error {
+	if err := s.expect('['); err != nil {
+		return err
+	}
+	for {
+		next, err := s.peek()
+		if err != nil {
+			return err
+		}
+		if next == ']' {
+			s.pos++
+			return nil
+		}
+		start := s.pos
+		if err := s.value(); err != nil {
+			return err
+		}
+		if elems != nil {
+			*elems = append(*elems, [2]int{start, s.pos})
+		}
+		if next, err = s.peek(); err != nil {
+			return err
+		}
+		switch next {
+		case ',':
+			s.pos++
+		case ']':
+		default:
+			return s.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// parseJSONCObject reads a JSONC document whose root is an object.
+func parseJSONCObject(data []byte) (*jsoncObject, error) {
+	s := &jsoncScanner{data: data}
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		s.pos = 3
+	}
+	if next, err := s.peek(); err != nil {
+		return nil, err
+	} else if next != '{' {
+		return nil, s.errorf("expected the file to hold an object")
+	}
+	obj, err := objectAt(data, s.pos)
+	if err != nil {
+		return nil, err
+	}
+	s.pos = obj.close + 1
+	if next, err := s.peek(); err != nil {
+		return nil, err
+	} else if next != 0 {
+		return nil, s.errorf("unexpected content after the closing brace")
+	}
+	return obj, nil
+}
+
+// objectAt reads the object whose '{' is at offset.
+func objectAt(data []byte, offset int) (*jsoncObject, error) {
+	s := &jsoncScanner{data: data, pos: offset}
+	obj := &jsoncObject{open: offset, members: make(map[string]jsoncMember)}
+	first, err := s.object(obj.members)
+	if err != nil {
+		return nil, err
+	}
+	obj.first = first
+	obj.close = s.pos - 1
+	return obj, nil
+}
+
+// elementsAt returns the span of each element of the array whose '[' is at
+// offset.
+func elementsAt(data []byte, offset int) ([][2]int, error) {
+	s := &jsoncScanner{data: data, pos: offset}
+	elems := [][2]int{}
+	if err := s.array(&elems); err != nil {
+		return nil, err
+	}
+	return elems, nil
+}
+
+// lookupJSONC finds the member at path, a list of keys from the root object.
+// It returns the object holding the member, which is nil when an object on
+// the way is missing, and whether the member itself exists.
+func lookupJSONC(data []byte, path []string) (*jsoncObject, jsoncMember, bool, error) {
+	obj, err := parseJSONCObject(data)
+	if err != nil {
+		return nil, jsoncMember{}, false, err
+	}
+	for i, key := range path {
+		m, ok := obj.members[key]
+		if !ok {
+			if i < len(path)-1 {
+				return nil, jsoncMember{}, false, nil
+			}
+			return obj, jsoncMember{}, false, nil
+		}
+		if i == len(path)-1 {
+			return obj, m, true, nil
+		}
+		if data[m.valueStart] != '{' {
+			return nil, jsoncMember{}, false, nil
+		}
+		if obj, err = objectAt(data, m.valueStart); err != nil {
+			return nil, jsoncMember{}, false, err
+		}
+	}
+	return obj, jsoncMember{}, false, nil
+}
+
+// decodeJSONC unmarshals a JSONC value into v.
+func decodeJSONC(raw []byte, v any) error {
+	s := &jsoncScanner{data: raw}
+	var out bytes.Buffer
+	for s.pos < len(raw) {
+		switch c := raw[s.pos]; c {
+		case '"':
+			start := s.pos
+			if _, err := s.str(); err != nil {
+				return err
+			}
+			out.Write(raw[start:s.pos])
+		case '/':
+			start := s.pos
+			if err := s.skip(); err != nil {
+				return err
+			}
+			if s.pos == start {
+				out.WriteByte(c)
+				s.pos++
+			}
+		case ',':
+			s.pos++
+			next, err := s.peek()
+			if err != nil {
+				return err
+			}
+			if next != ']' && next != '}' {
+				out.WriteByte(',')
+			}
+		default:
+			out.WriteByte(c)
+			s.pos++
+		}
+	}
+	return json.Unmarshal(out.Bytes(), v)
+}
+
+// lineIndent returns the whitespace that starts the line holding offset.
+func lineIndent(data []byte, offset int) string {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := start
+	for end < offset && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// setJSONCMember replaces the value of a top-level member with the output of
+// render, or adds the member at the top of the object when it's missing.
+// render is given the indent of the member's line and of one nesting level,
+// and the file's line ending.
+func setJSONCMember(data []byte, key string, render func(indent, unit, nl string) string) ([]byte, error) {
+	return setJSONCPath(data, []string{key}, render)
+}
+
+// setJSONCPath is setJSONCMember for a member of a nested object, given by
+// the keys leading to it. Objects missing on the way are added.
+func setJSONCPath(data []byte, path []string, render func(indent, unit, nl string) string) ([]byte, error) {
+	obj, err := parseJSONCObject(data)
+	if err != nil {
+		return nil, err
+	}
+	nl := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		nl = "\r\n"
+	}
+
+	base, unit := "", "  "
+	for len(path) > 1 {
+		m, ok := obj.members[path[0]]
+		if !ok || data[m.valueStart] != '{' {
+			break
+		}
+		base, unit = memberIndent(data, obj, m.keyStart, base, unit)
+		if obj, err = objectAt(data, m.valueStart); err != nil {
+			return nil, err
+		}
+		path = path[1:]
+	}
+	for i := len(path) - 1; i > 0; i-- {
+		key, inner := path[i], render
+		render = func(indent, unit, nl string) string {
+			return "{" + nl + indent + unit + jsonString(key) + ": " + inner(indent+unit, unit, nl) + nl + indent + "}"
+		}
+	}
+	key := path[0]
+
+	var out bytes.Buffer
+	if m, ok := obj.members[key]; ok {
+		indent, unit := memberIndent(data, obj, m.keyStart, base, unit)
+		out.Write(data[:m.valueStart])
+		out.WriteString(render(indent, unit, nl))
+		out.Write(data[m.valueEnd:])
+		return out.Bytes(), nil
+	}
+
+	quoted := jsonString(key)
+	if obj.first < 0 {
+		indent := base + unit
+		member := indent + quoted + ": " + render(indent, unit, nl)
+		inside := data[obj.open+1 : obj.close]
+		out.Write(data[:obj.open+1])
+		if len(bytes.TrimSpace(inside)) == 0 {
+			out.WriteString(nl + member + nl + base)
+		} else {
+			out.WriteString(nl + member)
+			out.Write(inside)
+		}
+		out.Write(data[obj.close:])
+		return out.Bytes(), nil
+	}
+
+	indent, unit := memberIndent(data, obj, obj.first, base, unit)
+	insertAt := obj.first - len(lineIndent(data, obj.first))
+	if bytes.LastIndexByte(data[:obj.first], '\n') < obj.open {
+		insertAt = obj.first
+		out.Write(data[:insertAt])
+		out.WriteString(quoted + ": " + render(indent, unit, nl) + ", ")
+	} else {
+		out.Write(data[:insertAt])
+		out.WriteString(indent + quoted + ": " + render(indent, unit, nl) + "," + nl)
+	}
+	out.Write(data[insertAt:])
+	return out.Bytes(), nil
+}
+
+// memberIndent returns the indent of the member of obj at offset, and of one
+// nesting level. base and unit are the indent of obj's own member and the
+// nesting unit so far.
+func memberIndent(data []byte, obj *jsoncObject, offset int, base, unit string) (string, string) {
+	if bytes.LastIndexByte(data[:offset], '\n') < obj.open {
+		return base, unit // on the same line as the opening brace
+	}
+	if indent := lineIndent(data, offset); indent != "" {
+		if own, ok := strings.CutPrefix(indent, base); ok && own != "" {
+			return indent, own
+		}
+		return indent, unit
+	}
+	return base + unit, unit
+}
+
+// deleteJSONCPath removes the member at path, along with its line when it
+// has one to itself. A missing member is not an error.
+func deleteJSONCPath(data []byte, path []string) ([]byte, error) {
+	_, m, ok, err := lookupJSONC(data, path)
+	if err != nil || !ok {
+		return data, err
+	}
+	start, end := m.keyStart, m.valueEnd
+	lineStart := bytes.LastIndexByte(data[:start], '\n') + 1
+	ownLine := len(bytes.TrimSpace(data[lineStart:start])) == 0
+	if ownLine {
+		start = lineStart
+	}
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	if end < len(data) && data[end] == ',' {
+		end++
+	}
+	rest := end
+	for rest < len(data) && (data[rest] == ' ' || data[rest] == '\t' || data[rest] == '\r') {
+		rest++
+	}
+	if ownLine && bytes.HasPrefix(data[rest:], []byte("//")) {
+		// The member's trailing comment goes with it.
+		if i := bytes.IndexByte(data[rest:], '\n'); i >= 0 {
+			rest += i
+		} else {
+			rest = len(data)
+		}
+	}
+	if ownLine && rest < len(data) && data[rest] == '\n' {
+		end = rest + 1
+	}
+	var out bytes.Buffer
+	out.Write(data[:start])
+	out.Write(data[end:])
+	return out.Bytes(), nil
+}
+
+// jsonString encodes s as a JSON string without escaping HTML characters.
+func jsonString(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,67 @@
+package ide
+
+import "testing"
+
+func TestSetJSONCPath(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		path           []string
+	}{
+		{
+			name: "nested member",
+			in:   "{\n  \"settings\": {\n    \"a\": 1 // keep\n  }\n}\n",
+			path: []string{"settings", "a"},
+			want: "{\n  \"settings\": {\n    \"a\": true // keep\n  }\n}\n",
+		},
+		{
+			name: "added to nested object",
+			in:   "{\n  \"settings\": {}\n}\n",
+			path: []string{"settings", "b"},
+			want: "{\n  \"settings\": {\n    \"b\": true\n  }\n}\n",
+		},
+		{
+			name: "missing objects added",
+			in:   "{\n  \"folders\": []\n}\n",
+			path: []string{"settings", "b"},
+			want: "{\n  \"settings\": {\n    \"b\": true\n  },\n  \"folders\": []\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := setJSONCPath([]byte(tt.in), tt.path, func(indent, unit, nl string) string { return "true" })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteJSONCPath(t *testing.T) {
+	in := "{\n  \"settings\": {\n    \"a\": 1, // gone\n    \"b\": 2\n  }\n}\n"
+	out, err := deleteJSONCPath([]byte(in), []string{"settings", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"settings\": {\n    \"b\": 2\n  }\n}\n"
+	if string(out) != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+
+	same, err := deleteJSONCPath([]byte(in), []string{"settings", "missing"})
+	if err != nil || string(same) != in {
+		t.Errorf("deleting a missing member changed the file: %q, %v", same, err)
+	}
+}
+
+func TestDecodeJSONC(t *testing.T) {
+	var got []string
+	if err := decodeJSONC([]byte("[\n  \"a\", // one\n  /* two */ \"b//c\",\n]"), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b//c" {
+		t.Errorf("got %q", got)
+	}
+}
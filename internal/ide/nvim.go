@@ -0,0 +1,74 @@
+package ide
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GenerateNeovim writes .nvim/ws.lua, a Lua module returning the boarded
+// capsules, for a project config such as .nvim.lua to load. No-op if the
+// .nvim/ directory doesn't exist.
+func GenerateNeovim(root string, boarded map[string][]string, displayNames map[string]string) error {
+	dir := filepath.Join(root, ".nvim")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	repos := make([]string, 0, len(boarded))
+	for repo := range boarded {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var b strings.Builder
+	b.WriteString("-- Generated by ws from the boarded capsules. Changes will be overwritten.\n")
+	b.WriteString("-- Paths are relative to the workspace root.\n")
+	b.WriteString("return {\n")
+	b.WriteString("  capsules = {\n")
+	for _, repo := range repos {
+		displayName := repo
+		if dn, ok := displayNames[repo]; ok {
+			displayName = dn
+		}
+		for _, capsule := range boarded[repo] {
+			fmt.Fprintf(&b, "    { repo = %s, capsule = %s, name = %s, path = %s },\n",
+				luaString(repo), luaString(capsule),
+				luaString(displayName+" ("+capsule+")"),
+				luaString("repos/"+repo+"/"+capsule))
+		}
+	}
+	b.WriteString("  },\n")
+	b.WriteString("}\n")
+
+	path := filepath.Join(dir, "ws.lua")
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, []byte(b.String())) {
+		return nil
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// luaString quotes s as a Lua string literal, escaping control bytes as
+// three-digit decimals, the one form every Lua version reads.
+func luaString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\%03d`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
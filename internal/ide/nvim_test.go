@@ -0,0 +1,61 @@
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateNeovim(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".nvim"), 0755)
+
+	boarded := map[string][]string{
+		"web": {".ground"},
+		"api": {"fix-\"quotes\""},
+	}
+	if err := GenerateNeovim(root, boarded, map[string]string{"api": "API"}); err != nil {
+		t.Fatalf("GenerateNeovim() error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, ".nvim", "ws.lua"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `-- Generated by ws from the boarded capsules. Changes will be overwritten.
+-- Paths are relative to the workspace root.
+return {
+  capsules = {
+    { repo = "api", capsule = "fix-\"quotes\"", name = "API (fix-\"quotes\")", path = "repos/api/fix-\"quotes\"" },
+    { repo = "web", capsule = ".ground", name = "web (.ground)", path = "repos/web/.ground" },
+  },
+}
+`
+	if string(data) != want {
+		t.Errorf("ws.lua =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestGenerateNeovim_NoDir(t *testing.T) {
+	root := t.TempDir()
+	if err := GenerateNeovim(root, map[string][]string{"api": {"main"}}, nil); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".nvim")); !os.IsNotExist(err) {
+		t.Error(".nvim/ should not have been created")
+	}
+}
+
+func TestLuaString(t *testing.T) {
+	tests := map[string]string{
+		"plain":      `"plain"`,
+		`a\b`:        `"a\\b"`,
+		"tab\t1":     `"tab\0091"`,
+		"line\nnext": `"line\nnext"`,
+	}
+	for in, want := range tests {
+		if got := luaString(in); got != want {
+			t.Errorf("luaString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -1,16 +1,104 @@
 package ide
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Board is the state generators work from.
+type Board struct {
+	Boarded      map[string][]string
+	DisplayNames map[string]string
+	Org          string
+}
+
+// Generator keeps one editor's workspace files in step with the board. A
+// generator only touches files whose editor has been set up in the
+// workspace, so each editor is opt-in.
+type Generator struct {
+	Name string
+	// Path is the file or directory, relative to the workspace root, whose
+	// presence turns the generator on.
+	Path string
+	// Blank is what a new file at Path starts as. Generators whose Path is a
+	// directory leave it empty.
+	Blank    string
+	Generate func(root string, b Board) error
+}
+
+// Generators are run by Regenerate, in order.
+var Generators = []Generator{
+	{Name: "vscode", Path: "workspace.code-workspace", Blank: "{\n  \"folders\": [],\n  \"settings\": {}\n}\n",
+		Generate: func(root string, b Board) error {
+			return GenerateVSCode(root, b.Boarded, b.DisplayNames)
+		}},
+	{Name: "idea", Path: ".idea", Generate: func(root string, b Board) error {
+		return GenerateIDEA(root, b.Boarded, b.Org)
+	}},
+	{Name: "zed", Path: filepath.Join(".zed", "settings.json"), Blank: "{}\n",
+		Generate: func(root string, b Board) error {
+			return GenerateZed(root, b.Boarded)
+		}},
+	{Name: "nvim", Path: ".nvim", Generate: func(root string, b Board) error {
+		return GenerateNeovim(root, b.Boarded, b.DisplayNames)
+	}},
+	{Name: "helix", Path: ".helix", Generate: func(root string, b Board) error {
+		return GenerateHelix(root, b.Boarded)
+	}},
+}
+
+// GeneratorNames lists the names of Generators.
+func GeneratorNames() []string {
+	names := make([]string, len(Generators))
+	for i, g := range Generators {
+		names[i] = g.Name
+	}
+	return names
+}
+
+// LookupGenerator returns the generator called name.
+func LookupGenerator(name string) (Generator, bool) {
+	for _, g := range Generators {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return Generator{}, false
+}
 
 // Regenerate updates all detected IDE workspace files from board state.
 // Only mutates files that already exist.
 func Regenerate(root string, boarded map[string][]string, displayNames map[string]string, org string) error {
+	b := Board{Boarded: boarded, DisplayNames: displayNames, Org: org}
 	var errs []error
-	if err := GenerateVSCode(root, boarded, displayNames); err != nil {
-		errs = append(errs, err)
-	}
-	if err := GenerateIDEA(root, boarded, org); err != nil {
-		errs = append(errs, err)
+	for _, g := range Generators {
+		if err := g.Generate(root, b); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", g.Name, err))
+		}
 	}
 	return errors.Join(errs...)
 }
+
+// hiddenPaths returns the directories under repos/ that aren't boarded
+// capsules, such as .bare and capsules that are off the board, relative to
+// root and sorted. Editors that open the whole workspace use it to keep
+// them out of search and file pickers.
+func hiddenPaths(root string, boarded map[string][]string) []string {
+	repos, _ := os.ReadDir(filepath.Join(root, "repos"))
+	var paths []string
+	for _, repo := range repos {
+		if !repo.IsDir() {
+			continue
+		}
+		entries, _ := os.ReadDir(filepath.Join(root, "repos", repo.Name()))
+		for _, e := range entries {
+			if e.IsDir() && !slices.Contains(boarded[repo.Name()], e.Name()) {
+				paths = append(paths, "repos/"+repo.Name()+"/"+e.Name())
+			}
+		}
+	}
+	return paths
+}
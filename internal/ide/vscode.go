@@ -1,36 +1,25 @@
 package ide
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
-// GenerateVSCode updates the folders in an existing .code-workspace file.
-// No-op if the file doesn't exist.
+// GenerateVSCode updates the folders in an existing .code-workspace file, and
+// merges in the settings, extensions, tasks and launch configurations that
+// repos contribute through ws.repo.toml. No-op if the file doesn't exist.
 func GenerateVSCode(root string, boarded map[string][]string, displayNames map[string]string) error {
 	path := filepath.Join(root, "workspace.code-workspace")
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil
 	}
 
-	// Read existing file
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return err
-	}
-
-	// Parse into generic map to preserve unknown keys.
-	// JSONC (comments, trailing commas) is not supported — fail with a clear message.
-	var workspace map[string]any
-	if err := json.Unmarshal(data, &workspace); err != nil {
-		return fmt.Errorf("failed to parse %s: %w\nOnly standard JSON is supported (no comments or trailing commas)", path, err)
-	}
-
 	// Build folders from boarded state
-	var folders []map[string]string
+	var folders []vscodeFolder
 	repos := make([]string, 0, len(boarded))
 	for repo := range boarded {
 		repos = append(repos, repo)
@@ -38,23 +27,97 @@ func GenerateVSCode(root string, boarded map[string][]string, displayNames map[s
 	sort.Strings(repos)
 
 	for _, repo := range repos {
-		displayName := repo
-		if dn, ok := displayNames[repo]; ok {
-			displayName = dn
-		}
 		for _, capsule := range boarded[repo] {
-			folders = append(folders, map[string]string{
-				"name": displayName + " (" + capsule + ")",
-				"path": filepath.Join("repos", repo, capsule),
-			})
+			folders = append(folders, newVSCodeFolder(repo, capsule, displayNames))
 		}
 	}
+	return writeVSCodeWorkspace(root, path, folders)
+}
 
-	workspace["folders"] = folders
+// GenerateCapsuleVSCode writes a workspace file in dir holding only one
+// capsule, for opening it in a window of its own, and returns its path. A new
+// file starts as a copy of the shared workspace file, if there is one, so its
+// settings carry over.
+func GenerateCapsuleVSCode(root, dir, repo, capsule string, displayNames map[string]string) (string, error) {
+	path := filepath.Join(dir, repo+"-"+capsule+".code-workspace")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		g, _ := LookupGenerator("vscode")
+		seed, err := os.ReadFile(filepath.Join(root, g.Path))
+		if err != nil {
+			seed = []byte(g.Blank)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, seed, 0644); err != nil {
+			return "", err
+		}
+	}
+	folders := []vscodeFolder{newVSCodeFolder(repo, capsule, displayNames)}
+	return path, writeVSCodeWorkspace(root, path, folders)
+}
 
-	out, err := json.MarshalIndent(workspace, "", "  ")
+func newVSCodeFolder(repo, capsule string, displayNames map[string]string) vscodeFolder {
+	displayName := repo
+	if dn, ok := displayNames[repo]; ok {
+		displayName = dn
+	}
+	return vscodeFolder{
+		name:    displayName + " (" + capsule + ")",
+		path:    filepath.Join("repos", repo, capsule),
+		repo:    repo,
+		capsule: capsule,
+	}
+}
+
+// writeVSCodeWorkspace sets the folders of the workspace file at path, which
+// belongs to the workspace at root, and applies what repos contribute.
+func writeVSCodeWorkspace(root, path string, folders []vscodeFolder) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	toRoot, err := filepath.Rel(filepath.Dir(path), root)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, append(out, '\n'), 0644)
+
+	// The file is JSONC, so only the folders array is rewritten. Comments,
+	// trailing commas and formatting elsewhere are left as they are.
+	out, err := setJSONCMember(data, "folders", func(indent, unit, nl string) string {
+		if len(folders) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[" + nl)
+		for i, f := range folders {
+			b.WriteString(indent + unit + "{" + nl)
+			b.WriteString(indent + unit + unit + `"name": ` + jsonString(f.name) + "," + nl)
+			b.WriteString(indent + unit + unit + `"path": ` + jsonString(filepath.ToSlash(filepath.Join(toRoot, f.path))) + nl)
+			b.WriteString(indent + unit + "}")
+			if i < len(folders)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString(nl)
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	contribs, contribErr := vscodeContributions(root)
+	if len(contribs) > 0 {
+		previous := vscodeFolderNames(data)
+		if out, err = mergeVSCodeContributions(out, root, folders, previous, contribs); err != nil {
+			return fmt.Errorf("failed to update %s: %w", path, err)
+		}
+	}
+	if !bytes.Equal(out, data) {
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return err
+		}
+	}
+	return contribErr
 }
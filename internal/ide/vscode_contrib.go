@@ -0,0 +1,402 @@
+package ide
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/brudil/workspace/internal/config"
+	"github.com/brudil/workspace/internal/workspace"
+)
+
+// A repo's ws.repo.toml can contribute to the VS Code workspace file. ws owns
+// what repos contribute and leaves the rest of the file alone:
+//
+//   - settings: keys that any repo sets are kept to the values of the boarded
+//     repos, and removed when none of those set them
+//   - extensions: recommendations from boarded repos are added to the user's
+//   - tasks and launch configurations: one copy per boarded capsule, named
+//     "<folder>: <name>" so they can be told apart from the user's own
+
+// vscodeFolder is a boarded capsule as it appears in the workspace file.
+type vscodeFolder struct {
+	name, path    string
+	repo, capsule string
+}
+
+// vscodeContributions reads the [vscode] section of every repo's
+// ws.repo.toml, boarded or not, keyed by repo. Repos whose file can't be read
+// are left out and reported in the error.
+func vscodeContributions(root string) (map[string]config.VSCodeRepoConfig, error) {
+	repos, _ := os.ReadDir(filepath.Join(root, "repos"))
+	contribs := make(map[string]config.VSCodeRepoConfig)
+	var errs []error
+	for _, repo := range repos {
+		if !repo.IsDir() {
+			continue
+		}
+		cfg, err := config.ParseRepoConfig(filepath.Join(root, "repos", repo.Name(), workspace.GroundDir, config.RepoFileName))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if cfg != nil && !cfg.VSCode.IsZero() {
+			contribs[repo.Name()] = cfg.VSCode
+		}
+	}
+	return contribs, errors.Join(errs...)
+}
+
+// vscodeFolderNames returns the names of the folders in a workspace file.
+func vscodeFolderNames(data []byte) []string {
+	_, m, ok, err := lookupJSONC(data, []string{"folders"})
+	if err != nil || !ok {
+		return nil
+	}
+	var folders []struct {
+		Name string `json:"name"`
+	}
+	decodeJSONC(data[m.valueStart:m.valueEnd], &folders)
+	names := make([]string, 0, len(folders))
+	for _, f := range folders {
+		if f.Name != "" {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// mergeVSCodeContributions applies what repos contribute to data, whose
+// folders are now folders and were previous.
+func mergeVSCodeContributions(data []byte, root string, folders []vscodeFolder, previous []string, contribs map[string]config.VSCodeRepoConfig) ([]byte, error) {
+	var repos []string
+	for _, f := range folders {
+		if !slices.Contains(repos, f.repo) {
+			repos = append(repos, f.repo)
+		}
+	}
+	owned := previous
+	for _, f := range folders {
+		owned = append(owned, f.name)
+	}
+
+	var err error
+	if data, err = mergeVSCodeSettings(data, repos, contribs); err != nil {
+		return nil, err
+	}
+	if data, err = mergeVSCodeExtensions(data, repos, contribs); err != nil {
+		return nil, err
+	}
+	if data, err = mergeVSCodeList(data, vscodeTasks, root, folders, owned, contribs); err != nil {
+		return nil, err
+	}
+	return mergeVSCodeList(data, vscodeLaunch, root, folders, owned, contribs)
+}
+
+func mergeVSCodeSettings(data []byte, repos []string, contribs map[string]config.VSCodeRepoConfig) ([]byte, error) {
+	managed := make(map[string]bool)
+	for _, c := range contribs {
+		for key := range c.Settings {
+			managed[key] = true
+		}
+	}
+	want := make(map[string]any)
+	for _, repo := range repos {
+		maps.Copy(want, contribs[repo].Settings)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(managed)) {
+		var err error
+		if v, ok := want[key]; ok {
+			data, err = setJSONCPath(data, []string{"settings", key}, func(indent, unit, nl string) string {
+				return renderJSON(v, indent, unit, nl)
+			})
+		} else {
+			data, err = deleteJSONCPath(data, []string{"settings", key})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func mergeVSCodeExtensions(data []byte, repos []string, contribs map[string]config.VSCodeRepoConfig) ([]byte, error) {
+	managed := make(map[string]bool)
+	for _, c := range contribs {
+		for _, id := range c.Extensions {
+			managed[id] = true
+		}
+	}
+	if len(managed) == 0 {
+		return data, nil
+	}
+
+	path := []string{"extensions", "recommendations"}
+	var current []string
+	_, m, exists, err := lookupJSONC(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if err := decodeJSONC(data[m.valueStart:m.valueEnd], &current); err != nil {
+			return nil, fmt.Errorf("extensions.recommendations: %w", err)
+		}
+	}
+
+	var want []string
+	for _, id := range current {
+		if !managed[id] && !slices.Contains(want, id) {
+			want = append(want, id)
+		}
+	}
+	for _, repo := range repos {
+		for _, id := range contribs[repo].Extensions {
+			if !slices.Contains(want, id) {
+				want = append(want, id)
+			}
+		}
+	}
+	if !exists && len(want) == 0 {
+		return data, nil
+	}
+	return setJSONCPath(data, path, func(indent, unit, nl string) string {
+		items := make([]string, len(want))
+		for i, id := range want {
+			items[i] = jsonString(id)
+		}
+		return renderList(items, indent, unit, nl)
+	})
+}
+
+// vscodeList describes a list in the workspace file that repos add entries
+// to, once per boarded capsule.
+type vscodeList struct {
+	member, items string // e.g. launch.configurations
+	version       string // written when ws adds the member
+	nameKey       string
+	runsInFolder  bool // entries default to running in their capsule
+	entries       func(config.VSCodeRepoConfig) []map[string]any
+}
+
+var (
+	vscodeTasks = vscodeList{
+		member: "tasks", items: "tasks", version: "2.0.0", nameKey: "label", runsInFolder: true,
+		entries: func(c config.VSCodeRepoConfig) []map[string]any { return c.Tasks },
+	}
+	vscodeLaunch = vscodeList{
+		member: "launch", items: "configurations", version: "0.2.0", nameKey: "name",
+		entries: func(c config.VSCodeRepoConfig) []map[string]any { return c.Launch },
+	}
+)
+
+// vscodeTaskRefs are the keys of tasks and launch configurations that name a
+// task, and so are prefixed along with the task's label.
+var vscodeTaskRefs = []string{"dependsOn", "preLaunchTask", "postDebugTask"}
+
+// mergeVSCodeList rewrites a list with the entries of the boarded capsules,
+// keeping the user's entries. Entries whose name starts with one of the
+// owned folder names are taken to be ws's.
+func mergeVSCodeList(data []byte, list vscodeList, root string, folders []vscodeFolder, owned []string, contribs map[string]config.VSCodeRepoConfig) ([]byte, error) {
+	if !slices.ContainsFunc(slices.Collect(maps.Values(contribs)), func(c config.VSCodeRepoConfig) bool { return len(list.entries(c)) > 0 }) {
+		return data, nil
+	}
+
+	var generated []map[string]any
+	for _, f := range folders {
+		entries := list.entries(contribs[f.repo])
+		if len(entries) == 0 {
+			continue
+		}
+		labels := make(map[string]bool)
+		for _, t := range contribs[f.repo].Tasks {
+			if label, ok := t["label"].(string); ok {
+				labels[label] = true
+			}
+		}
+		prefix := func(name string) string { return f.name + ": " + name }
+		vars := vscodeVars(root, f)
+		for _, entry := range entries {
+			e := expandVars(entry, vars).(map[string]any)
+			e[list.nameKey] = prefix(fmt.Sprint(e[list.nameKey]))
+			for _, key := range vscodeTaskRefs {
+				switch ref := e[key].(type) {
+				case string:
+					if labels[ref] {
+						e[key] = prefix(ref)
+					}
+				case []any:
+					for i, r := range ref {
+						if s, ok := r.(string); ok && labels[s] {
+							ref[i] = prefix(s)
+						}
+					}
+				}
+			}
+			if list.runsInFolder {
+				opts, _ := e["options"].(map[string]any)
+				if opts == nil {
+					opts = make(map[string]any)
+				}
+				if _, ok := opts["cwd"]; !ok {
+					opts["cwd"] = "${workspaceFolder:" + f.name + "}"
+				}
+				e["options"] = opts
+			}
+			generated = append(generated, e)
+		}
+	}
+
+	_, member, hasMember, err := lookupJSONC(data, []string{list.member})
+	if err != nil {
+		return nil, err
+	}
+	if !hasMember && len(generated) == 0 {
+		return data, nil
+	}
+	var kept [][]byte
+	if hasMember {
+		_, m, ok, err := lookupJSONC(data, []string{list.member, list.items})
+		if err != nil {
+			return nil, err
+		}
+		if ok && data[m.valueStart] == '[' {
+			elems, err := elementsAt(data, m.valueStart)
+			if err != nil {
+				return nil, err
+			}
+			for _, span := range elems {
+				raw := data[span[0]:span[1]]
+				var entry map[string]any
+				decodeJSONC(raw, &entry)
+				name, _ := entry[list.nameKey].(string)
+				if !slices.ContainsFunc(owned, func(folder string) bool { return strings.HasPrefix(name, folder+": ") }) {
+					kept = append(kept, raw)
+				}
+			}
+		}
+	}
+
+	renderItems := func(indent, unit, nl string) string {
+		var items []string
+		for _, raw := range kept {
+			items = append(items, string(raw))
+		}
+		for _, e := range generated {
+			items = append(items, renderEntry(e, indent+unit, unit, nl))
+		}
+		return renderList(items, indent, unit, nl)
+	}
+	if hasMember && data[member.valueStart] == '{' {
+		return setJSONCPath(data, []string{list.member, list.items}, renderItems)
+	}
+	return setJSONCPath(data, []string{list.member}, func(indent, unit, nl string) string {
+		return "{" + nl +
+			indent + unit + `"version": ` + jsonString(list.version) + "," + nl +
+			indent + unit + jsonString(list.items) + ": " + renderItems(indent+unit, unit, nl) + nl +
+			indent + "}"
+	})
+}
+
+// vscodeVars returns the variables ws expands in a capsule's tasks and launch
+// configurations. ${workspaceFolder} is made to point at the capsule, since
+// it's ambiguous in a workspace with several folders.
+func vscodeVars(root string, f vscodeFolder) *strings.Replacer {
+	return strings.NewReplacer(
+		"${workspaceFolder}", "${workspaceFolder:"+f.name+"}",
+		"${ws:repo}", f.repo,
+		"${ws:capsule}", f.capsule,
+		"${ws:branch}", workspace.GitCurrentBranch(filepath.Join(root, f.path)),
+	)
+}
+
+// expandVars returns a copy of v with the variables in its strings replaced.
+func expandVars(v any, r *strings.Replacer) any {
+	switch v := v.(type) {
+	case string:
+		return r.Replace(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = expandVars(val, r)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = expandVars(val, r)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = expandVars(val, r)
+		}
+		return out
+	}
+	return v
+}
+
+// vscodeKeyOrder puts the keys people scan for first in generated entries.
+var vscodeKeyOrder = []string{"name", "label", "type", "request"}
+
+// renderEntry renders a task or launch configuration as a JSON object.
+func renderEntry(e map[string]any, indent, unit, nl string) string {
+	rank := func(key string) int {
+		if i := slices.Index(vscodeKeyOrder, key); i >= 0 {
+			return i
+		}
+		return len(vscodeKeyOrder)
+	}
+	keys := slices.Sorted(maps.Keys(e))
+	slices.SortStableFunc(keys, func(a, b string) int { return cmp.Compare(rank(a), rank(b)) })
+
+	var b strings.Builder
+	b.WriteString("{" + nl)
+	for i, k := range keys {
+		b.WriteString(indent + unit + jsonString(k) + ": " + renderJSON(e[k], indent+unit, unit, nl))
+		if i < len(keys)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString(nl)
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// renderList renders already-rendered items as a JSON array, one per line.
+func renderList(items []string, indent, unit, nl string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	var b strings.Builder
+	b.WriteString("[" + nl)
+	for i, item := range items {
+		b.WriteString(indent + unit + item)
+		if i < len(items)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString(nl)
+	}
+	b.WriteString(indent + "]")
+	return b.String()
+}
+
+// renderJSON renders v as indented JSON whose first line starts at indent.
+func renderJSON(v any, indent, unit, nl string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(indent, unit)
+	if err := enc.Encode(v); err != nil {
+		return "null"
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(b.String(), "\n"), "\n", nl)
+}
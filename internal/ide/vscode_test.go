@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/charmbracelet/x/exp/golden"
 )
 
 func TestGenerateVSCode_Basic(t *testing.T) {
@@ -114,25 +116,173 @@ func TestGenerateVSCode_RepoOrder(t *testing.T) {
 	}
 }
 
-func TestGenerateVSCode_JSONCReturnsError(t *testing.T) {
+func TestGenerateVSCode_Golden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "vscode", "*.code-workspace"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no workspace fixtures found: %v", err)
+	}
+
+	boarded := map[string][]string{
+		"api": {".ground", "retry-webhooks"},
+		"web": {"login"},
+	}
+	displayNames := map[string]string{"web": "Web & Docs"}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".code-workspace")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root := t.TempDir()
+			path := filepath.Join(root, "workspace.code-workspace")
+			os.WriteFile(path, data, 0644)
+
+			if err := GenerateVSCode(root, boarded, displayNames); err != nil {
+				t.Fatalf("GenerateVSCode() error: %v", err)
+			}
+			out, _ := os.ReadFile(path)
+			golden.RequireEqual(t, out)
+
+			// Running again with the same boards changes nothing.
+			if err := GenerateVSCode(root, boarded, displayNames); err != nil {
+				t.Fatalf("second GenerateVSCode() error: %v", err)
+			}
+			if again, _ := os.ReadFile(path); string(again) != string(out) {
+				t.Errorf("regenerating changed the file:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestGenerateVSCode_MalformedReturnsError(t *testing.T) {
+	for _, content := range []string{
+		`{"folders": [}`,
+		`{"folders": [] /* unterminated`,
+		`["not", "an", "object"]`,
+		`{"folders": []} extra`,
+	} {
+		root := t.TempDir()
+		path := filepath.Join(root, "workspace.code-workspace")
+		os.WriteFile(path, []byte(content), 0644)
+
+		err := GenerateVSCode(root, map[string][]string{"repo-a": {"main"}}, nil)
+		if err == nil {
+			t.Errorf("GenerateVSCode(%q) = nil, want a parse error", content)
+			continue
+		}
+		if !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("error %q should say where the file broke", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("malformed file was rewritten to %q", data)
+		}
+	}
+}
+
+func TestGenerateVSCode_RepoContributions(t *testing.T) {
 	root := t.TempDir()
-	jsonc := `{
+	apiGround := filepath.Join(root, "repos", "api", ".ground")
+	os.MkdirAll(apiGround, 0755)
+	os.MkdirAll(filepath.Join(root, "repos", "web", ".ground"), 0755)
+	os.WriteFile(filepath.Join(apiGround, "ws.repo.toml"), []byte(`[vscode]
+extensions = ["golang.go"]
+
+[vscode.settings]
+"go.buildTags" = "integration"
+
+[[vscode.tasks]]
+label = "build"
+type = "shell"
+command = "go build ./..."
+
+[[vscode.launch]]
+name = "Server"
+type = "go"
+request = "launch"
+program = "${workspaceFolder}/cmd/server"
+args = ["--capsule", "${ws:capsule}"]
+preLaunchTask = "build"
+`), 0644)
+
+	path := filepath.Join(root, "workspace.code-workspace")
+	os.WriteFile(path, []byte(`{
   "folders": [],
+  "settings": {
+    "editor.fontSize": 14, // mine
+  },
   "extensions": {
-    "recommendations": [
-      // this is a comment
-      "some.extension"
+    "recommendations": ["esbenp.prettier-vscode"]
+  },
+  "launch": {
+    "version": "0.2.0",
+    "configurations": [
+      // my own
+      {"name": "Attach", "type": "node", "request": "attach"}
     ]
   }
-}`
-	os.WriteFile(filepath.Join(root, "workspace.code-workspace"), []byte(jsonc), 0644)
+}
+`), 0644)
 
-	boarded := map[string][]string{"repo-a": {"main"}}
-	err := GenerateVSCode(root, boarded, nil)
-	if err == nil {
-		t.Fatal("expected error for JSONC file, got nil")
+	boarded := map[string][]string{"api": {"retry-webhooks"}, "web": {".ground"}}
+	if err := GenerateVSCode(root, boarded, map[string]string{"api": "API"}); err != nil {
+		t.Fatalf("GenerateVSCode() error: %v", err)
+	}
+	boardedOut, _ := os.ReadFile(path)
+	golden.RequireEqual(t, boardedOut)
+
+	if err := GenerateVSCode(root, boarded, map[string]string{"api": "API"}); err != nil {
+		t.Fatalf("GenerateVSCode() error: %v", err)
+	}
+	if again, _ := os.ReadFile(path); string(again) != string(boardedOut) {
+		t.Errorf("second run changed the file:\n%s", again)
+	}
+
+	// Unboarding api takes its contributions out and leaves the user's.
+	delete(boarded, "api")
+	if err := GenerateVSCode(root, boarded, nil); err != nil {
+		t.Fatalf("GenerateVSCode() error: %v", err)
+	}
+	out, _ := os.ReadFile(path)
+	got := string(out)
+	for _, gone := range []string{"go.buildTags", "golang.go", "Server", "build"} {
+		if strings.Contains(got, gone) {
+			t.Errorf("%s should have been removed:\n%s", gone, got)
+		}
+	}
+	for _, kept := range []string{`"editor.fontSize": 14, // mine`, "esbenp.prettier-vscode", `{"name": "Attach", "type": "node", "request": "attach"}`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("%s should have been kept:\n%s", kept, got)
+		}
+	}
+}
+
+func TestGenerateCapsuleVSCode(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "workspace.code-workspace"), []byte(`{
+  // shared
+  "folders": [{"name": "api (main)", "path": "repos/api/main"}],
+  "settings": {"editor.tabSize": 2}
+}
+`), 0644)
+	dir := filepath.Join(root, ".windows", "api", "feature-x")
+
+	path, err := GenerateCapsuleVSCode(root, dir, "api", "feature-x", map[string]string{"api": "API"})
+	if err != nil {
+		t.Fatalf("GenerateCapsuleVSCode() error: %v", err)
+	}
+	if path != filepath.Join(dir, "api-feature-x.code-workspace") {
+		t.Errorf("path = %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	for _, want := range []string{"// shared", `"editor.tabSize": 2`, `"name": "API (feature-x)"`, `"path": "../../../repos/api/feature-x"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("workspace file missing %s:\n%s", want, got)
+		}
 	}
-	if !strings.Contains(err.Error(), "Only standard JSON is supported") {
-		t.Errorf("error message = %q, want it to mention standard JSON", err.Error())
+	if strings.Contains(got, "repos/api/main") {
+		t.Errorf("workspace file should only hold the capsule:\n%s", got)
 	}
 }
@@ -0,0 +1,52 @@
+package ide
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// zedDefaultExclusions are Zed's own file_scan_exclusions, which a project
+// setting replaces rather than extends.
+var zedDefaultExclusions = []string{
+	"**/.git", "**/.svn", "**/.hg", "**/.jj", "**/CVS",
+	"**/.DS_Store", "**/Thumbs.db", "**/.classpath", "**/.settings",
+}
+
+// GenerateZed updates file_scan_exclusions in an existing .zed/settings.json
+// so that only boarded capsules show up when Zed opens the workspace root.
+// No-op if the file doesn't exist.
+func GenerateZed(root string, boarded map[string][]string) error {
+	path := filepath.Join(root, ".zed", "settings.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	exclusions := append(zedDefaultExclusions[:len(zedDefaultExclusions):len(zedDefaultExclusions)], hiddenPaths(root, boarded)...)
+	out, err := setJSONCMember(data, "file_scan_exclusions", func(indent, unit, nl string) string {
+		var b strings.Builder
+		b.WriteString("[" + nl)
+		for i, e := range exclusions {
+			b.WriteString(indent + unit + jsonString(e))
+			if i < len(exclusions)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString(nl)
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if bytes.Equal(out, data) {
+		return nil
+	}
+	return os.WriteFile(path, out, 0644)
+}
@@ -0,0 +1,54 @@
+package ide
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// makeCapsules creates repos/<repo>/<dir> for each dir, with a .bare.
+func makeCapsules(t *testing.T, root, repo string, dirs ...string) {
+	t.Helper()
+	for _, d := range append([]string{".bare"}, dirs...) {
+		if err := os.MkdirAll(filepath.Join(root, "repos", repo, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerateZed_HidesUnboarded(t *testing.T) {
+	root := t.TempDir()
+	makeCapsules(t, root, "api", ".ground", "feature", "old")
+	os.MkdirAll(filepath.Join(root, ".zed"), 0755)
+	settings := "// project settings\n{\n  \"tab_size\": 2, // keep\n}\n"
+	os.WriteFile(filepath.Join(root, ".zed", "settings.json"), []byte(settings), 0644)
+
+	boarded := map[string][]string{"api": {".ground", "feature"}}
+	if err := GenerateZed(root, boarded); err != nil {
+		t.Fatalf("GenerateZed() error: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(root, ".zed", "settings.json"))
+	got := string(data)
+	for _, want := range []string{`"**/.git",`, `"repos/api/.bare",`, `"repos/api/old"`, `"tab_size": 2, // keep`, "// project settings"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("settings.json missing %s:\n%s", want, got)
+		}
+	}
+	for _, boardedPath := range []string{`"repos/api/.ground"`, `"repos/api/feature"`} {
+		if strings.Contains(got, boardedPath) {
+			t.Errorf("settings.json should not exclude %s:\n%s", boardedPath, got)
+		}
+	}
+}
+
+func TestGenerateZed_NoSettings(t *testing.T) {
+	root := t.TempDir()
+	if err := GenerateZed(root, map[string][]string{"api": {"main"}}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".zed")); !os.IsNotExist(err) {
+		t.Error(".zed/ should not have been created")
+	}
+}
@@ -0,0 +1,79 @@
+package mux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/brudil/workspace/internal/tmux"
+)
+
+// Layout is how a capsule's window is split into panes.
+type Layout = tmux.Layout
+
+// LayoutPane is one pane of a Layout.
+type LayoutPane = tmux.LayoutPane
+
+// Multiplexer is a terminal multiplexer that capsules can have named windows
+// in. Zellij and WezTerm call them tabs.
+type Multiplexer interface {
+	// Name is the multiplexer's command, e.g. "tmux".
+	Name() string
+	// Windows returns a map of window name → ID, or nil if the query fails.
+	Windows() map[string]string
+	// Focus switches to a window, making sure it has a shell in path to use
+	// where the multiplexer can tell.
+	Focus(id, path string) error
+	// NewWindow opens a window with the given name in path, split into
+	// layout's panes, and switches to it.
+	NewWindow(name, path string, layout Layout) error
+	// Run opens a window with the given name running argv in path.
+	Run(name, path string, argv ...string) error
+	// Split adds a pane running argv in path to a window.
+	Split(id, path string, argv ...string) error
+	KillWindow(id string) error
+	RenameWindow(id, name string) error
+}
+
+// run runs a multiplexer's command line and returns its trimmed output.
+// Tests replace it.
+var run = func(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", name, strings.Join(args[:min(2, len(args))], " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Detect returns the multiplexer ws is running inside, or nil. tmux wins when
+// it runs inside one of the others, since its windows are the nearest.
+func Detect() Multiplexer {
+	switch {
+	case tmux.InTmux():
+		return Tmux{}
+	case os.Getenv("ZELLIJ") != "":
+		return Zellij{}
+	case os.Getenv("WEZTERM_PANE") != "":
+		return WezTerm{}
+	}
+	return nil
+}
+
+// WindowName builds the canonical window name for a capsule.
+func WindowName(displayName, capsule string) string {
+	return tmux.WindowName(displayName, capsule)
+}
+
+// KillCapsuleWindow closes a capsule's window, if ws is running in a
+// multiplexer and the capsule has one. It reports whether a window closed.
+func KillCapsuleWindow(displayName, capsule string) bool {
+	m := Detect()
+	if m == nil {
+		return false
+	}
+	if id, ok := m.Windows()[WindowName(displayName, capsule)]; ok {
+		return m.KillWindow(id) == nil
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package mux
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// record replaces run for the test, answering each command with the output
+// reply returns for it.
+func record(t *testing.T, reply func(args []string) string) *[]string {
+	t.Helper()
+	var calls []string
+	orig := run
+	run = func(name string, args ...string) (string, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		if reply == nil {
+			return "", nil
+		}
+		return reply(args), nil
+	}
+	t.Cleanup(func() { run = orig })
+	return &calls
+}
+
+func assertCalls(t *testing.T, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		tmux, zellij, wezterm string
+		want                  string
+	}{
+		{want: ""},
+		{tmux: "/tmp/tmux-1000/default,1,0", want: "tmux"},
+		{zellij: "0", want: "zellij"},
+		{wezterm: "3", want: "wezterm"},
+		{tmux: "/tmp/tmux-1000/default,1,0", wezterm: "3", want: "tmux"},
+	}
+	for _, tt := range tests {
+		t.Setenv("TMUX", tt.tmux)
+		t.Setenv("ZELLIJ", tt.zellij)
+		t.Setenv("WEZTERM_PANE", tt.wezterm)
+		got := ""
+		if m := Detect(); m != nil {
+			got = m.Name()
+		}
+		if got != tt.want {
+			t.Errorf("Detect() with %+v = %q, want %q", tt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package mux
+
+import "github.com/brudil/workspace/internal/tmux"
+
+// Tmux drives tmux windows in the current session.
+type Tmux struct{}
+
+func (Tmux) Name() string { return "tmux" }
+
+func (Tmux) Windows() map[string]string { return tmux.ListWindows() }
+
+// Focus selects the window and an idle shell in it, splitting a new pane off
+// when every pane is busy.
+func (Tmux) Focus(id, path string) error {
+	if err := tmux.SelectWindow(id); err != nil {
+		return err
+	}
+	if pane, idle := tmux.FindIdlePane(tmux.ListPanes(id)); idle {
+		return tmux.SelectPane(pane)
+	}
+	return tmux.SplitWindow(id, path)
+}
+
+func (Tmux) NewWindow(name, path string, layout Layout) error {
+	return tmux.NewLayoutWindow(name, path, layout)
+}
+
+func (Tmux) Run(name, path string, argv ...string) error {
+	return tmux.NewCommandWindow(name, path, argv...)
+}
+
+func (Tmux) Split(id, path string, argv ...string) error {
+	if err := tmux.SelectWindow(id); err != nil {
+		return err
+	}
+	return tmux.SplitWindow(id, path, argv...)
+}
+
+func (Tmux) KillWindow(id string) error { return tmux.KillWindow(id) }
+
+func (Tmux) RenameWindow(id, name string) error { return tmux.RenameWindow(id, name) }
@@ -0,0 +1,158 @@
+package mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WezTerm drives tabs through `wezterm cli`. A window's ID is its tab ID.
+type WezTerm struct{}
+
+func (WezTerm) Name() string { return "wezterm" }
+
+func wezterm(args ...string) (string, error) {
+	return run("wezterm", append([]string{"cli"}, args...)...)
+}
+
+// weztermPane is an entry in `wezterm cli list --format json`.
+type weztermPane struct {
+	TabID    int    `json:"tab_id"`
+	PaneID   int    `json:"pane_id"`
+	TabTitle string `json:"tab_title"`
+}
+
+func weztermPanes() []weztermPane {
+	out, err := wezterm("list", "--format", "json")
+	if err != nil {
+		return nil
+	}
+	return parseWeztermPanes(out)
+}
+
+func parseWeztermPanes(output string) []weztermPane {
+	var panes []weztermPane
+	if json.Unmarshal([]byte(output), &panes) != nil {
+		return nil
+	}
+	return panes
+}
+
+func (WezTerm) Windows() map[string]string {
+	panes := weztermPanes()
+	if panes == nil {
+		return nil
+	}
+	windows := make(map[string]string)
+	for _, p := range panes {
+		if p.TabTitle != "" {
+			windows[p.TabTitle] = strconv.Itoa(p.TabID)
+		}
+	}
+	return windows
+}
+
+// Focus activates the tab. WezTerm doesn't say what its panes are running,
+// so no pane is added.
+func (WezTerm) Focus(id, path string) error {
+	_, err := wezterm("activate-tab", "--tab-id", id)
+	return err
+}
+
+// NewWindow spawns a tab and splits each later pane off the one before it.
+// layout.Arrange is a tmux layout name and has no WezTerm equivalent.
+func (WezTerm) NewWindow(name, path string, layout Layout) error {
+	first, err := wezterm("spawn", "--cwd", path)
+	if err != nil {
+		return err
+	}
+	if _, err := wezterm("set-tab-title", "--pane-id", first, name); err != nil {
+		return err
+	}
+
+	prev, focus := first, first
+	for i, p := range layout.Panes {
+		id := first
+		if i > 0 {
+			args := []string{"split-pane", "--pane-id", prev, "--cwd", path}
+			if p.Split == "below" {
+				args = append(args, "--bottom")
+			} else {
+				args = append(args, "--right")
+			}
+			if n, ok := strings.CutSuffix(p.Size, "%"); ok {
+				args = append(args, "--percent", n)
+			} else if p.Size != "" {
+				args = append(args, "--cells", p.Size)
+			}
+			if id, err = wezterm(args...); err != nil {
+				return err
+			}
+		}
+		if p.Command != "" {
+			if _, err := wezterm("send-text", "--pane-id", id, "--no-paste", p.Command+"\n"); err != nil {
+				return err
+			}
+		}
+		if p.Focus {
+			focus = id
+		}
+		prev = id
+	}
+	if focus == first {
+		return nil
+	}
+	_, err = wezterm("activate-pane", "--pane-id", focus)
+	return err
+}
+
+func (WezTerm) Run(name, path string, argv ...string) error {
+	pane, err := wezterm(append([]string{"spawn", "--cwd", path, "--"}, argv...)...)
+	if err != nil {
+		return err
+	}
+	_, err = wezterm("set-tab-title", "--pane-id", pane, name)
+	return err
+}
+
+func (WezTerm) Split(id, path string, argv ...string) error {
+	pane, ok := weztermTabPane(weztermPanes(), id)
+	if !ok {
+		return fmt.Errorf("no wezterm tab %s", id)
+	}
+	args := []string{"split-pane", "--pane-id", pane, "--cwd", path}
+	if len(argv) > 0 {
+		args = append(append(args, "--"), argv...)
+	}
+	_, err := wezterm(args...)
+	return err
+}
+
+// weztermTabPane returns the first pane in tab id.
+func weztermTabPane(panes []weztermPane, id string) (string, bool) {
+	for _, p := range panes {
+		if strconv.Itoa(p.TabID) == id {
+			return strconv.Itoa(p.PaneID), true
+		}
+	}
+	return "", false
+}
+
+// KillWindow closes the tab by killing each of its panes.
+func (WezTerm) KillWindow(id string) error {
+	for _, p := range weztermPanes() {
+		if strconv.Itoa(p.TabID) != id {
+			continue
+		}
+		if _, err := wezterm("kill-pane", "--pane-id", strconv.Itoa(p.PaneID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (WezTerm) RenameWindow(id, name string) error {
+	_, err := wezterm("set-tab-title", "--tab-id", id, name)
+	return err
+}
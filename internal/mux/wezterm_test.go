@@ -0,0 +1,66 @@
+package mux
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+const weztermList = `[
+  {"window_id": 0, "tab_id": 1, "pane_id": 4, "tab_title": "API:fix"},
+  {"window_id": 0, "tab_id": 1, "pane_id": 5, "tab_title": "API:fix"},
+  {"window_id": 0, "tab_id": 2, "pane_id": 6, "tab_title": ""}
+]`
+
+func TestWezTerm_Windows(t *testing.T) {
+	record(t, func(args []string) string { return weztermList })
+	got := WezTerm{}.Windows()
+	if len(got) != 1 || got["API:fix"] != "1" {
+		t.Errorf("Windows() = %v", got)
+	}
+}
+
+func TestWezTerm_NewWindow(t *testing.T) {
+	panes := 0
+	calls := record(t, func(args []string) string {
+		if slices.Contains([]string{"spawn", "split-pane"}, args[1]) {
+			panes++
+			return fmt.Sprint(panes)
+		}
+		return ""
+	})
+	layout := Layout{Panes: []LayoutPane{
+		{Command: "nvim ."},
+		{Size: "40%", Command: "go test ./...", Focus: true},
+		{Split: "below", Size: "10"},
+	}}
+	if err := (WezTerm{}).NewWindow("API:fix", "/p", layout); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, *calls, []string{
+		"wezterm cli spawn --cwd /p",
+		"wezterm cli set-tab-title --pane-id 1 API:fix",
+		"wezterm cli send-text --pane-id 1 --no-paste nvim .\n",
+		"wezterm cli split-pane --pane-id 1 --cwd /p --right --percent 40",
+		"wezterm cli send-text --pane-id 2 --no-paste go test ./...\n",
+		"wezterm cli split-pane --pane-id 2 --cwd /p --bottom --cells 10",
+		"wezterm cli activate-pane --pane-id 2",
+	})
+}
+
+func TestWezTerm_KillWindow(t *testing.T) {
+	calls := record(t, func(args []string) string {
+		if args[1] == "list" {
+			return weztermList
+		}
+		return ""
+	})
+	if err := (WezTerm{}).KillWindow("1"); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, *calls, []string{
+		"wezterm cli list --format json",
+		"wezterm cli kill-pane --pane-id 4",
+		"wezterm cli kill-pane --pane-id 5",
+	})
+}
@@ -0,0 +1,197 @@
+package mux
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Zellij drives tabs in the current Zellij session through `zellij action`.
+// Tabs are addressed by name, so a window's ID is its name.
+type Zellij struct{}
+
+func (Zellij) Name() string { return "zellij" }
+
+func zellij(args ...string) error {
+	_, err := run("zellij", append([]string{"action"}, args...)...)
+	return err
+}
+
+func (Zellij) Windows() map[string]string {
+	out, err := run("zellij", "action", "query-tab-names")
+	if err != nil {
+		return nil
+	}
+	windows := make(map[string]string)
+	for name := range strings.SplitSeq(out, "\n") {
+		if name != "" {
+			windows[name] = name
+		}
+	}
+	return windows
+}
+
+// Focus switches to the tab. Zellij doesn't say what its panes are running,
+// so no pane is added.
+func (Zellij) Focus(id, path string) error {
+	return zellij("go-to-tab-name", id)
+}
+
+func (Zellij) NewWindow(name, path string, layout Layout) error {
+	if len(layout.Panes) == 0 {
+		return zellij("new-tab", "--name", name, "--cwd", path)
+	}
+	return newZellijTab(name, path, zellijLayout(path, layout))
+}
+
+func (Zellij) Run(name, path string, argv ...string) error {
+	return newZellijTab(name, path, zellijCommandLayout(path, argv))
+}
+
+// newZellijTab opens a tab from a layout written to a temporary file.
+func newZellijTab(name, path, kdl string) error {
+	f, err := os.CreateTemp("", "ws-zellij-*.kdl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(kdl); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+	return zellij("new-tab", "--layout", f.Name(), "--name", name, "--cwd", path)
+}
+
+func (Zellij) Split(id, path string, argv ...string) error {
+	if err := zellij("go-to-tab-name", id); err != nil {
+		return err
+	}
+	args := []string{"new-pane", "--cwd", path}
+	if len(argv) > 0 {
+		args = append(append(args, "--close-on-exit", "--"), argv...)
+	}
+	return zellij(args...)
+}
+
+// KillWindow switches to the tab to close it, as Zellij only closes the
+// focused tab.
+func (Zellij) KillWindow(id string) error {
+	if err := zellij("go-to-tab-name", id); err != nil {
+		return err
+	}
+	return zellij("close-tab")
+}
+
+// RenameWindow switches to the tab to rename it, as Zellij only renames the
+// focused tab.
+func (Zellij) RenameWindow(id, name string) error {
+	if err := zellij("go-to-tab-name", id); err != nil {
+		return err
+	}
+	return zellij("rename-tab", name)
+}
+
+// zellijTabTemplate keeps the tab and status bars of Zellij's default layout
+// in tabs opened from a layout file.
+const zellijTabTemplate = `    default_tab_template {
+        pane size=1 borderless=true {
+            plugin location="zellij:tab-bar"
+        }
+        children
+        pane size=2 borderless=true {
+            plugin location="zellij:status-bar"
+        }
+    }
+`
+
+// zellijLayout renders layout as a KDL tab layout. Each pane after the first
+// is split off the one before it, so the panes nest: pane n and everything
+// after it share one node beside pane n-1. layout.Arrange is a tmux layout
+// name and has no Zellij equivalent.
+func zellijLayout(path string, layout Layout) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "layout {\n    cwd %s\n%s", kdlString(path), zellijTabTemplate)
+	writeZellijPanes(&b, layout.Panes, "", 1)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func writeZellijPanes(b *strings.Builder, panes []LayoutPane, size string, depth int) {
+	indent := strings.Repeat("    ", depth)
+	attrs := ""
+	if size != "" {
+		attrs += " size=" + kdlString(size)
+	}
+	if len(panes) == 1 {
+		writeZellijPane(b, panes[0], attrs, indent)
+		return
+	}
+	dir := "vertical"
+	if panes[1].Split == "below" {
+		dir = "horizontal"
+	}
+	fmt.Fprintf(b, "%spane%s split_direction=%q {\n", indent, attrs, dir)
+	writeZellijPane(b, panes[0], "", indent+"    ")
+	writeZellijPanes(b, panes[1:], panes[1].Size, depth+1)
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// writeZellijPane writes a leaf pane. Its command runs in a shell that stays
+// open once the command exits, as it would have been typed there.
+func writeZellijPane(b *strings.Builder, p LayoutPane, attrs, indent string) {
+	if p.Focus {
+		attrs += " focus=true"
+	}
+	if p.Command == "" {
+		fmt.Fprintf(b, "%spane%s\n", indent, attrs)
+		return
+	}
+	fmt.Fprintf(b, "%spane%s command=\"sh\" {\n", indent, attrs)
+	fmt.Fprintf(b, "%s    args \"-c\" %s\n", indent, kdlString(p.Command+`; exec "${SHELL:-sh}"`))
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+// zellijCommandLayout renders a tab layout with one pane running argv, which
+// closes when argv exits.
+func zellijCommandLayout(path string, argv []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "layout {\n    cwd %s\n%s", kdlString(path), zellijTabTemplate)
+	fmt.Fprintf(&b, "    pane command=%s close_on_exit=true {\n", kdlString(argv[0]))
+	if len(argv) > 1 {
+		b.WriteString("        args")
+		for _, a := range argv[1:] {
+			b.WriteString(" " + kdlString(a))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("    }\n}\n")
+	return b.String()
+}
+
+// kdlString quotes s as a KDL string.
+func kdlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package mux
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/exp/golden"
+)
+
+func TestZellijLayout(t *testing.T) {
+	out := zellijLayout("/ws/repos/api/fix", Layout{Panes: []LayoutPane{
+		{Command: `nvim "."`, Focus: true},
+		{Size: "40%", Command: "go test ./..."},
+		{Split: "below"},
+	}})
+	golden.RequireEqual(t, []byte(out))
+}
+
+func TestZellijCommandLayout(t *testing.T) {
+	out := zellijCommandLayout("/ws/repos/api/fix", []string{"sh", "-c", "make\tlint"})
+	golden.RequireEqual(t, []byte(out))
+}
+
+func TestZellij_Windows(t *testing.T) {
+	record(t, func(args []string) string { return "API:fix\nWeb:main\n" })
+	got := Zellij{}.Windows()
+	if len(got) != 2 || got["API:fix"] != "API:fix" || got["Web:main"] != "Web:main" {
+		t.Errorf("Windows() = %v", got)
+	}
+}
+
+func TestZellij_Split(t *testing.T) {
+	calls := record(t, nil)
+	if err := (Zellij{}).Split("API:fix", "/p", "vim", "/p"); err != nil {
+		t.Fatal(err)
+	}
+	assertCalls(t, *calls, []string{
+		"zellij action go-to-tab-name API:fix",
+		"zellij action new-pane --cwd /p --close-on-exit -- vim /p",
+	})
+}
@@ -22,9 +22,14 @@ func StubGitHubAuth(t *testing.T) {
 type StubClient struct {
 	PRsForRepoFn       func(org, repo string) ([]github.PR, error)
 	MergedPRsForRepoFn func(org, repo string) ([]github.PR, error)
+	PRsForReposFn      func(org string, repos []string) map[string]github.RepoPRs
 	PRFromNumberFn     func(org, repo string, number int) (*github.PR, error)
+	PRsFromNumbersFn   func(org, repo string, numbers []int) (map[int]github.PR, error)
+	IssueFromNumberFn  func(org, repo string, number int) (*github.Issue, error)
 	PRDetailFn         func(org, repo string, number int) (github.PRDetailResult, error)
 	WorkflowRunsFn     func(org, repo, branch string, limit int) ([]github.WorkflowRun, error)
+	CreatePRFn         func(org, repo string, opts github.CreatePROptions) (*github.PR, error)
+	EditPRBodyFn       func(org, repo string, number int, body string) error
 }
 
 func (s *StubClient) PRsForRepo(org, repo string) ([]github.PR, error) {
@@ -41,6 +46,24 @@ func (s *StubClient) MergedPRsForRepo(org, repo string) ([]github.PR, error) {
 	return nil, nil
 }
 
+// PRsForRepos falls back to PRsForRepoFn and MergedPRsForRepoFn per repo
+// when PRsForReposFn isn't set, so tests can stub either.
+func (s *StubClient) PRsForRepos(org string, repos []string) map[string]github.RepoPRs {
+	if s.PRsForReposFn != nil {
+		return s.PRsForReposFn(org, repos)
+	}
+	result := make(map[string]github.RepoPRs, len(repos))
+	for _, repo := range repos {
+		var r github.RepoPRs
+		r.Open, r.Err = s.PRsForRepo(org, repo)
+		if r.Err == nil {
+			r.Merged, r.Err = s.MergedPRsForRepo(org, repo)
+		}
+		result[repo] = r
+	}
+	return result
+}
+
 func (s *StubClient) PRFromNumber(org, repo string, number int) (*github.PR, error) {
 	if s.PRFromNumberFn != nil {
 		return s.PRFromNumberFn(org, repo, number)
@@ -48,6 +71,32 @@ func (s *StubClient) PRFromNumber(org, repo string, number int) (*github.PR, err
 	return nil, nil
 }
 
+// PRsFromNumbers falls back to PRFromNumber per number when
+// PRsFromNumbersFn isn't set.
+func (s *StubClient) PRsFromNumbers(org, repo string, numbers []int) (map[int]github.PR, error) {
+	if s.PRsFromNumbersFn != nil {
+		return s.PRsFromNumbersFn(org, repo, numbers)
+	}
+	result := make(map[int]github.PR, len(numbers))
+	for _, n := range numbers {
+		pr, err := s.PRFromNumber(org, repo, n)
+		if err != nil {
+			return result, err
+		}
+		if pr != nil {
+			result[n] = *pr
+		}
+	}
+	return result, nil
+}
+
+func (s *StubClient) IssueFromNumber(org, repo string, number int) (*github.Issue, error) {
+	if s.IssueFromNumberFn != nil {
+		return s.IssueFromNumberFn(org, repo, number)
+	}
+	return nil, nil
+}
+
 func (s *StubClient) PRDetail(org, repo string, number int) (github.PRDetailResult, error) {
 	if s.PRDetailFn != nil {
 		return s.PRDetailFn(org, repo, number)
@@ -61,3 +110,17 @@ func (s *StubClient) WorkflowRuns(org, repo, branch string, limit int) ([]github
 	}
 	return nil, nil
 }
+
+func (s *StubClient) CreatePR(org, repo string, opts github.CreatePROptions) (*github.PR, error) {
+	if s.CreatePRFn != nil {
+		return s.CreatePRFn(org, repo, opts)
+	}
+	return &github.PR{Number: 1, Title: opts.Title, HeadRefName: opts.Head, State: "OPEN"}, nil
+}
+
+func (s *StubClient) EditPRBody(org, repo string, number int, body string) error {
+	if s.EditPRBodyFn != nil {
+		return s.EditPRBodyFn(org, repo, number, body)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Layout is how a capsule's window is split into panes. With no panes the
+// window has a single shell.
+type Layout struct {
+	Panes   []LayoutPane
+	Arrange string // tmux layout applied once the panes exist, e.g. "main-vertical"
+}
+
+// LayoutPane is one pane of a Layout. The first pane is the window's own;
+// each later one is split off the pane before it.
+type LayoutPane struct {
+	Split   string // "below" stacks the pane under the one before; anything else puts it to the right
+	Size    string // passed to split-window -l, e.g. "30%"
+	Command string // typed into the pane's shell, which stays open after it exits
+	Focus   bool
+}
+
+// run runs a tmux command and returns its trimmed output. Tests replace it.
+var run = func(args ...string) (string, error) {
+	out, err := exec.Command("tmux", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("tmux %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// NewLayoutWindow creates a window in the current session with the given
+// name, starting in path and split into layout's panes, and switches to it.
+func NewLayoutWindow(name, path string, layout Layout) error {
+	return newLayout(path, layout, "new-window", "-n", name)
+}
+
+// NewSessionWindow adds a window to session without switching to it.
+func NewSessionWindow(session, name, path string, layout Layout) error {
+	return newLayout(path, layout, "new-window", "-d", "-t", "="+session+":", "-n", name)
+}
+
+// NewSession creates a detached session whose first window has the given
+// name and layout.
+func NewSession(session, name, path string, layout Layout) error {
+	return newLayout(path, layout, "new-session", "-d", "-s", session, "-n", name)
+}
+
+// newLayout runs create, which makes a window's first pane, then splits the
+// rest of layout's panes off it.
+func newLayout(path string, layout Layout, create ...string) error {
+	first, err := run(append(create, "-c", path, "-P", "-F", "#{pane_id}")...)
+	if err != nil {
+		return err
+	}
+	panes := layout.Panes
+	if len(panes) == 0 {
+		return nil
+	}
+
+	ids := []string{first}
+	focus := first
+	for i, p := range panes {
+		id := first
+		if i > 0 {
+			args := []string{"split-window", "-d", "-t", ids[i-1], "-c", path, "-P", "-F", "#{pane_id}"}
+			if p.Split == "below" {
+				args = append(args, "-v")
+			} else {
+				args = append(args, "-h")
+			}
+			if p.Size != "" {
+				args = append(args, "-l", p.Size)
+			}
+			if id, err = run(args...); err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if p.Command != "" {
+			if _, err := run("send-keys", "-t", id, p.Command, "Enter"); err != nil {
+				return err
+			}
+		}
+		if p.Focus {
+			focus = id
+		}
+	}
+
+	if layout.Arrange != "" {
+		if _, err := run("select-layout", "-t", first, layout.Arrange); err != nil {
+			return err
+		}
+	}
+	_, err = run("select-pane", "-t", focus)
+	return err
+}
+
+// HasSession reports whether a session with exactly this name exists.
+func HasSession(session string) bool {
+	_, err := run("has-session", "-t", "="+session)
+	return err == nil
+}
+
+// SessionWindows returns the window names in session.
+func SessionWindows(session string) []string {
+	out, err := run("list-windows", "-t", "="+session+":", "-F", "#{window_name}")
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// SessionName makes name usable as a session name; tmux doesn't allow "."
+// or ":" in them.
+func SessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// AttachSession attaches the terminal to session, or switches the current
+// client to it when already inside tmux.
+func AttachSession(session string) error {
+	verb := "attach-session"
+	if InTmux() {
+		verb = "switch-client"
+	}
+	c := exec.Command("tmux", verb, "-t", "="+session)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return c.Run()
+}
+
+// KillSession closes session and every window in it.
+func KillSession(session string) error {
+	_, err := run("kill-session", "-t", "="+session)
+	return err
+}
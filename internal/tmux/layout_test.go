@@ -0,0 +1,88 @@
+package tmux
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// record replaces run for the test, returning pane IDs %1, %2, … for each
+// command that prints one.
+func record(t *testing.T) *[]string {
+	t.Helper()
+	var calls []string
+	panes := 0
+	orig := run
+	run = func(args ...string) (string, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if slices.Contains(args, "#{pane_id}") {
+			panes++
+			return fmt.Sprintf("%%%d", panes), nil
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { run = orig })
+	return &calls
+}
+
+func TestNewLayoutWindow_SinglePane(t *testing.T) {
+	calls := record(t)
+	if err := NewLayoutWindow("API:fix", "/ws/repos/api/fix", Layout{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"new-window -n API:fix -c /ws/repos/api/fix -P -F #{pane_id}"}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(*calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNewLayoutWindow_Panes(t *testing.T) {
+	calls := record(t)
+	layout := Layout{
+		Arrange: "main-vertical",
+		Panes: []LayoutPane{
+			{Command: "nvim ."},
+			{Command: "go test ./...", Size: "30%", Focus: true},
+			{Split: "below"},
+		},
+	}
+	if err := NewLayoutWindow("API:fix", "/p", layout); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"new-window -n API:fix -c /p -P -F #{pane_id}",
+		"send-keys -t %1 nvim . Enter",
+		"split-window -d -t %1 -c /p -P -F #{pane_id} -h -l 30%",
+		"send-keys -t %2 go test ./... Enter",
+		"split-window -d -t %2 -c /p -P -F #{pane_id} -v",
+		"select-layout -t %1 main-vertical",
+		"select-pane -t %2",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(*calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNewSession(t *testing.T) {
+	calls := record(t)
+	if err := NewSession("acme", "API:fix", "/p", Layout{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSessionWindow("acme", "Web:main", "/q", Layout{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"new-session -d -s acme -n API:fix -c /p -P -F #{pane_id}",
+		"new-window -d -t =acme: -n Web:main -c /q -P -F #{pane_id}",
+	}
+	if !slices.Equal(*calls, want) {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(*calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSessionName(t *testing.T) {
+	if got := SessionName("acme.io: web"); got != "acme_io_ web" {
+		t.Errorf("SessionName = %q", got)
+	}
+}
@@ -98,17 +98,26 @@ func SelectPane(id string) error {
 	return exec.Command("tmux", "select-pane", "-t", id).Run()
 }
 
-// SplitWindow creates a new pane in an existing window, starting in path.
-func SplitWindow(windowID, path string) error {
-	return exec.Command("tmux", "split-window", "-t", windowID, "-c", path).Start()
+// SplitWindow creates a new pane in an existing window, starting in path and
+// running argv, or a shell if argv is empty.
+func SplitWindow(windowID, path string, argv ...string) error {
+	args := append([]string{"split-window", "-t", windowID, "-c", path}, argv...)
+	return exec.Command("tmux", args...).Run()
 }
 
-// NewWindow creates a new tmux window with the given name, starting in path.
-func NewWindow(name, path string) error {
-	return exec.Command("tmux", "new-window", "-n", name, "-c", path).Start()
+// NewCommandWindow creates a window with the given name running argv in
+// path. The window closes when argv exits.
+func NewCommandWindow(name, path string, argv ...string) error {
+	args := append([]string{"new-window", "-n", name, "-c", path}, argv...)
+	return exec.Command("tmux", args...).Run()
 }
 
 // KillWindow closes a tmux window by ID.
 func KillWindow(id string) error {
 	return exec.Command("tmux", "kill-window", "-t", id).Run()
 }
+
+// RenameWindow renames a tmux window by ID.
+func RenameWindow(id, name string) error {
+	return exec.Command("tmux", "rename-window", "-t", id, name).Run()
+}
@@ -35,6 +35,16 @@ func GitFetchOrigin(dir string) error {
 	return runGit(dir, "fetch", "origin")
 }
 
+// GitPush pushes branch to origin and sets it as the upstream.
+func GitPush(dir, branch string) error {
+	return runGit(dir, "push", "--set-upstream", "origin", branch)
+}
+
+// GitPushDelete deletes branch from origin.
+func GitPushDelete(dir, branch string) error {
+	return runGit(dir, "push", "origin", "--delete", branch)
+}
+
 func GitResetHard(dir, ref string) error {
 	return runGit(dir, "reset", "--hard", ref)
 }
@@ -74,6 +84,17 @@ func GitWorktreeAddNewBranch(gitDir, path, branch, base string) error {
 	return runGit(gitDir, "worktree", "add", path, "-b", branch, base)
 }
 
+// GitWorktreeMove moves a worktree to a new path.
+func GitWorktreeMove(gitDir, from, to string) error {
+	return runGit(gitDir, "worktree", "move", from, to)
+}
+
+// GitBranchRename renames a local branch, including one checked out in a
+// worktree. Its branch.<name>.* config moves with it.
+func GitBranchRename(gitDir, from, to string) error {
+	return runGit(gitDir, "branch", "-m", from, to)
+}
+
 func GitWorktreeRemove(gitDir, path string) error {
 	return runGit(gitDir, "worktree", "remove", path)
 }
@@ -273,6 +294,33 @@ func GitMergedBranches(dir, base string) []string {
 	return branches
 }
 
+// GitSquashMerged reports whether branch's changes have landed on base as a
+// single squashed commit, which "git branch --merged" can't see. It squashes
+// the branch onto its merge base and asks git cherry whether base already
+// has an equivalent patch.
+func GitSquashMerged(dir, base, branch string) bool {
+	mergeBase, err := runGitOutput(dir, "merge-base", base, branch)
+	if err != nil {
+		return false
+	}
+	mergeBase = strings.TrimSpace(mergeBase)
+	if mergeBase == GitRevParse(dir, branch) {
+		// Nothing on the branch, or already merged normally.
+		return false
+	}
+	tree, err := runGitOutput(dir, "rev-parse", branch+"^{tree}")
+	if err != nil {
+		return false
+	}
+	squash, err := runGitOutput(dir, "-c", "user.name=ws", "-c", "user.email=ws@localhost",
+		"commit-tree", strings.TrimSpace(tree), "-p", mergeBase, "-m", "squash")
+	if err != nil {
+		return false
+	}
+	out, err := runGitOutput(dir, "cherry", base, strings.TrimSpace(squash))
+	return err == nil && strings.HasPrefix(out, "-")
+}
+
 // GitRevParse resolves a ref to its commit SHA.
 func GitRevParse(dir, ref string) string {
 	out, err := runGitOutput(dir, "rev-parse", ref)
@@ -282,6 +330,51 @@ func GitRevParse(dir, ref string) string {
 	return strings.TrimSpace(out)
 }
 
+// GitRefExists reports whether ref resolves to a commit.
+func GitRefExists(dir, ref string) bool {
+	_, err := runGitOutput(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// GitIsAncestor reports whether ancestor is reachable from ref.
+func GitIsAncestor(dir, ancestor, ref string) bool {
+	_, err := runGitOutput(dir, "merge-base", "--is-ancestor", ancestor, ref)
+	return err == nil
+}
+
+// GitRebaseOnto replays the commits between upstream and HEAD onto onto.
+func GitRebaseOnto(dir, onto, upstream string) error {
+	return runGit(dir, "rebase", "--onto", onto, upstream)
+}
+
+// GitRebaseContinue continues a stopped rebase without opening an editor.
+func GitRebaseContinue(dir string) error {
+	return runGit(dir, "-c", "core.editor=true", "rebase", "--continue")
+}
+
+// GitRebaseAbort abandons a stopped rebase.
+func GitRebaseAbort(dir string) error {
+	return runGit(dir, "rebase", "--abort")
+}
+
+// GitRebaseInProgress reports whether a rebase is stopped in the worktree.
+func GitRebaseInProgress(dir string) bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		out, err := runGitOutput(dir, "rev-parse", "--git-path", name)
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(out)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func runGit(dir string, args ...string) error {
 	out, err := runGitOutput(dir, args...)
 	if err != nil {
@@ -446,3 +446,35 @@ func TestRepoCloneURL_HTTPS(t *testing.T) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
+
+func TestGitSquashMerged(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "checkout", "-q", "-b", "feature")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644)
+	gitRun(t, dir, "add", "a.txt")
+	gitRun(t, dir, "commit", "-q", "-m", "add a")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\nb\n"), 0o644)
+	gitRun(t, dir, "commit", "-q", "-am", "extend a")
+	gitRun(t, dir, "checkout", "-q", "-b", "unmerged", "main")
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0o644)
+	gitRun(t, dir, "add", "b.txt")
+	gitRun(t, dir, "commit", "-q", "-m", "add b")
+	gitRun(t, dir, "checkout", "-q", "main")
+
+	if GitSquashMerged(dir, "main", "feature") {
+		t.Error("feature reported merged before it landed")
+	}
+
+	gitRun(t, dir, "merge", "-q", "--squash", "feature")
+	gitRun(t, dir, "commit", "-q", "-m", "feature (#1)")
+
+	if !GitSquashMerged(dir, "main", "feature") {
+		t.Error("GitSquashMerged() = false after a squash merge")
+	}
+	if GitSquashMerged(dir, "main", "unmerged") {
+		t.Error("unmerged branch reported merged")
+	}
+	if GitSquashMerged(dir, "main", "main") {
+		t.Error("base reported merged into itself")
+	}
+}
@@ -0,0 +1,50 @@
+package workspace
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IssueRef points at an issue, possibly in another repo.
+type IssueRef struct {
+	Org    string
+	Repo   string
+	Number int
+}
+
+// String renders the ref as "org/repo#123".
+func (r IssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Org, r.Repo, r.Number)
+}
+
+// ParseIssueRef parses a ref written by IssueRef.String.
+func ParseIssueRef(s string) (IssueRef, bool) {
+	slug, num, ok := strings.Cut(s, "#")
+	if !ok {
+		return IssueRef{}, false
+	}
+	org, repo, ok := strings.Cut(slug, "/")
+	n, err := strconv.Atoi(num)
+	if !ok || org == "" || repo == "" || err != nil || n <= 0 {
+		return IssueRef{}, false
+	}
+	return IssueRef{Org: org, Repo: repo, Number: n}, true
+}
+
+// SetCapsuleIssue records the issue branch was lifted from.
+func SetCapsuleIssue(gitDir, branch string, ref IssueRef) error {
+	return runGit(gitDir, "config", "branch."+branch+".ws-issue", ref.String())
+}
+
+// CapsuleIssues returns the issues recorded by SetCapsuleIssue, keyed by
+// branch.
+func CapsuleIssues(gitDir string) map[string]IssueRef {
+	issues := make(map[string]IssueRef)
+	for branch, value := range branchConfig(gitDir, "ws-issue") {
+		if ref, ok := ParseIssueRef(value); ok {
+			issues[branch] = ref
+		}
+	}
+	return issues
+}
@@ -0,0 +1,27 @@
+package workspace
+
+import "testing"
+
+func TestCapsuleIssues(t *testing.T) {
+	dir := initTestRepo(t)
+	ref := IssueRef{Org: "acme", Repo: "api", Number: 482}
+	if err := SetCapsuleIssue(dir, "482-fix-login", ref); err != nil {
+		t.Fatal(err)
+	}
+
+	got := CapsuleIssues(dir)
+	if len(got) != 1 || got["482-fix-login"] != ref {
+		t.Errorf("CapsuleIssues = %v, want %v", got, ref)
+	}
+}
+
+func TestParseIssueRef(t *testing.T) {
+	if ref, ok := ParseIssueRef("acme/api#482"); !ok || ref != (IssueRef{"acme", "api", 482}) {
+		t.Errorf("ParseIssueRef(acme/api#482) = %v, %v", ref, ok)
+	}
+	for _, bad := range []string{"", "api#482", "acme/api", "acme/api#x", "acme/api#0", "/api#1"} {
+		if _, ok := ParseIssueRef(bad); ok {
+			t.Errorf("ParseIssueRef(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// capsuleMetaFile holds the metadata of a repo's capsules, next to .bare.
+const capsuleMetaFile = ".capsules.toml"
+
+// metaMu serialises read-modify-write of metadata files, since bulk
+// operations remove capsules in parallel.
+var metaMu sync.Mutex
+
+// CapsuleMeta is what ws knows about a capsule beyond git: why it exists and
+// how it was made. The issue and PR a capsule is linked to live with its
+// branch instead (see SetCapsuleIssue and SetCapsulePR).
+type CapsuleMeta struct {
+	Note      string    `toml:"note,omitempty"`
+	Tags      []string  `toml:"tags,omitempty"`
+	CreatedAt time.Time `toml:"created_at,omitzero"`
+	CreatedBy string    `toml:"created_by,omitempty"` // command that made the capsule, e.g. "lift"
+	Base      string    `toml:"base,omitempty"`       // ref the branch was lifted from
+}
+
+// HasTag reports whether the capsule is tagged tag.
+func (m CapsuleMeta) HasTag(tag string) bool {
+	return slices.Contains(m.Tags, NormalizeTag(tag))
+}
+
+// NormalizeTag trims tag and drops a leading "#", so "#oncall" and "oncall"
+// are the same tag.
+func NormalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// NormalizeTags normalizes each tag, dropping blanks and duplicates, and
+// sorts them.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		if t = NormalizeTag(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// IsZero reports whether there's nothing recorded.
+func (m CapsuleMeta) IsZero() bool {
+	return m.Note == "" && len(m.Tags) == 0 && m.CreatedAt.IsZero() && m.CreatedBy == "" && m.Base == ""
+}
+
+type capsuleMetaDoc struct {
+	Capsules map[string]CapsuleMeta `toml:"capsules"`
+}
+
+func (w *Workspace) capsuleMetaPath(repo string) string {
+	return filepath.Join(w.RepoDir(repo), capsuleMetaFile)
+}
+
+// CapsuleMetas returns the metadata of repo's capsules, keyed by capsule
+// name. A missing or unreadable file gives an empty map.
+func (w *Workspace) CapsuleMetas(repo string) map[string]CapsuleMeta {
+	var doc capsuleMetaDoc
+	toml.DecodeFile(w.capsuleMetaPath(repo), &doc)
+	if doc.Capsules == nil {
+		doc.Capsules = make(map[string]CapsuleMeta)
+	}
+	return doc.Capsules
+}
+
+// UpdateCapsuleMeta applies fn to a capsule's metadata and saves it.
+func (w *Workspace) UpdateCapsuleMeta(repo, capsule string, fn func(*CapsuleMeta)) error {
+	return w.updateCapsuleMetas(repo, func(metas map[string]CapsuleMeta) {
+		meta := metas[capsule]
+		fn(&meta)
+		if meta.IsZero() {
+			delete(metas, capsule)
+		} else {
+			metas[capsule] = meta
+		}
+	})
+}
+
+// RemoveCapsuleMeta forgets a capsule's metadata.
+func (w *Workspace) RemoveCapsuleMeta(repo, capsule string) error {
+	return w.updateCapsuleMetas(repo, func(metas map[string]CapsuleMeta) {
+		delete(metas, capsule)
+	})
+}
+
+// renameCapsuleMeta moves a capsule's metadata to its new name.
+func (w *Workspace) renameCapsuleMeta(repo, from, to string) error {
+	return w.updateCapsuleMetas(repo, func(metas map[string]CapsuleMeta) {
+		if meta, ok := metas[from]; ok {
+			delete(metas, from)
+			metas[to] = meta
+		}
+	})
+}
+
+func (w *Workspace) updateCapsuleMetas(repo string, fn func(map[string]CapsuleMeta)) error {
+	metaMu.Lock()
+	defer metaMu.Unlock()
+
+	path := w.capsuleMetaPath(repo)
+	var doc capsuleMetaDoc
+	if _, err := toml.DecodeFile(path, &doc); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", capsuleMetaFile, err)
+	}
+	if doc.Capsules == nil {
+		doc.Capsules = make(map[string]CapsuleMeta)
+	}
+	fn(doc.Capsules)
+
+	if len(doc.Capsules) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", capsuleMetaFile, err)
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(doc)
+}
+
+// FilterOutlinesByTag keeps only the capsules tagged tag, dropping repos
+// left with none.
+func (w *Workspace) FilterOutlinesByTag(outlines []RepoOutline, tag string) []RepoOutline {
+	var filtered []RepoOutline
+	for _, o := range outlines {
+		if o.Err != nil {
+			continue
+		}
+		metas := w.CapsuleMetas(o.Name)
+		var wts []string
+		for _, wt := range o.Worktrees {
+			if metas[wt].HasTag(tag) {
+				wts = append(wts, wt)
+			}
+		}
+		if len(wts) > 0 {
+			o.Worktrees = wts
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCapsuleMeta_UpdateRenameRemove(t *testing.T) {
+	ws := &Workspace{Root: t.TempDir()}
+	os.MkdirAll(ws.RepoDir("repo-a"), 0755)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := ws.UpdateCapsuleMeta("repo-a", "feat", func(m *CapsuleMeta) {
+		*m = CapsuleMeta{Note: "why", Tags: []string{"oncall"}, CreatedAt: created, CreatedBy: "lift", Base: "origin/main"}
+	})
+	if err != nil {
+		t.Fatalf("UpdateCapsuleMeta: %v", err)
+	}
+
+	meta := ws.CapsuleMetas("repo-a")["feat"]
+	if meta.Note != "why" || !meta.HasTag("oncall") || !meta.CreatedAt.Equal(created) || meta.Base != "origin/main" {
+		t.Errorf("meta = %+v, want what was saved", meta)
+	}
+
+	if err := ws.renameCapsuleMeta("repo-a", "feat", "short"); err != nil {
+		t.Fatalf("renameCapsuleMeta: %v", err)
+	}
+	metas := ws.CapsuleMetas("repo-a")
+	if _, ok := metas["feat"]; ok || metas["short"].Note != "why" {
+		t.Errorf("after rename metas = %+v, want the note under short", metas)
+	}
+
+	if err := ws.RemoveCapsuleMeta("repo-a", "short"); err != nil {
+		t.Fatalf("RemoveCapsuleMeta: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ws.RepoDir("repo-a"), capsuleMetaFile)); !os.IsNotExist(err) {
+		t.Error("metadata file should be removed once empty")
+	}
+}
+
+func TestCapsuleMeta_ClearingDropsEntry(t *testing.T) {
+	ws := &Workspace{Root: t.TempDir()}
+	os.MkdirAll(ws.RepoDir("repo-a"), 0755)
+
+	ws.UpdateCapsuleMeta("repo-a", "feat", func(m *CapsuleMeta) { m.Note = "why" })
+	ws.UpdateCapsuleMeta("repo-a", "feat", func(m *CapsuleMeta) { m.Note = "" })
+
+	if metas := ws.CapsuleMetas("repo-a"); len(metas) != 0 {
+		t.Errorf("metas = %+v, want none", metas)
+	}
+}
+
+func TestFilterOutlinesByTag(t *testing.T) {
+	ws := &Workspace{Root: t.TempDir()}
+	os.MkdirAll(ws.RepoDir("repo-a"), 0755)
+	ws.UpdateCapsuleMeta("repo-a", "b", func(m *CapsuleMeta) { m.Tags = []string{"oncall"} })
+
+	outlines := []RepoOutline{
+		{Name: "repo-a", Worktrees: []string{GroundDir, "a", "b"}},
+		{Name: "repo-b", Worktrees: []string{GroundDir, "c"}},
+	}
+	got := ws.FilterOutlinesByTag(outlines, "oncall")
+	if len(got) != 1 || got[0].Name != "repo-a" || len(got[0].Worktrees) != 1 || got[0].Worktrees[0] != "b" {
+		t.Errorf("FilterOutlinesByTag = %+v, want repo-a with just b", got)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{"q3", " #oncall", "oncall", "", "#"})
+	if !slices.Equal(got, []string{"oncall", "q3"}) {
+		t.Errorf("NormalizeTags = %v, want [oncall q3]", got)
+	}
+	if !(CapsuleMeta{Tags: got}).HasTag("#oncall") {
+		t.Error(`HasTag("#oncall") should match oncall`)
+	}
+}
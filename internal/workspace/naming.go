@@ -0,0 +1,222 @@
+package workspace
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// DefaultBranchTemplate names branches lifted from an issue when ws.toml
+// doesn't set [issues] branch_template.
+const DefaultBranchTemplate = "{{.Number}}-{{slug .Title}}"
+
+// maxSlugLength caps the slug of an issue title, so branch names stay short.
+const maxSlugLength = 40
+
+// BranchData is what branch, prefix and capsule templates are rendered
+// against. Fields that don't apply are left empty.
+type BranchData struct {
+	Number int    // issue number, for capsules lifted from an issue
+	Title  string // issue title
+	User   string // GitHub login
+	Repo   string
+	Branch string // full branch name, for capsule templates
+	Name   string // last segment of the branch, for capsule templates
+}
+
+// nameFuncs are the functions naming templates can use.
+var nameFuncs = template.FuncMap{
+	"slug":  Slug,
+	"trunc": trunc,
+}
+
+func parseNameTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Funcs(nameFuncs).Parse(text)
+}
+
+func renderNameTemplate(name, text string, data BranchData) (string, error) {
+	tmpl, err := parseNameTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// BranchFromTemplate renders a branch name from text.
+func BranchFromTemplate(text string, data BranchData) (string, error) {
+	branch, err := renderNameTemplate("branch", text, data)
+	if err != nil {
+		return "", err
+	}
+	if branch == "" || strings.HasPrefix(branch, "/") || strings.HasSuffix(branch, "/") || strings.Contains(branch, "//") {
+		return "", fmt.Errorf("branch template gave an invalid branch name %q", branch)
+	}
+	return branch, nil
+}
+
+// Slug turns "Fix login redirect (Safari)" into "fix-login-redirect-safari",
+// cut at a word boundary to keep it short.
+func Slug(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if b.Len() > 0 && b.Len()+1+len(w) > maxSlugLength {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// trunc cuts s to at most n characters, dropping separators left dangling
+// at the end.
+func trunc(n int, s string) string {
+	if r := []rune(s); len(r) > n {
+		s = string(r[:max(n, 0)])
+	}
+	return strings.TrimRight(s, "-_./")
+}
+
+// BranchPolicy is the workspace's branch naming policy, from [branches] in
+// ws.toml. The zero value allows any branch name.
+type BranchPolicy struct {
+	// Prefix is a template, e.g. "{{.User}}/", put in front of branches
+	// that don't already start with it.
+	Prefix string
+	// Charset is the body of a regexp character class listing the
+	// characters branch names may use, e.g. "a-z0-9/._-".
+	Charset string
+	// MaxLength caps the length of branch names. 0 means no limit.
+	MaxLength int
+	// Pattern is a regexp every branch name must match.
+	Pattern string
+	// Fix rewrites names that break Charset or MaxLength instead of
+	// rejecting them: uppercase letters are lowered, other characters
+	// become hyphens and long names are cut.
+	Fix bool
+	// CapsuleTemplate names capsule directories, rendered with .Branch,
+	// .Name and .Repo. By default a capsule is named after the last
+	// segment of its branch.
+	CapsuleTemplate string
+}
+
+// Validate checks that the policy's patterns and templates compile.
+func (p BranchPolicy) Validate() error {
+	if _, err := p.allowed(); err != nil {
+		return fmt.Errorf("charset: %w", err)
+	}
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+	}
+	if p.MaxLength < 0 {
+		return fmt.Errorf("max_length can't be negative")
+	}
+	if _, err := parseNameTemplate("prefix", p.Prefix); err != nil {
+		return fmt.Errorf("prefix: %w", err)
+	}
+	if _, err := parseNameTemplate("capsule", p.CapsuleTemplate); err != nil {
+		return fmt.Errorf("capsule_template: %w", err)
+	}
+	return nil
+}
+
+// allowed returns a regexp matching one allowed character, or nil when any
+// character is allowed.
+func (p BranchPolicy) allowed() (*regexp.Regexp, error) {
+	if p.Charset == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^[" + p.Charset + "]$")
+}
+
+// Apply puts the policy's prefix on branch, fixes it up if the policy says
+// to, and checks it against the policy.
+func (p BranchPolicy) Apply(branch string, data BranchData) (string, error) {
+	if p.Prefix != "" {
+		prefix, err := renderNameTemplate("prefix", p.Prefix, data)
+		if err != nil {
+			return "", fmt.Errorf("prefix: %w", err)
+		}
+		if !strings.HasPrefix(branch, prefix) {
+			branch = prefix + branch
+		}
+	}
+
+	allowed, err := p.allowed()
+	if err != nil {
+		return "", fmt.Errorf("charset: %w", err)
+	}
+	ok := func(r rune) bool { return allowed == nil || allowed.MatchString(string(r)) }
+
+	if p.Fix {
+		var b strings.Builder
+		for _, r := range branch {
+			switch {
+			case ok(r):
+			case ok(unicode.ToLower(r)):
+				r = unicode.ToLower(r)
+			default:
+				r = '-'
+			}
+			if r == '-' && strings.HasSuffix(b.String(), "-") {
+				continue
+			}
+			b.WriteRune(r)
+		}
+		branch = strings.Trim(b.String(), "-")
+		if p.MaxLength > 0 {
+			branch = trunc(p.MaxLength, branch)
+		}
+	}
+
+	var bad []string
+	for _, r := range branch {
+		if !ok(r) && !strings.ContainsRune(strings.Join(bad, ""), r) {
+			bad = append(bad, string(r))
+		}
+	}
+	if len(bad) > 0 {
+		return "", fmt.Errorf("branch %q uses %q, which [branches] charset doesn't allow", branch, strings.Join(bad, ""))
+	}
+	if n := len([]rune(branch)); p.MaxLength > 0 && n > p.MaxLength {
+		return "", fmt.Errorf("branch %q is %d characters, over the [branches] max_length of %d", branch, n, p.MaxLength)
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("pattern: %w", err)
+		}
+		if !re.MatchString(branch) {
+			return "", fmt.Errorf("branch %q doesn't match the [branches] pattern %s", branch, p.Pattern)
+		}
+	}
+	return branch, nil
+}
+
+// CapsuleDir returns the directory name for a capsule of branch in repo,
+// from the branch policy's capsule template when there is one.
+func (w *Workspace) CapsuleDir(repo, branch string) string {
+	name := CapsuleName(branch)
+	if w.BranchPolicy.CapsuleTemplate == "" {
+		return name
+	}
+	dir, err := renderNameTemplate("capsule", w.BranchPolicy.CapsuleTemplate, BranchData{Repo: repo, Branch: branch, Name: name})
+	dir = strings.Trim(strings.ReplaceAll(dir, "/", "-"), "-.")
+	if err != nil || dir == "" {
+		return name
+	}
+	return dir
+}
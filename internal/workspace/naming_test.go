@@ -0,0 +1,125 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBranchFromTemplate(t *testing.T) {
+	data := BranchData{Number: 482, Title: "Fix login redirect (Safari)", User: "octocat", Repo: "api"}
+	for tmpl, want := range map[string]string{
+		DefaultBranchTemplate:                   "482-fix-login-redirect-safari",
+		"{{.User}}/{{.Number}}-{{slug .Title}}": "octocat/482-fix-login-redirect-safari",
+		"{{.Repo}}/issue-{{.Number}}":           "api/issue-482",
+	} {
+		got, err := BranchFromTemplate(tmpl, data)
+		if err != nil || got != want {
+			t.Errorf("BranchFromTemplate(%q) = %q, %v; want %q", tmpl, got, err, want)
+		}
+	}
+
+	if _, err := BranchFromTemplate("{{.User}}/{{.Number}}", BranchData{Number: 1}); err == nil {
+		t.Error("expected an error for a branch starting with a slash")
+	}
+	if _, err := BranchFromTemplate("{{.Nope}}", data); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestSlug(t *testing.T) {
+	for in, want := range map[string]string{
+		"Fix login redirect":          "fix-login-redirect",
+		"  API: 500s on /users?id=  ": "api-500s-on-users-id",
+		"Überprüfung der Einträge":    "überprüfung-der-einträge",
+		"":                            "",
+	} {
+		if got := Slug(in); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+	long := Slug("make the settings page load faster on slow connections please")
+	if len(long) > maxSlugLength || long != "make-the-settings-page-load-faster-on" {
+		t.Errorf("Slug(long) = %q, want it cut at a word under %d", long, maxSlugLength)
+	}
+}
+
+func TestBranchPolicy_Prefix(t *testing.T) {
+	p := BranchPolicy{Prefix: "{{.User}}/"}
+	data := BranchData{User: "octocat"}
+
+	for in, want := range map[string]string{
+		"login-fix":         "octocat/login-fix",
+		"octocat/login-fix": "octocat/login-fix",
+	} {
+		got, err := p.Apply(in, data)
+		if err != nil || got != want {
+			t.Errorf("Apply(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestBranchPolicy_Enforce(t *testing.T) {
+	p := BranchPolicy{Charset: "a-z0-9/-", MaxLength: 20, Pattern: `^(feat|fix)/`}
+
+	if got, err := p.Apply("feat/login", BranchData{}); err != nil || got != "feat/login" {
+		t.Errorf("Apply(feat/login) = %q, %v; want it unchanged", got, err)
+	}
+	for in, want := range map[string]string{
+		"feat/Login_Page":                  "charset",
+		"feat/a-much-too-long-branch-name": "max_length",
+		"chore/deps":                       "pattern",
+	} {
+		if _, err := p.Apply(in, BranchData{}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Apply(%q) error = %v, want a %s error", in, err, want)
+		}
+	}
+}
+
+func TestBranchPolicy_Fix(t *testing.T) {
+	p := BranchPolicy{Charset: "a-z0-9/-", MaxLength: 20, Fix: true}
+
+	for in, want := range map[string]string{
+		"Feat/Login Page!!":                "feat/login-page",
+		"feat/a-much-too-long-branch-name": "feat/a-much-too-long",
+		"fix/trailing-cut-here-x":          "fix/trailing-cut-her",
+	} {
+		got, err := p.Apply(in, BranchData{})
+		if err != nil || got != want {
+			t.Errorf("Apply(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestBranchPolicy_Validate(t *testing.T) {
+	if err := (BranchPolicy{}).Validate(); err != nil {
+		t.Errorf("zero policy: %v", err)
+	}
+	for _, p := range []BranchPolicy{
+		{Charset: "z-a"},
+		{Pattern: "("},
+		{MaxLength: -1},
+		{Prefix: "{{.User"},
+		{CapsuleTemplate: "{{nope .Name}}"},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", p)
+		}
+	}
+}
+
+func TestCapsuleDir(t *testing.T) {
+	w := &Workspace{}
+	if got := w.CapsuleDir("api", "octocat/482-fix-login"); got != "482-fix-login" {
+		t.Errorf("default CapsuleDir = %q, want the last segment", got)
+	}
+
+	w.BranchPolicy.CapsuleTemplate = "{{trunc 9 .Name}}"
+	if got := w.CapsuleDir("api", "octocat/482-fix-login"); got != "482-fix-l" {
+		t.Errorf("CapsuleDir = %q, want %q", got, "482-fix-l")
+	}
+
+	w.BranchPolicy.CapsuleTemplate = "{{.Branch}}"
+	if got := w.CapsuleDir("api", "octocat/login"); got != "octocat-login" {
+		t.Errorf("CapsuleDir = %q, want slashes replaced", got)
+	}
+}
@@ -97,7 +97,7 @@ func (w *Workspace) CreateLiftWorktree(repo, branch, base string) (string, error
 	bareDir := w.BareDir(repo)
 	runGit(bareDir, "config", "push.autoSetupRemote", "true") // idempotent
 
-	capsule := UniqueCapsuleName(w.RepoDir(repo), branch)
+	capsule := uniqueDir(w.RepoDir(repo), w.CapsuleDir(repo, branch))
 	wtPath := filepath.Join(w.RepoDir(repo), capsule)
 	if err := GitWorktreeAddNewBranch(bareDir, wtPath, branch, base); err != nil {
 		return "", fmt.Errorf("creating worktree: %w", err)
@@ -111,11 +111,26 @@ func (w *Workspace) CreateLiftWorktree(repo, branch, base string) (string, error
 	return capsule, nil
 }
 
+// CreateStackedWorktree creates a new branch on top of parent's branch and
+// records parent, so the new capsule can be restacked when parent moves.
+// Does not fetch — caller is responsible for fetching first.
+func (w *Workspace) CreateStackedWorktree(repo, branch, parent string) (string, error) {
+	capsule, err := w.CreateLiftWorktree(repo, branch, parent)
+	if err != nil {
+		return "", err
+	}
+	bareDir := w.BareDir(repo)
+	if err := SetStackParent(bareDir, branch, parent, GitRevParse(bareDir, parent)); err != nil {
+		return "", fmt.Errorf("recording parent of %s: %w", branch, err)
+	}
+	return capsule, nil
+}
+
 // CreateDockWorktree checks out an existing branch into a new worktree.
 // Does not fetch — caller is responsible for fetching first.
 // Returns the capsule directory name used for the worktree.
 func (w *Workspace) CreateDockWorktree(repo, branch string) (string, error) {
-	capsule := UniqueCapsuleName(w.RepoDir(repo), branch)
+	capsule := uniqueDir(w.RepoDir(repo), w.CapsuleDir(repo, branch))
 	wtPath := filepath.Join(w.RepoDir(repo), capsule)
 	if err := GitWorktreeAddBranch(w.BareDir(repo), wtPath, branch); err != nil {
 		return "", fmt.Errorf("creating worktree: %w", err)
@@ -168,6 +183,8 @@ func (w *Workspace) RemoveWorktree(repo, branch string, force bool) error {
 	if err := remove(w.BareDir(repo), wtPath); err != nil {
 		return fmt.Errorf("removing worktree: %w", err)
 	}
+	w.RemoveCapsuleMeta(repo, branch)
+	os.RemoveAll(w.CapsuleWindowDir(repo, branch))
 	return nil
 }
 
@@ -235,3 +235,24 @@ func TestCopyFromGround_EmptyList(t *testing.T) {
 		t.Errorf("skipped = %v, want empty", skipped)
 	}
 }
+
+func TestRenameCapsule_Invalid(t *testing.T) {
+	root := t.TempDir()
+	ws := &Workspace{Root: root}
+	os.MkdirAll(filepath.Join(ws.RepoDir("repo-a"), "feat"), 0755)
+	os.MkdirAll(filepath.Join(ws.RepoDir("repo-a"), "taken"), 0755)
+
+	tests := []struct{ capsule, newName string }{
+		{GroundDir, "ground"},
+		{"feat", ""},
+		{"feat", ".hidden"},
+		{"feat", "a/b"},
+		{"feat", "taken"},
+		{"missing", "other"},
+	}
+	for _, tt := range tests {
+		if err := ws.RenameCapsule("repo-a", tt.capsule, tt.newName); err == nil {
+			t.Errorf("RenameCapsule(%q, %q) = nil, want error", tt.capsule, tt.newName)
+		}
+	}
+}
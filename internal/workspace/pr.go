@@ -0,0 +1,145 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// prTemplatePaths are the places GitHub looks for a pull request template,
+// in the order it checks them.
+var prTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+// SetCapsulePR records the PR opened for branch, so it can be linked before
+// GitHub's PR list catches up.
+func SetCapsulePR(gitDir, branch string, number int) error {
+	return runGit(gitDir, "config", "branch."+branch+".ws-pr", strconv.Itoa(number))
+}
+
+// ClearCapsulePR forgets the PR recorded for branch.
+func ClearCapsulePR(gitDir, branch string) {
+	runGit(gitDir, "config", "--unset", "branch."+branch+".ws-pr")
+}
+
+// RecordedPRs returns the PR numbers recorded by SetCapsulePR, keyed by
+// branch.
+func RecordedPRs(gitDir string) map[string]int {
+	prs := make(map[string]int)
+	for branch, value := range branchConfig(gitDir, "ws-pr") {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			prs[branch] = n
+		}
+	}
+	return prs
+}
+
+// SetPRGroup links branch's PR with PRs in other repos under group, for
+// changes whose branches don't share a name.
+func SetPRGroup(gitDir, branch, group string) error {
+	return runGit(gitDir, "config", "branch."+branch+".ws-group", group)
+}
+
+// ClearPRGroup removes branch from its PR group.
+func ClearPRGroup(gitDir, branch string) {
+	runGit(gitDir, "config", "--unset", "branch."+branch+".ws-group")
+}
+
+// PRGroups returns the groups recorded by SetPRGroup, keyed by branch.
+func PRGroups(gitDir string) map[string]string {
+	return branchConfig(gitDir, "ws-group")
+}
+
+// branchConfig returns the value of branch.<name>.<key> for every branch
+// that sets it.
+func branchConfig(gitDir, key string) map[string]string {
+	out, err := runGitOutput(gitDir, "config", "--get-regexp", `^branch\..*\.`+key+`$`)
+	if err != nil {
+		return nil
+	}
+	values := make(map[string]string)
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		name, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		branch := strings.TrimSuffix(strings.TrimPrefix(name, "branch."), "."+key)
+		values[branch] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// PRTemplate returns the repo's pull request template from the worktree at
+// dir, or "" when it has none.
+func PRTemplate(dir string) string {
+	for _, p := range prTemplatePaths {
+		data, err := os.ReadFile(filepath.Join(dir, p))
+		if err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return ""
+}
+
+// PRDraft prefills a PR title and body from the commits on HEAD since base,
+// the same way gh does: a single commit supplies both, several commits give
+// a title from the branch name and list their subjects in the body. The
+// repo's PR template, if any, follows.
+func PRDraft(dir, base string) (title, body string) {
+	var subjects []string
+	if out, err := runGitOutput(dir, "log", "--reverse", "--format=%s", base+"..HEAD"); err == nil {
+		for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+			if line != "" {
+				subjects = append(subjects, line)
+			}
+		}
+	}
+
+	var parts []string
+	switch len(subjects) {
+	case 0:
+		title = titleFromBranch(GitCurrentBranch(dir))
+	case 1:
+		title = subjects[0]
+		if out, err := runGitOutput(dir, "log", "-1", "--format=%b"); err == nil {
+			if b := strings.TrimSpace(out); b != "" {
+				parts = append(parts, b)
+			}
+		}
+	default:
+		title = titleFromBranch(GitCurrentBranch(dir))
+		list := make([]string, len(subjects))
+		for i, s := range subjects {
+			list[i] = "- " + s
+		}
+		parts = append(parts, strings.Join(list, "\n"))
+	}
+	if tmpl := PRTemplate(dir); tmpl != "" {
+		parts = append(parts, tmpl)
+	}
+	return title, strings.Join(parts, "\n\n")
+}
+
+// titleFromBranch turns "fix/login-redirect" into "Login redirect".
+func titleFromBranch(branch string) string {
+	if i := strings.LastIndex(branch, "/"); i >= 0 {
+		branch = branch[i+1:]
+	}
+	title := strings.Join(strings.FieldsFunc(branch, func(r rune) bool {
+		return r == '-' || r == '_'
+	}), " ")
+	if title == "" {
+		return branch
+	}
+	r := []rune(title)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPRDraft_SingleCommit(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "checkout", "-q", "-b", "fix/login-redirect")
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "Fix login redirect", "-m", "It looped forever.")
+
+	title, body := PRDraft(dir, "main")
+	if title != "Fix login redirect" {
+		t.Errorf("title = %q, want the commit subject", title)
+	}
+	if body != "It looped forever." {
+		t.Errorf("body = %q, want the commit body", body)
+	}
+}
+
+func TestPRDraft_SeveralCommitsAndTemplate(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "checkout", "-q", "-b", "fix/login-redirect")
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "First")
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "Second")
+	os.MkdirAll(filepath.Join(dir, ".github"), 0755)
+	os.WriteFile(filepath.Join(dir, ".github", "pull_request_template.md"), []byte("## Testing\n"), 0644)
+
+	title, body := PRDraft(dir, "main")
+	if title != "Login redirect" {
+		t.Errorf("title = %q, want it from the branch name", title)
+	}
+	if want := "- First\n- Second\n\n## Testing"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestRecordedPRs(t *testing.T) {
+	dir := initTestRepo(t)
+	if err := SetCapsulePR(dir, "feature/a", 12); err != nil {
+		t.Fatal(err)
+	}
+	SetCapsulePR(dir, "b", 34)
+
+	got := RecordedPRs(dir)
+	if len(got) != 2 || got["feature/a"] != 12 || got["b"] != 34 {
+		t.Errorf("RecordedPRs = %v", got)
+	}
+
+	ClearCapsulePR(dir, "b")
+	if _, ok := RecordedPRs(dir)["b"]; ok {
+		t.Error("b should be cleared")
+	}
+}
+
+func TestTitleFromBranch(t *testing.T) {
+	for branch, want := range map[string]string{
+		"fix/login-redirect": "Login redirect",
+		"add_dark_mode":      "Add dark mode",
+		"wip":                "Wip",
+		"---":                "---",
+	} {
+		if got := titleFromBranch(branch); got != want {
+			t.Errorf("titleFromBranch(%q) = %q, want %q", branch, got, want)
+		}
+	}
+	if strings.Contains(titleFromBranch("a/b/c-d"), "/") {
+		t.Error("only the last path segment should be used")
+	}
+}
@@ -0,0 +1,140 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Process is a process working in a capsule or holding its files open.
+type Process struct {
+	PID     int
+	Command string
+	InDir   bool // its working directory is in the capsule
+	Open    int  // files in the capsule it holds open
+}
+
+// HoldsFiles reports whether p has files in the capsule open, as a dev
+// server or file watcher would.
+func (p Process) HoldsFiles() bool {
+	return p.Open > 0
+}
+
+// OpenFiles is a snapshot of every process's open files, taken once so that
+// several capsules can be checked against it.
+type OpenFiles struct {
+	procs []openFilesProc
+}
+
+type openFilesProc struct {
+	pid     int
+	command string
+	files   []openFile
+}
+
+type openFile struct {
+	fd, name string
+}
+
+// ListOpenFiles asks lsof for every process's open files, leaving out ws
+// itself and the shell that ran it.
+func ListOpenFiles() (*OpenFiles, error) {
+	out, err := exec.Command("lsof", "-w", "-n", "-P", "-F", "pcfn").Output()
+	if err != nil && len(out) == 0 {
+		// lsof exits 1 when some processes can't be read, but still
+		// lists the rest.
+		return nil, fmt.Errorf("listing open files: %w", err)
+	}
+	files := parseLsof(string(out))
+	files.procs = slices.DeleteFunc(files.procs, func(p openFilesProc) bool {
+		return p.pid == os.Getpid() || p.pid == os.Getppid()
+	})
+	return files, nil
+}
+
+// CapsuleProcesses lists the processes working in dir or holding files in it
+// open. It takes its own snapshot; use ListOpenFiles to check several dirs.
+func CapsuleProcesses(dir string) ([]Process, error) {
+	files, err := ListOpenFiles()
+	if err != nil {
+		return nil, err
+	}
+	return files.CapsuleProcesses(dir), nil
+}
+
+// CapsuleProcesses lists the processes in the snapshot working in dir or
+// holding files in it open.
+func (o *OpenFiles) CapsuleProcesses(dir string) []Process {
+	dirs := []string{dir}
+	if real, err := filepath.EvalSymlinks(dir); err == nil && real != dir {
+		dirs = append(dirs, real)
+	}
+	return o.in(dirs)
+}
+
+// in returns the processes with a file under one of dirs.
+func (o *OpenFiles) in(dirs []string) []Process {
+	inside := func(name string) bool {
+		for _, d := range dirs {
+			if name == d || strings.HasPrefix(name, d+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var procs []Process
+	for _, op := range o.procs {
+		p := Process{PID: op.pid, Command: op.command}
+		for _, f := range op.files {
+			if !inside(f.name) {
+				continue
+			}
+			switch f.fd {
+			case "cwd":
+				p.InDir = true
+			case "rtd":
+			default:
+				p.Open++
+			}
+		}
+		if p.InDir || p.Open > 0 {
+			procs = append(procs, p)
+		}
+	}
+	return procs
+}
+
+// parseLsof reads lsof -F pcfn output.
+func parseLsof(output string) *OpenFiles {
+	files := &OpenFiles{}
+	var cur *openFilesProc
+	var fd string
+	for line := range strings.SplitSeq(output, "\n") {
+		if line == "" {
+			continue
+		}
+		field, value := line[0], line[1:]
+		switch field {
+		case 'p':
+			pid, _ := strconv.Atoi(value)
+			files.procs = append(files.procs, openFilesProc{pid: pid})
+			cur = &files.procs[len(files.procs)-1]
+		case 'c':
+			if cur != nil {
+				cur.command = value
+			}
+		case 'f':
+			fd = value
+		case 'n':
+			if cur != nil {
+				cur.files = append(cur.files, openFile{fd: fd, name: value})
+			}
+		}
+	}
+	return files
+}
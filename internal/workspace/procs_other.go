@@ -0,0 +1,13 @@
+//go:build !unix
+
+package workspace
+
+import (
+	"errors"
+	"time"
+)
+
+// StopProcesses can't signal processes here, so stops none.
+func StopProcesses(procs []Process, timeout time.Duration) ([]Process, error) {
+	return nil, errors.New("can't stop processes on this platform")
+}
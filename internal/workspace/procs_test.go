@@ -0,0 +1,50 @@
+package workspace
+
+import "testing"
+
+func TestParseLsof(t *testing.T) {
+	output := `p100
+czsh
+fcwd
+n/ws/repos/api/fix
+f3
+n/dev/ttys001
+p200
+cnode
+fcwd
+n/ws/repos/api/fix/web
+ftxt
+n/usr/local/bin/node
+f21
+n/ws/repos/api/fix/web/dist/app.js
+f22
+n/ws/repos/api/fix/web/.cache/db
+p300
+cvim
+fcwd
+n/ws/repos/api/fix-other
+p400
+cgit
+frtd
+n/ws/repos/api/fix
+`
+	got := parseLsof(output).CapsuleProcesses("/ws/repos/api/fix")
+	want := []Process{
+		{PID: 100, Command: "zsh", InDir: true},
+		{PID: 200, Command: "node", InDir: true, Open: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("process %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if got[0].HoldsFiles() || !got[1].HoldsFiles() {
+		t.Error("HoldsFiles wrong")
+	}
+	if other := parseLsof(output).CapsuleProcesses("/ws/repos/api/fix-other"); len(other) != 1 || other[0].PID != 300 {
+		t.Errorf("fix-other = %+v, want vim from the same snapshot", other)
+	}
+}
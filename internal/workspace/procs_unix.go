@@ -0,0 +1,45 @@
+//go:build unix
+
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// StopProcesses sends each process SIGTERM and waits up to timeout for them
+// to exit. It returns the processes that have stopped.
+func StopProcesses(procs []Process, timeout time.Duration) ([]Process, error) {
+	for _, p := range procs {
+		syscall.Kill(p.PID, syscall.SIGTERM)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		var stopped []Process
+		for _, p := range procs {
+			if !processAlive(p.PID) {
+				stopped = append(stopped, p)
+			}
+		}
+		if len(stopped) == len(procs) || time.Now().After(deadline) {
+			return stopped, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func processAlive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	// A child of ours that has exited lingers as a zombie until reaped.
+	state, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	_, rest, _ := strings.Cut(string(state), ") ")
+	return !strings.HasPrefix(rest, "Z")
+}
@@ -0,0 +1,35 @@
+//go:build unix
+
+package workspace
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCapsuleProcesses_FindsAndStops(t *testing.T) {
+	if _, err := exec.LookPath("lsof"); err != nil {
+		t.Skip("lsof not installed")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("sleep", "30")
+	cmd.Dir = dir
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+
+	procs, err := CapsuleProcesses(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 1 || procs[0].PID != cmd.Process.Pid || !procs[0].InDir {
+		t.Fatalf("procs = %+v, want sleep %d", procs, cmd.Process.Pid)
+	}
+
+	stopped, err := StopProcesses(procs, 5*time.Second)
+	if err != nil || len(stopped) != 1 {
+		t.Errorf("stopped = %+v, want the sleep", stopped)
+	}
+}
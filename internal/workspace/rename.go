@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RenameCapsule moves a capsule's worktree to newName, carrying its metadata,
+// board and silo state over. The caller saves Boarded and Silo.
+func (w *Workspace) RenameCapsule(repo, capsule, newName string) error {
+	if capsule == GroundDir || capsule == SiloDir {
+		return fmt.Errorf("cannot rename %s", capsule)
+	}
+	if newName == "" || strings.HasPrefix(newName, ".") || strings.ContainsAny(newName, `/\`) {
+		return fmt.Errorf("%q is not a valid capsule name", newName)
+	}
+	from := filepath.Join(w.RepoDir(repo), capsule)
+	to := filepath.Join(w.RepoDir(repo), newName)
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Errorf("capsule %s/%s does not exist", repo, capsule)
+	}
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("capsule %q already exists for %s", newName, repo)
+	}
+	if err := GitWorktreeMove(w.BareDir(repo), from, to); err != nil {
+		return fmt.Errorf("moving worktree: %w", err)
+	}
+
+	if err := w.renameCapsuleMeta(repo, capsule, newName); err != nil {
+		GitWorktreeMove(w.BareDir(repo), to, from)
+		return fmt.Errorf("moving capsule metadata: %w", err)
+	}
+	if _, err := os.Stat(w.CapsuleWindowDir(repo, capsule)); err == nil {
+		os.Rename(w.CapsuleWindowDir(repo, capsule), w.CapsuleWindowDir(repo, newName))
+	}
+	if i := slices.Index(w.Boarded[repo], capsule); i >= 0 {
+		w.Boarded[repo][i] = newName
+	}
+	if w.Silo[repo] == capsule {
+		w.Silo[repo] = newName
+	}
+	return nil
+}
+
+// RenameBranch renames a capsule's branch locally. Its per-branch metadata
+// moves with it, and capsules stacked on it are pointed at the new name.
+// A branch that tracked origin is set to track its new name there; one that
+// tracked nothing still doesn't. If the upstream can't be set, the rename is
+// undone.
+func (w *Workspace) RenameBranch(repo, from, to string) error {
+	bareDir := w.BareDir(repo)
+	if GitRefExists(bareDir, "refs/heads/"+to) {
+		return fmt.Errorf("branch %s already exists in %s", to, repo)
+	}
+	remote, _ := runGitOutput(bareDir, "config", "branch."+from+".remote")
+	if err := GitBranchRename(bareDir, from, to); err != nil {
+		return fmt.Errorf("renaming branch: %w", err)
+	}
+	if remote = strings.TrimSpace(remote); remote != "" {
+		if err := GitConfigBranchUpstream(bareDir, to, remote); err != nil {
+			GitBranchRename(bareDir, to, from)
+			return fmt.Errorf("setting upstream for %s: %w", to, err)
+		}
+	}
+	for child, parent := range branchConfig(bareDir, "ws-parent") {
+		if parent == from {
+			runGit(bareDir, "config", "branch."+child+".ws-parent", to)
+		}
+	}
+	return nil
+}
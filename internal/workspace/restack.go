@@ -0,0 +1,267 @@
+package workspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RestackStep rebases one capsule's branch onto its stack parent.
+type RestackStep struct {
+	Capsule  string `json:"capsule"`
+	Branch   string `json:"branch"`
+	Parent   string `json:"parent"`
+	Onto     string `json:"onto"`     // parent branch, or origin/<default> once the parent has landed
+	Upstream string `json:"upstream"` // commit the branch was cut from; commits after it are replayed
+	Landed   bool   `json:"landed"`
+}
+
+// ErrRestackConflict is returned when a rebase stops on a conflict.
+var ErrRestackConflict = errors.New("rebase stopped on a conflict")
+
+const restackStateFile = "ws-restack.json"
+
+// PlanRestack lists the rebases needed to bring the stacked capsules of a
+// repo back on top of their parents, parents first. When capsule is set,
+// only it and the capsules stacked on it are included. landed holds branches
+// known to be merged (e.g. squash-merged PRs) in addition to those git can
+// see in origin/<default>.
+//
+// Upstreams are resolved up front, before any branch moves, so children
+// replay only their own commits after their parent is rebased.
+func (w *Workspace) PlanRestack(repo, capsule string, landed map[string]bool) ([]RestackStep, error) {
+	repoDir := w.RepoDir(repo)
+	bareDir := w.BareDir(repo)
+	capsules, err := ListWorktrees(repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("listing capsules for %s: %w", repo, err)
+	}
+
+	capsuleOf := make(map[string]string)
+	var branches []string
+	for _, c := range capsules {
+		b := GitCurrentBranch(filepath.Join(repoDir, c))
+		if b == "" || b == "HEAD" || b == w.DefaultBranch {
+			continue
+		}
+		capsuleOf[b] = c
+		branches = append(branches, b)
+	}
+	if capsule != "" && !slices.Contains(capsules, capsule) {
+		return nil, fmt.Errorf("no capsule %q in %s", capsule, repo)
+	}
+
+	edges := GitStackParents(bareDir, w.DefaultBranch, branches)
+	remoteDefault := "origin/" + w.DefaultBranch
+
+	steps := make(map[string]RestackStep)
+	for _, b := range slices.Sorted(maps.Keys(capsuleOf)) {
+		parent, base := StackParent(bareDir, b)
+		if parent == "" {
+			if !edges[b].Stacked(w.DefaultBranch) {
+				continue
+			}
+			parent = edges[b].Parent
+		}
+
+		step := RestackStep{Capsule: capsuleOf[b], Branch: b, Parent: parent, Onto: parent}
+		if landed[parent] || !GitRefExists(bareDir, parent) || gitMergedInto(bareDir, parent, remoteDefault) {
+			step.Landed = true
+			step.Onto = remoteDefault
+		}
+		switch {
+		case base != "" && GitIsAncestor(bareDir, base, b):
+			step.Upstream = base
+		case GitRefExists(bareDir, parent):
+			step.Upstream = gitMergeBase(bareDir, b, parent)
+		default:
+			step.Upstream = gitMergeBase(bareDir, b, remoteDefault)
+		}
+		steps[b] = step
+	}
+
+	// A step waits for its parent's step, if the parent is being restacked too.
+	children := make(map[string][]string)
+	var roots []string
+	for _, b := range slices.Sorted(maps.Keys(steps)) {
+		parent := steps[b].Parent
+		if _, ok := steps[parent]; ok && !steps[b].Landed {
+			children[parent] = append(children[parent], b)
+		} else {
+			roots = append(roots, b)
+		}
+	}
+
+	var plan []RestackStep
+	var walk func(b string, include bool)
+	walk = func(b string, include bool) {
+		include = include || capsule == "" || capsuleOf[b] == capsule || capsuleOf[steps[b].Parent] == capsule
+		if include {
+			plan = append(plan, steps[b])
+		}
+		for _, c := range children[b] {
+			walk(c, include)
+		}
+	}
+	for _, b := range roots {
+		walk(b, false)
+	}
+	return plan, nil
+}
+
+// gitMergedInto reports whether branch's own commits have been merged into
+// ref. A branch with no commits of its own, such as a parent just lifted
+// with nothing committed yet, sits on ref's first-parent history rather than
+// being merged into it, so it hasn't landed however far ref moves on.
+func gitMergedInto(dir, branch, ref string) bool {
+	if !GitIsAncestor(dir, branch, ref) {
+		return false
+	}
+	tip := GitRevParse(dir, branch)
+	out, err := runGitOutput(dir, "rev-list", "--first-parent", ref, "--not", branch+"^@")
+	if err != nil {
+		return false
+	}
+	return !slices.Contains(strings.Fields(out), tip)
+}
+
+// RunRestack applies steps in order. It stops at the first conflict,
+// leaving the rebase in progress and saving the remaining steps so
+// ContinueRestack can pick up once the conflict is resolved. done is called
+// after each step that completes.
+func (w *Workspace) RunRestack(repo string, steps []RestackStep, done func(RestackStep, bool)) error {
+	for i, step := range steps {
+		moved, err := w.restackStep(repo, step)
+		if err != nil {
+			if errors.Is(err, ErrRestackConflict) {
+				if serr := w.saveRestack(repo, steps[i:]); serr != nil {
+					return serr
+				}
+			}
+			return err
+		}
+		if done != nil {
+			done(step, moved)
+		}
+	}
+	return w.clearRestack(repo)
+}
+
+// ContinueRestack finishes a restack stopped on a conflict: it continues the
+// stopped rebase and then runs the remaining steps.
+func (w *Workspace) ContinueRestack(repo string, done func(RestackStep, bool)) error {
+	steps, err := w.PendingRestack(repo)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("no restack in progress for %s", repo)
+	}
+
+	step := steps[0]
+	wtPath := filepath.Join(w.RepoDir(repo), step.Capsule)
+	if GitRebaseInProgress(wtPath) {
+		if err := GitRebaseContinue(wtPath); err != nil {
+			if GitRebaseInProgress(wtPath) {
+				return fmt.Errorf("%s: %w", step.Capsule, ErrRestackConflict)
+			}
+			return err
+		}
+	}
+	w.recordRestack(repo, step)
+	if done != nil {
+		done(step, true)
+	}
+	return w.RunRestack(repo, steps[1:], done)
+}
+
+// AbortRestack abandons a restack stopped on a conflict. Capsules already
+// restacked keep their new history.
+func (w *Workspace) AbortRestack(repo string) error {
+	steps, err := w.PendingRestack(repo)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("no restack in progress for %s", repo)
+	}
+	wtPath := filepath.Join(w.RepoDir(repo), steps[0].Capsule)
+	if GitRebaseInProgress(wtPath) {
+		if err := GitRebaseAbort(wtPath); err != nil {
+			return err
+		}
+	}
+	return w.clearRestack(repo)
+}
+
+// PendingRestack returns the steps left over from a restack that stopped on
+// a conflict, or nil when none is in progress.
+func (w *Workspace) PendingRestack(repo string) ([]RestackStep, error) {
+	data, err := os.ReadFile(filepath.Join(w.BareDir(repo), restackStateFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var steps []RestackStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, fmt.Errorf("reading restack state: %w", err)
+	}
+	return steps, nil
+}
+
+// restackStep rebases one branch, reporting whether it moved.
+func (w *Workspace) restackStep(repo string, step RestackStep) (bool, error) {
+	bareDir := w.BareDir(repo)
+	wtPath := filepath.Join(w.RepoDir(repo), step.Capsule)
+
+	if GitIsAncestor(bareDir, step.Onto, step.Branch) {
+		// Already on top of its parent.
+		w.recordRestack(repo, step)
+		return false, nil
+	}
+	if GitIsDirty(wtPath) {
+		return false, fmt.Errorf("%s has uncommitted changes; commit or stash them first", step.Capsule)
+	}
+	if err := GitRebaseOnto(wtPath, step.Onto, step.Upstream); err != nil {
+		if GitRebaseInProgress(wtPath) {
+			return false, fmt.Errorf("%s: %w", step.Capsule, ErrRestackConflict)
+		}
+		return false, fmt.Errorf("rebasing %s: %w", step.Capsule, err)
+	}
+	w.recordRestack(repo, step)
+	return true, nil
+}
+
+// recordRestack updates the recorded parent once a step has completed. A
+// branch whose parent landed is no longer stacked.
+func (w *Workspace) recordRestack(repo string, step RestackStep) {
+	bareDir := w.BareDir(repo)
+	if step.Landed {
+		ClearStackParent(bareDir, step.Branch)
+		return
+	}
+	SetStackParent(bareDir, step.Branch, step.Parent, GitRevParse(bareDir, step.Onto))
+}
+
+func (w *Workspace) saveRestack(repo string, steps []RestackStep) error {
+	data, err := json.MarshalIndent(steps, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(w.BareDir(repo), restackStateFile), data, 0644)
+}
+
+func (w *Workspace) clearRestack(repo string) error {
+	err := os.Remove(filepath.Join(w.BareDir(repo), restackStateFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
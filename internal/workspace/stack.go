@@ -0,0 +1,178 @@
+package workspace
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// StackEdge links a branch to the branch it was cut from.
+type StackEdge struct {
+	Parent string // parent branch; the default branch when the branch isn't stacked
+	Ahead  int    // commits on the branch that aren't on the parent
+	Behind int    // commits on the parent that aren't on the branch
+}
+
+// Stacked reports whether the branch sits on another capsule's branch rather
+// than the default branch.
+func (e StackEdge) Stacked(defaultBranch string) bool {
+	return e.Parent != "" && e.Parent != defaultBranch
+}
+
+// NeedsRestack reports whether a stacked branch's parent has moved on since
+// the branch was cut from it.
+func (e StackEdge) NeedsRestack(defaultBranch string) bool {
+	return e.Stacked(defaultBranch) && e.Behind > 0
+}
+
+// GitStackParents works out which of branches each branch was cut from.
+//
+// A parent recorded by SetStackParent wins when it's one of branches.
+// Otherwise a branch's parent is the other branch it shares the most work with beyond
+// the default branch, judged by merge-base; branches that share nothing
+// with another branch sit on the default branch. A branch that b is built on
+// always beats one b has diverged from; only when b is built on none of them
+// is the diverged branch with fewer commits over the default branch taken as
+// the parent.
+func GitStackParents(dir, defaultBranch string, branches []string) map[string]StackEdge {
+	branches = slices.DeleteFunc(slices.Clone(branches), func(b string) bool {
+		return b == "" || b == defaultBranch || b == "HEAD"
+	})
+	slices.Sort(branches)
+	branches = slices.Compact(branches)
+
+	own := make(map[string]int, len(branches))
+	recorded := make(map[string]string, len(branches))
+	for _, b := range branches {
+		own[b] = gitCount(dir, defaultBranch+".."+b)
+		recorded[b], _ = StackParent(dir, b)
+	}
+
+	// Pairs whose merge-base is the default branch's tip share nothing, so
+	// are skipped without counting. Merge-bases are symmetric, so each pair
+	// is asked about once.
+	tip := GitRevParse(dir, defaultBranch)
+	mergeBases := make(map[[2]string]string)
+	mergeBase := func(a, b string) string {
+		key := [2]string{min(a, b), max(a, b)}
+		mb, ok := mergeBases[key]
+		if !ok {
+			mb = gitMergeBase(dir, a, b)
+			mergeBases[key] = mb
+		}
+		return mb
+	}
+
+	edges := make(map[string]StackEdge, len(branches))
+	roots := make(map[string]StackEdge, len(branches))
+	for _, b := range branches {
+		edge := StackEdge{Parent: defaultBranch, Ahead: own[b], Behind: gitCount(dir, b+".."+defaultBranch)}
+		roots[b] = edge
+		if p := recorded[b]; p != "" && p != b && slices.Contains(branches, p) {
+			edges[b] = StackEdge{Parent: p, Ahead: gitCount(dir, p+".."+b), Behind: gitCount(dir, b+".."+p)}
+			continue
+		}
+		bestShared, bestContains := 0, false
+		for _, p := range branches {
+			if p == b || recorded[p] == b {
+				continue
+			}
+			mb := mergeBase(b, p)
+			if mb == "" || mb == tip {
+				continue
+			}
+			shared := gitCount(dir, defaultBranch+".."+mb)
+			if shared == 0 {
+				continue
+			}
+			ahead := gitCount(dir, p+".."+b)
+			if ahead == 0 {
+				// b is contained in p, so p is stacked on b, not the other way round.
+				continue
+			}
+			behind := gitCount(dir, b+".."+p)
+			contains := behind == 0
+			if !contains && (bestContains || !parentOf(p, b, own)) {
+				continue
+			}
+			if contains != bestContains || shared > bestShared || (shared == bestShared && ahead < edge.Ahead) {
+				edge = StackEdge{Parent: p, Ahead: ahead, Behind: behind}
+				bestShared, bestContains = shared, contains
+			}
+		}
+		edges[b] = edge
+	}
+	breakStackCycles(edges, roots, defaultBranch)
+	return edges
+}
+
+// parentOf decides which of two diverged branches is the parent.
+func parentOf(p, b string, own map[string]int) bool {
+	if own[p] != own[b] {
+		return own[p] < own[b]
+	}
+	return p < b
+}
+
+// breakStackCycles reattaches any branch caught in a parent cycle to the
+// default branch.
+func breakStackCycles(edges, roots map[string]StackEdge, defaultBranch string) {
+	for _, b := range slices.Sorted(maps.Keys(edges)) {
+		seen := map[string]bool{b: true}
+		for cur := edges[b].Parent; cur != defaultBranch; cur = edges[cur].Parent {
+			if seen[cur] {
+				edges[b] = roots[b]
+				break
+			}
+			seen[cur] = true
+		}
+	}
+}
+
+func gitMergeBase(dir, a, b string) string {
+	out, err := runGitOutput(dir, "merge-base", a, b)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+func gitCount(dir, rangeSpec string) int {
+	out, err := runGitOutput(dir, "rev-list", "--count", rangeSpec)
+	if err != nil {
+		return 0
+	}
+	var n int
+	fmt.Sscanf(strings.TrimSpace(out), "%d", &n)
+	return n
+}
+
+// SetStackParent records that branch was cut from parent at base (a commit),
+// so a later restack knows which commits belong to the branch.
+func SetStackParent(gitDir, branch, parent, base string) error {
+	if err := runGit(gitDir, "config", "branch."+branch+".ws-parent", parent); err != nil {
+		return err
+	}
+	return runGit(gitDir, "config", "branch."+branch+".ws-parent-base", base)
+}
+
+// StackParent returns the parent and fork point recorded for branch, or
+// empty strings when none is recorded.
+func StackParent(gitDir, branch string) (parent, base string) {
+	out, err := runGitOutput(gitDir, "config", "--get", "branch."+branch+".ws-parent")
+	if err != nil {
+		return "", ""
+	}
+	parent = strings.TrimSpace(out)
+	if out, err := runGitOutput(gitDir, "config", "--get", "branch."+branch+".ws-parent-base"); err == nil {
+		base = strings.TrimSpace(out)
+	}
+	return parent, base
+}
+
+// ClearStackParent forgets the recorded parent of branch.
+func ClearStackParent(gitDir, branch string) {
+	runGit(gitDir, "config", "--unset", "branch."+branch+".ws-parent")
+	runGit(gitDir, "config", "--unset", "branch."+branch+".ws-parent-base")
+}
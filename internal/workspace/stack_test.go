@@ -0,0 +1,129 @@
+package workspace
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func commitOn(t *testing.T, dir, branch, msg string) {
+	t.Helper()
+	gitRun(t, dir, "checkout", "-q", branch)
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", msg)
+}
+
+func TestGitStackParents(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "branch", "solo")
+	gitRun(t, dir, "branch", "base")
+	commitOn(t, dir, "solo", "solo 1")
+	commitOn(t, dir, "base", "base 1")
+	gitRun(t, dir, "branch", "child")
+	commitOn(t, dir, "child", "child 1")
+	gitRun(t, dir, "branch", "grandchild")
+	commitOn(t, dir, "grandchild", "grandchild 1")
+	commitOn(t, dir, "grandchild", "grandchild 2")
+	gitRun(t, dir, "checkout", "-q", "main")
+
+	edges := GitStackParents(dir, "main", []string{"main", "solo", "base", "child", "grandchild"})
+
+	want := map[string]StackEdge{
+		"solo":       {Parent: "main", Ahead: 1},
+		"base":       {Parent: "main", Ahead: 1},
+		"child":      {Parent: "base", Ahead: 1},
+		"grandchild": {Parent: "child", Ahead: 2},
+	}
+	for b, w := range want {
+		if got := edges[b]; got != w {
+			t.Errorf("edges[%s] = %+v, want %+v", b, got, w)
+		}
+	}
+	if _, ok := edges["main"]; ok {
+		t.Error("the default branch should not have an edge")
+	}
+}
+
+func TestGitStackParents_SiblingsShareParent(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "branch", "c-parent")
+	commitOn(t, dir, "c-parent", "parent 1")
+	gitRun(t, dir, "branch", "a-one")
+	gitRun(t, dir, "branch", "b-two")
+	commitOn(t, dir, "a-one", "one 1")
+	commitOn(t, dir, "b-two", "two 1")
+	gitRun(t, dir, "checkout", "-q", "main")
+
+	edges := GitStackParents(dir, "main", []string{"a-one", "b-two", "c-parent"})
+
+	for _, b := range []string{"a-one", "b-two"} {
+		if got, want := edges[b], (StackEdge{Parent: "c-parent", Ahead: 1}); got != want {
+			t.Errorf("edges[%s] = %+v, want %+v", b, got, want)
+		}
+	}
+	if got := edges["c-parent"]; got.Parent != "main" {
+		t.Errorf("c-parent parent = %q, want main", got.Parent)
+	}
+}
+
+func TestGitStackParents_ParentMovedOn(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "branch", "base")
+	commitOn(t, dir, "base", "base 1")
+	gitRun(t, dir, "branch", "child")
+	commitOn(t, dir, "child", "child 1")
+	commitOn(t, dir, "child", "child 2")
+	commitOn(t, dir, "base", "base 2")
+	gitRun(t, dir, "checkout", "-q", "main")
+
+	edges := GitStackParents(dir, "main", []string{"base", "child"})
+
+	if got := edges["child"]; got.Parent != "base" || got.Ahead != 2 || got.Behind != 1 {
+		t.Errorf("child = %+v, want parent base ↑2 ↓1", got)
+	}
+	if !edges["child"].NeedsRestack("main") {
+		t.Error("child should need a restack")
+	}
+	if got := edges["base"]; got.Parent != "main" {
+		t.Errorf("base parent = %q, want main", got.Parent)
+	}
+	if edges["base"].NeedsRestack("main") {
+		t.Error("branches on the default branch never need a restack")
+	}
+}
+
+func TestGitStackParents_RecordedParentWins(t *testing.T) {
+	dir := initTestRepo(t)
+	gitRun(t, dir, "branch", "parent")
+	commitOn(t, dir, "parent", "parent 1")
+	gitRun(t, dir, "branch", "child")
+	commitOn(t, dir, "child", "child 1")
+	commitOn(t, dir, "parent", "parent 2")
+	gitRun(t, dir, "checkout", "-q", "main")
+
+	// Both branches have two commits over main, so only the record tells
+	// them apart.
+	if err := SetStackParent(dir, "child", "parent", GitRevParse(dir, "parent~1")); err != nil {
+		t.Fatal(err)
+	}
+	edges := GitStackParents(dir, "main", []string{"child", "parent"})
+
+	if got := edges["child"]; got.Parent != "parent" || got.Behind != 1 {
+		t.Errorf("child = %+v, want recorded parent, 1 behind", got)
+	}
+	if got := edges["parent"]; got.Parent != "main" {
+		t.Errorf("parent = %+v, want it on main", got)
+	}
+
+	ClearStackParent(dir, "child")
+	if p, _ := StackParent(dir, "child"); p != "" {
+		t.Errorf("StackParent after clear = %q, want empty", p)
+	}
+}
@@ -23,6 +23,7 @@ type Workspace struct {
 	AfterCreateHooks map[string]string   // canonical name → shell command
 	Boarded          map[string][]string // repo → boarded capsule names (from ws.local.toml)
 	Silo             map[string]string   // repo → capsule name the silo points at (from ws.local.toml)
+	BranchPolicy     BranchPolicy        // branch naming rules from [branches]
 }
 
 func (w *Workspace) ReposDir() string {
@@ -36,6 +37,15 @@ func (w *Workspace) RepoDir(name string) string {
 const GroundDir = ".ground"
 const SiloDir = ".silo"
 
+// WindowsDir holds the editor files of capsules opened in a window of their
+// own, under <repo>/<capsule>.
+const WindowsDir = ".windows"
+
+// CapsuleWindowDir is where a capsule's own editor files live.
+func (w *Workspace) CapsuleWindowDir(repo, capsule string) string {
+	return filepath.Join(w.Root, WindowsDir, repo, capsule)
+}
+
 func (w *Workspace) MainWorktree(name string) string {
 	return filepath.Join(w.RepoDir(name), GroundDir)
 }
@@ -161,7 +171,12 @@ func CapsuleName(branch string) string {
 // UniqueCapsuleName returns a capsule name that doesn't collide with
 // existing directories in repoDir.
 func UniqueCapsuleName(repoDir, branch string) string {
-	base := CapsuleName(branch)
+	return uniqueDir(repoDir, CapsuleName(branch))
+}
+
+// uniqueDir returns base, or base with a "-2", "-3"... suffix, whichever
+// doesn't exist yet in repoDir.
+func uniqueDir(repoDir, base string) string {
 	candidate := base
 	for n := 2; ; n++ {
 		if _, err := os.Stat(filepath.Join(repoDir, candidate)); os.IsNotExist(err) {